@@ -1,7 +1,8 @@
 package config
 
 // ErrInvalidRegion represents the error
-// returned when the region in user config is invalid.
+// returned when the region in user config is invalid
+// (it doesn't belong to a supported partition).
 type ErrInvalidRegion struct {
 	Region string
 }
@@ -10,6 +11,27 @@ func (ErrInvalidRegion) Error() string {
 	return "ErrInvalidRegion"
 }
 
+// ErrUnknownRegion represents the error returned when
+// the region in user config is not known by AWS.
+type ErrUnknownRegion struct {
+	Region string
+}
+
+func (ErrUnknownRegion) Error() string {
+	return "ErrUnknownRegion"
+}
+
+// ErrRegionNotEnabled represents the error returned when
+// the region in user config needs to be enabled (opt-in)
+// on the user account before use.
+type ErrRegionNotEnabled struct {
+	Region string
+}
+
+func (ErrRegionNotEnabled) Error() string {
+	return "ErrRegionNotEnabled"
+}
+
 // ErrInvalidAccessKeyID represents the error
 // returned when the access key ID in user config is invalid.
 type ErrInvalidAccessKeyID struct {
@@ -29,3 +51,63 @@ type ErrInvalidSecretAccessKey struct {
 func (ErrInvalidSecretAccessKey) Error() string {
 	return "ErrInvalidSecretAccessKey"
 }
+
+// ErrInvalidRoleARN represents the error
+// returned when a role ARN in user config is invalid.
+type ErrInvalidRoleARN struct {
+	RoleARN string
+}
+
+func (ErrInvalidRoleARN) Error() string {
+	return "ErrInvalidRoleARN"
+}
+
+// ErrMissingMFATokenProvider represents the error returned
+// when a role in user config requires MFA and the
+// MFATokenProvider option is not set.
+type ErrMissingMFATokenProvider struct {
+	RoleARN   string
+	MFASerial string
+}
+
+func (ErrMissingMFATokenProvider) Error() string {
+	return "ErrMissingMFATokenProvider"
+}
+
+// ErrMissingSessionToken represents the error returned when the
+// access key ID in user config is a temporary one (starting with "ASIA")
+// but the session token is missing.
+type ErrMissingSessionToken struct {
+	AccessKeyID string
+}
+
+func (ErrMissingSessionToken) Error() string {
+	return "ErrMissingSessionToken"
+}
+
+// ErrInvalidSessionToken represents the error
+// returned when the session token in user config is invalid.
+type ErrInvalidSessionToken struct {
+	SessionToken string
+}
+
+func (ErrInvalidSessionToken) Error() string {
+	return "ErrInvalidSessionToken"
+}
+
+// ErrExpiredSessionToken represents the error returned
+// by AWS API calls when the temporary credentials resolved
+// from user config have expired.
+//
+// Err is set to the error returned by the AWS API.
+type ErrExpiredSessionToken struct {
+	Err error
+}
+
+func (ErrExpiredSessionToken) Error() string {
+	return "ErrExpiredSessionToken"
+}
+
+func (e ErrExpiredSessionToken) Unwrap() error {
+	return e.Err
+}
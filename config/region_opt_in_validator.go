@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+const (
+	regionOptInStatusNotOptedIn = "not-opted-in"
+)
+
+type RegionsDescriber interface {
+	DescribeRegions(
+		ctx context.Context,
+		params *ec2.DescribeRegionsInput,
+		optFns ...func(*ec2.Options),
+	) (*ec2.DescribeRegionsOutput, error)
+}
+
+// RegionOptInValidator checks (via the EC2 API) that
+// the region in user config exists and is enabled
+// on the user account.
+type RegionOptInValidator struct {
+	ec2Client RegionsDescriber
+}
+
+func NewRegionOptInValidator(ec2Client RegionsDescriber) RegionOptInValidator {
+	return RegionOptInValidator{
+		ec2Client: ec2Client,
+	}
+}
+
+func (r RegionOptInValidator) Validate(ctx context.Context, region string) error {
+	partition, ok := LookupPartitionForRegion(region)
+
+	if !ok {
+		return ErrInvalidRegion{
+			Region: region,
+		}
+	}
+
+	describeRegionsResp, err := r.ec2Client.DescribeRegions(
+		ctx,
+		&ec2.DescribeRegionsInput{
+			// Return disabled regions too
+			// to tell them apart from unknown ones
+			AllRegions: aws.Bool(true),
+		},
+		// The endpoints of unknown or
+		// disabled regions cannot be reached
+		func(o *ec2.Options) {
+			o.Region = partition.DefaultRegion
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for _, describedRegion := range describeRegionsResp.Regions {
+		if aws.ToString(describedRegion.RegionName) != region {
+			continue
+		}
+
+		if aws.ToString(describedRegion.OptInStatus) == regionOptInStatusNotOptedIn {
+			return ErrRegionNotEnabled{
+				Region: region,
+			}
+		}
+
+		return nil
+	}
+
+	return ErrUnknownRegion{
+		Region: region,
+	}
+}
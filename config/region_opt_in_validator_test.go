@@ -0,0 +1,76 @@
+package config_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eleven-sh/aws-cloud-provider/config"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+)
+
+func TestRegionOptInValidation(t *testing.T) {
+	testCases := []struct {
+		test          string
+		region        string
+		expectedError error
+	}{
+		{
+			test:          "with region that doesn't require opt-in",
+			region:        "eu-west-3",
+			expectedError: nil,
+		},
+
+		{
+			test:          "with opted-in region",
+			region:        "ap-southeast-4",
+			expectedError: nil,
+		},
+
+		{
+			test:          "with not opted-in region",
+			region:        "af-south-1",
+			expectedError: config.ErrRegionNotEnabled{},
+		},
+
+		{
+			test:          "with unknown region",
+			region:        "eu-south-9",
+			expectedError: config.ErrUnknownRegion{},
+		},
+
+		{
+			test:          "with invalid region",
+			region:        "invalid_region",
+			expectedError: config.ErrInvalidRegion{},
+		},
+	}
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			regionValidator := config.NewRegionOptInValidator(
+				ec2.NewFromConfig(fakeAWS.Config()),
+			)
+
+			err := regionValidator.Validate(context.Background(), tc.region)
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil &&
+				reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					tc.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
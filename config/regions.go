@@ -0,0 +1,63 @@
+package config
+
+import (
+	"regexp"
+)
+
+const (
+	PartitionAWS      = "aws"
+	PartitionAWSCN    = "aws-cn"
+	PartitionAWSUSGov = "aws-us-gov"
+)
+
+// Partition represents a group of AWS regions
+// (like "aws" for the standard regions).
+type Partition struct {
+	ID            string
+	DefaultRegion string
+	regionRegex   *regexp.Regexp
+}
+
+// partitions is maintained by hand: the endpoint metadata of the
+// pinned AWS SDK is not exported and predates some regions (like
+// il-central-1 or mx-central-1). The region regexes follow the shape
+// of the SDK partition regexes, with the prefixes added since.
+//
+// These regexes only check the shape of the region names (any
+// "us-foo-1" matches). The regions that really exist and are enabled
+// on the account are checked online when the ValidateRegionOptIn
+// option is set (see RegionOptInValidator).
+//
+// The isolated partitions (like "aws-iso") are not supported
+// given that they cannot reach the Eleven agent releases.
+var partitions = []Partition{
+	{
+		ID:            PartitionAWS,
+		DefaultRegion: "us-east-1",
+		regionRegex:   regexp.MustCompile(`^(us|eu|ap|sa|ca|me|af|il|mx)\-\w+\-\d+$`),
+	},
+
+	{
+		ID:            PartitionAWSCN,
+		DefaultRegion: "cn-north-1",
+		regionRegex:   regexp.MustCompile(`^cn\-\w+\-\d+$`),
+	},
+
+	{
+		ID:            PartitionAWSUSGov,
+		DefaultRegion: "us-gov-west-1",
+		regionRegex:   regexp.MustCompile(`^us\-gov\-\w+\-\d+$`),
+	},
+}
+
+// LookupPartitionForRegion returns the partition
+// that the passed region belongs to.
+func LookupPartitionForRegion(region string) (Partition, bool) {
+	for _, partition := range partitions {
+		if partition.regionRegex.MatchString(region) {
+			return partition, true
+		}
+	}
+
+	return Partition{}, false
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/eleven-sh/aws-cloud-provider/userconfig"
+)
+
+const SSOCredentialsProviderName = "ElevenSSOProvider"
+
+type SSORoleCredentialsGetter interface {
+	GetRoleCredentials(
+		ctx context.Context,
+		params *sso.GetRoleCredentialsInput,
+		optFns ...func(*sso.Options),
+	) (*sso.GetRoleCredentialsOutput, error)
+}
+
+// SSOCredentialsProvider exchanges the SSO token
+// resolved from user config for temporary role credentials.
+type SSOCredentialsProvider struct {
+	client  SSORoleCredentialsGetter
+	userSSO userconfig.SSO
+}
+
+func NewSSOCredentialsProvider(
+	client SSORoleCredentialsGetter,
+	userSSO userconfig.SSO,
+) SSOCredentialsProvider {
+
+	return SSOCredentialsProvider{
+		client:  client,
+		userSSO: userSSO,
+	}
+}
+
+func (s SSOCredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	output, err := s.client.GetRoleCredentials(ctx, &sso.GetRoleCredentialsInput{
+		AccessToken: aws.String(s.userSSO.AccessToken),
+		AccountId:   aws.String(s.userSSO.AccountID),
+		RoleName:    aws.String(s.userSSO.RoleName),
+	})
+
+	if err != nil {
+		var unauthorizedErr *types.UnauthorizedException
+		if errors.As(err, &unauthorizedErr) {
+			// the token was revoked or has
+			// expired since it was resolved
+			return aws.Credentials{}, userconfig.ErrSSOSessionExpired{
+				StartURL: s.userSSO.StartURL,
+			}
+		}
+
+		return aws.Credentials{}, err
+	}
+
+	roleCredentials := output.RoleCredentials
+
+	return aws.Credentials{
+		AccessKeyID:     aws.ToString(roleCredentials.AccessKeyId),
+		SecretAccessKey: aws.ToString(roleCredentials.SecretAccessKey),
+		SessionToken:    aws.ToString(roleCredentials.SessionToken),
+		CanExpire:       true,
+		Expires:         time.UnixMilli(roleCredentials.Expiration).UTC(),
+		Source:          SSOCredentialsProviderName,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sso/types"
+	"github.com/eleven-sh/aws-cloud-provider/config"
+	"github.com/eleven-sh/aws-cloud-provider/userconfig"
+)
+
+type fakeSSOClient struct {
+	passedInput *sso.GetRoleCredentialsInput
+	output      *sso.GetRoleCredentialsOutput
+	err         error
+}
+
+func (f *fakeSSOClient) GetRoleCredentials(
+	ctx context.Context,
+	params *sso.GetRoleCredentialsInput,
+	optFns ...func(*sso.Options),
+) (*sso.GetRoleCredentialsOutput, error) {
+
+	f.passedInput = params
+	return f.output, f.err
+}
+
+func TestSSOCredentialsProviderRetrieve(t *testing.T) {
+	expiration := time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	SSOClient := &fakeSSOClient{
+		output: &sso.GetRoleCredentialsOutput{
+			RoleCredentials: &types.RoleCredentials{
+				AccessKeyId:     aws.String("a"),
+				SecretAccessKey: aws.String("b"),
+				SessionToken:    aws.String("c"),
+				Expiration:      expiration.UnixMilli(),
+			},
+		},
+	}
+
+	userSSO := userconfig.SSO{
+		StartURL:    "https://valid.awsapps.com/start",
+		AccountID:   "123456789012",
+		RoleName:    "Admin",
+		AccessToken: "valid_access_token",
+	}
+
+	creds, err := config.NewSSOCredentialsProvider(SSOClient, userSSO).Retrieve(context.TODO())
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if aws.ToString(SSOClient.passedInput.AccessToken) != userSSO.AccessToken ||
+		aws.ToString(SSOClient.passedInput.AccountId) != userSSO.AccountID ||
+		aws.ToString(SSOClient.passedInput.RoleName) != userSSO.RoleName {
+
+		t.Fatalf("expected SSO config to be passed to client, got '%+v'", *SSOClient.passedInput)
+	}
+
+	if creds.AccessKeyID != "a" ||
+		creds.SecretAccessKey != "b" ||
+		creds.SessionToken != "c" ||
+		!creds.CanExpire ||
+		!creds.Expires.Equal(expiration) {
+
+		t.Fatalf("expected role credentials to be returned, got '%+v'", creds)
+	}
+}
+
+func TestSSOCredentialsProviderRetrieveWithRevokedToken(t *testing.T) {
+	SSOClient := &fakeSSOClient{
+		err: &types.UnauthorizedException{},
+	}
+
+	_, err := config.NewSSOCredentialsProvider(SSOClient, userconfig.SSO{
+		StartURL:    "https://valid.awsapps.com/start",
+		AccessToken: "revoked_access_token",
+	}).Retrieve(context.TODO())
+
+	if !errors.As(err, &userconfig.ErrSSOSessionExpired{}) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			userconfig.ErrSSOSessionExpired{},
+			err,
+		)
+	}
+}
@@ -2,20 +2,81 @@ package config
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/processcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/eleven-sh/aws-cloud-provider/userconfig"
 )
 
-type UserConfigLoader struct{}
+// expiredSessionTokenErrorCodes lists the error codes returned
+// by the AWS APIs when the temporary credentials have expired
+// ("RequestExpired" is not listed: it is returned on clock skew)
+var expiredSessionTokenErrorCodes = map[string]bool{
+	"ExpiredToken":          true,
+	"ExpiredTokenException": true,
+}
+
+type UserConfigLoaderOpts struct {
+	// MFATokenProvider is called to retrieve the MFA
+	// token code when a role that requires MFA is assumed.
+	MFATokenProvider func() (string, error)
+}
+
+type UserConfigLoader struct {
+	opts UserConfigLoaderOpts
+}
 
-func NewUserConfigLoader() UserConfigLoader {
-	return UserConfigLoader{}
+func NewUserConfigLoader(opts UserConfigLoaderOpts) UserConfigLoader {
+	return UserConfigLoader{
+		opts: opts,
+	}
 }
 
-func (UserConfigLoader) Load(userConfig *userconfig.Config) (aws.Config, error) {
+func (u UserConfigLoader) Load(userConfig *userconfig.Config) (aws.Config, error) {
+	var AWSSDKConfig aws.Config
+	var err error
+
+	switch {
+	case userConfig.SSO.IsSet():
+		AWSSDKConfig, err = u.loadSSO(userConfig)
+	case len(userConfig.CredentialProcess) > 0:
+		AWSSDKConfig, err = u.loadWithProvider(
+			userConfig,
+			processcreds.NewProvider(userConfig.CredentialProcess),
+		)
+	case userConfig.WebIdentity.IsSet():
+		AWSSDKConfig, err = u.loadWebIdentity(userConfig)
+	case userConfig.UseInstanceMetadata:
+		AWSSDKConfig, err = u.loadWithProvider(
+			userConfig,
+			ec2rolecreds.New(),
+		)
+	default:
+		AWSSDKConfig, err = u.loadStatic(userConfig)
+	}
+
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	AWSSDKConfig.APIOptions = append(
+		AWSSDKConfig.APIOptions,
+		addExpiredSessionTokenMiddleware,
+	)
+
+	return u.assumeRoles(AWSSDKConfig, userConfig.RoleChain)
+}
+
+func (UserConfigLoader) loadStatic(userConfig *userconfig.Config) (aws.Config, error) {
 	return config.LoadDefaultConfig(
 		context.TODO(),
 		config.WithCredentialsProvider(
@@ -28,3 +89,147 @@ func (UserConfigLoader) Load(userConfig *userconfig.Config) (aws.Config, error)
 		config.WithRegion(userConfig.Region),
 	)
 }
+
+func (UserConfigLoader) loadSSO(userConfig *userconfig.Config) (aws.Config, error) {
+	AWSSDKConfig, err := config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithCredentialsProvider(aws.AnonymousCredentials{}),
+		config.WithRegion(userConfig.Region),
+	)
+
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	// The SSO user portal may be hosted
+	// in another region than the resources
+	SSOClient := sso.NewFromConfig(AWSSDKConfig, func(o *sso.Options) {
+		if len(userConfig.SSO.Region) > 0 {
+			o.Region = userConfig.SSO.Region
+		}
+	})
+
+	AWSSDKConfig.Credentials = aws.NewCredentialsCache(
+		NewSSOCredentialsProvider(SSOClient, userConfig.SSO),
+	)
+
+	return AWSSDKConfig, nil
+}
+
+func (UserConfigLoader) loadWithProvider(
+	userConfig *userconfig.Config,
+	provider aws.CredentialsProvider,
+) (aws.Config, error) {
+
+	return config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithCredentialsProvider(
+			aws.NewCredentialsCache(provider),
+		),
+		config.WithRegion(userConfig.Region),
+	)
+}
+
+func (UserConfigLoader) loadWebIdentity(userConfig *userconfig.Config) (aws.Config, error) {
+	AWSSDKConfig, err := config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithCredentialsProvider(aws.AnonymousCredentials{}),
+		config.WithRegion(userConfig.Region),
+	)
+
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	webIdentity := userConfig.WebIdentity
+
+	// AssumeRoleWithWebIdentity
+	// doesn't require credentials
+	STSClient := sts.NewFromConfig(AWSSDKConfig)
+
+	AWSSDKConfig.Credentials = aws.NewCredentialsCache(
+		stscreds.NewWebIdentityRoleProvider(
+			STSClient,
+			webIdentity.RoleARN,
+			stscreds.IdentityTokenFile(webIdentity.TokenFilePath),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = webIdentity.RoleSessionName
+			},
+		),
+	)
+
+	return AWSSDKConfig, nil
+}
+
+func (u UserConfigLoader) assumeRoles(
+	AWSSDKConfig aws.Config,
+	roleChain []userconfig.AssumeRole,
+) (aws.Config, error) {
+
+	for _, role := range roleChain {
+		if len(role.MFASerial) > 0 && u.opts.MFATokenProvider == nil {
+			return aws.Config{}, ErrMissingMFATokenProvider{
+				RoleARN:   role.RoleARN,
+				MFASerial: role.MFASerial,
+			}
+		}
+
+		// Each role is assumed using the
+		// credentials of the previous one
+		STSClient := sts.NewFromConfig(AWSSDKConfig)
+
+		AWSSDKConfig.Credentials = aws.NewCredentialsCache(
+			stscreds.NewAssumeRoleProvider(
+				STSClient,
+				role.RoleARN,
+				func(o *stscreds.AssumeRoleOptions) {
+					o.RoleSessionName = role.RoleSessionName
+					o.Duration = role.Duration
+
+					if len(role.ExternalID) > 0 {
+						o.ExternalID = aws.String(role.ExternalID)
+					}
+
+					if len(role.MFASerial) > 0 {
+						o.SerialNumber = aws.String(role.MFASerial)
+						o.TokenProvider = u.opts.MFATokenProvider
+					}
+				},
+			),
+		)
+	}
+
+	return AWSSDKConfig, nil
+}
+
+// addExpiredSessionTokenMiddleware converts the errors returned
+// by the AWS APIs when the temporary credentials have expired
+// to ErrExpiredSessionToken. The middleware is added at the start
+// of the stack so errors are converted once retries are exhausted.
+func addExpiredSessionTokenMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(
+		middleware.InitializeMiddlewareFunc(
+			"ElevenExpiredSessionToken",
+			func(
+				ctx context.Context,
+				in middleware.InitializeInput,
+				next middleware.InitializeHandler,
+			) (middleware.InitializeOutput, middleware.Metadata, error) {
+
+				out, metadata, err := next.HandleInitialize(ctx, in)
+
+				var APIErr smithy.APIError
+				if errors.As(err, &APIErr) &&
+					expiredSessionTokenErrorCodes[APIErr.ErrorCode()] {
+
+					err = ErrExpiredSessionToken{
+						Err: err,
+					}
+				}
+
+				return out, metadata, err
+			},
+		),
+		middleware.Before,
+	)
+}
@@ -2,16 +2,23 @@ package config_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/eleven-sh/aws-cloud-provider/config"
 	"github.com/eleven-sh/aws-cloud-provider/userconfig"
 )
 
 func TestUserConfigLoader(t *testing.T) {
-	configLoader := config.NewUserConfigLoader()
+	configLoader := config.NewUserConfigLoader(config.UserConfigLoaderOpts{})
 
 	passedUserConfig := userconfig.NewConfig("a", "b", "c")
+	passedUserConfig.Credentials.SessionToken = "d"
+
 	loadedConfig, err := configLoader.Load(passedUserConfig)
 
 	if err != nil {
@@ -47,4 +54,230 @@ func TestUserConfigLoader(t *testing.T) {
 			credsInConfig.SecretAccessKey,
 		)
 	}
+
+	if credsInConfig.SessionToken != passedUserConfig.Credentials.SessionToken {
+		t.Errorf(
+			"expected session token to equal '%s', got '%s'",
+			passedUserConfig.Credentials.SessionToken,
+			credsInConfig.SessionToken,
+		)
+	}
+}
+
+func TestUserConfigLoaderWithExpiredSessionToken(t *testing.T) {
+	testCases := []struct {
+		test                  string
+		errorType             string
+		expectExpiredTokenErr bool
+	}{
+		{
+			test:                  "with expired token",
+			errorType:             "com.amazonaws.dynamodb.v20120810#ExpiredTokenException",
+			expectExpiredTokenErr: true,
+		},
+
+		{
+			test:                  "with clock skew",
+			errorType:             "RequestExpired",
+			expectExpiredTokenErr: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"` + tc.errorType + `","message":"The request failed"}`))
+			}))
+			defer server.Close()
+
+			passedUserConfig := userconfig.NewConfig("a", "b", "c")
+			passedUserConfig.Credentials.SessionToken = "d"
+
+			loadedConfig, err := config.NewUserConfigLoader(config.UserConfigLoaderOpts{}).Load(passedUserConfig)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			dynamoDBClient := dynamodb.NewFromConfig(loadedConfig, func(o *dynamodb.Options) {
+				o.EndpointResolver = dynamodb.EndpointResolverFromURL(server.URL)
+			})
+
+			_, err = dynamoDBClient.ListTables(context.TODO(), &dynamodb.ListTablesInput{})
+
+			if err == nil {
+				t.Fatalf("expected error, got nothing")
+			}
+
+			isExpiredTokenErr := errors.As(err, &config.ErrExpiredSessionToken{})
+
+			if isExpiredTokenErr != tc.expectExpiredTokenErr {
+				t.Fatalf(
+					"expected error to be '%+v' (%t), got '%+v'",
+					config.ErrExpiredSessionToken{},
+					tc.expectExpiredTokenErr,
+					err,
+				)
+			}
+		})
+	}
+}
+
+func TestUserConfigLoaderWithSSOConfig(t *testing.T) {
+	configLoader := config.NewUserConfigLoader(config.UserConfigLoaderOpts{})
+
+	passedUserConfig := userconfig.NewSSOConfig(userconfig.SSO{
+		StartURL:    "https://valid.awsapps.com/start",
+		Region:      "eu-west-1",
+		AccessToken: "valid_access_token",
+	}, "c")
+
+	loadedConfig, err := configLoader.Load(passedUserConfig)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if loadedConfig.Region != passedUserConfig.Region {
+		t.Errorf(
+			"expected region to equal '%s', got '%s'",
+			passedUserConfig.Region,
+			loadedConfig.Region,
+		)
+	}
+
+	if _, ok := loadedConfig.Credentials.(*aws.CredentialsCache); !ok {
+		t.Fatalf("expected SSO credentials provider, got '%+v'", loadedConfig.Credentials)
+	}
+}
+
+func TestUserConfigLoaderWithRoleChain(t *testing.T) {
+	passedUserConfig := userconfig.NewConfig("a", "b", "c")
+	passedUserConfig.RoleChain = []userconfig.AssumeRole{
+		{
+			RoleARN: "arn:aws:iam::123456789012:role/sandbox",
+		},
+		{
+			RoleARN:   "arn:aws:iam::210987654321:role/nested",
+			MFASerial: "arn:aws:iam::123456789012:mfa/user",
+		},
+	}
+
+	_, err := config.NewUserConfigLoader(config.UserConfigLoaderOpts{}).Load(passedUserConfig)
+
+	if !errors.As(err, &config.ErrMissingMFATokenProvider{}) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			config.ErrMissingMFATokenProvider{},
+			err,
+		)
+	}
+
+	configLoader := config.NewUserConfigLoader(config.UserConfigLoaderOpts{
+		MFATokenProvider: func() (string, error) {
+			return "123456", nil
+		},
+	})
+
+	loadedConfig, err := configLoader.Load(passedUserConfig)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if loadedConfig.Region != passedUserConfig.Region {
+		t.Errorf(
+			"expected region to equal '%s', got '%s'",
+			passedUserConfig.Region,
+			loadedConfig.Region,
+		)
+	}
+
+	if _, ok := loadedConfig.Credentials.(*aws.CredentialsCache); !ok {
+		t.Fatalf("expected assume role credentials provider, got '%+v'", loadedConfig.Credentials)
+	}
+}
+
+func TestUserConfigLoaderWithCredentialProcess(t *testing.T) {
+	passedUserConfig := &userconfig.Config{
+		CredentialProcess: `echo '{"Version": 1, "AccessKeyId": "a", "SecretAccessKey": "b", "SessionToken": "c"}'`,
+		Region:            "d",
+	}
+
+	loadedConfig, err := config.NewUserConfigLoader(config.UserConfigLoaderOpts{}).Load(passedUserConfig)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if loadedConfig.Region != passedUserConfig.Region {
+		t.Errorf(
+			"expected region to equal '%s', got '%s'",
+			passedUserConfig.Region,
+			loadedConfig.Region,
+		)
+	}
+
+	credsInConfig, err := loadedConfig.Credentials.Retrieve(context.TODO())
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if credsInConfig.AccessKeyID != "a" ||
+		credsInConfig.SecretAccessKey != "b" ||
+		credsInConfig.SessionToken != "c" {
+
+		t.Fatalf("expected credentials returned by process, got '%+v'", credsInConfig)
+	}
+}
+
+func TestUserConfigLoaderWithCredentialsProviders(t *testing.T) {
+	testCases := []struct {
+		test       string
+		userConfig *userconfig.Config
+	}{
+		{
+			test: "with web identity",
+			userConfig: &userconfig.Config{
+				WebIdentity: userconfig.WebIdentity{
+					RoleARN:       "arn:aws:iam::123456789012:role/ci",
+					TokenFilePath: "/var/run/secrets/token",
+				},
+				Region: "eu-west-1",
+			},
+		},
+
+		{
+			test: "with instance metadata",
+			userConfig: &userconfig.Config{
+				UseInstanceMetadata: true,
+				Region:              "eu-west-1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			loadedConfig, err := config.NewUserConfigLoader(config.UserConfigLoaderOpts{}).Load(tc.userConfig)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if loadedConfig.Region != tc.userConfig.Region {
+				t.Errorf(
+					"expected region to equal '%s', got '%s'",
+					tc.userConfig.Region,
+					loadedConfig.Region,
+				)
+			}
+
+			if _, ok := loadedConfig.Credentials.(*aws.CredentialsCache); !ok {
+				t.Fatalf("expected credentials provider, got '%+v'", loadedConfig.Credentials)
+			}
+		})
+	}
 }
@@ -2,40 +2,20 @@ package config
 
 import (
 	"regexp"
+	"strings"
 
 	"github.com/eleven-sh/aws-cloud-provider/userconfig"
 )
 
 const (
+	awsTemporaryAccessKeyIDPrefix = "ASIA"
+
 	awsAccessKeyIDPattern     = "^[A-Z0-9]{20}$"
 	awsSecretAccessKeyPattern = "^[A-Za-z0-9/+=]{40}$"
+	awsSessionTokenPattern    = "^[A-Za-z0-9/+=]+$"
+	awsRoleARNPattern         = `^arn:aws[a-z-]*:iam::[0-9]{12}:role/[\w+=,.@/-]{1,512}$`
 )
 
-var validAWSRegions = map[string]bool{
-	"us-east-2":      true,
-	"us-east-1":      true,
-	"us-west-1":      true,
-	"us-west-2":      true,
-	"af-south-1":     true,
-	"ap-east-1":      true,
-	"ap-southeast-3": true,
-	"ap-south-1":     true,
-	"ap-northeast-3": true,
-	"ap-southeast-1": true,
-	"ap-southeast-2": true,
-	"ap-northeast-1": true,
-	"ca-central-1":   true,
-	"eu-central-1":   true,
-	"eu-west-1":      true,
-	"eu-west-2":      true,
-	"eu-south-1":     true,
-	"eu-west-3":      true,
-	"eu-north-1":     true,
-	"me-south-1":     true,
-	"me-central-1":   true,
-	"sa-east-1":      true,
-}
-
 type UserConfigValidator struct{}
 
 func NewUserConfigValidator() UserConfigValidator {
@@ -49,6 +29,24 @@ func (u UserConfigValidator) Validate(userConfig *userconfig.Config) error {
 		return err
 	}
 
+	for _, role := range userConfig.RoleChain {
+		if err := u.validateRoleARN(region, role.RoleARN); err != nil {
+			return err
+		}
+	}
+
+	if userConfig.WebIdentity.IsSet() {
+		if err := u.validateRoleARN(region, userConfig.WebIdentity.RoleARN); err != nil {
+			return err
+		}
+	}
+
+	// Credentials are retrieved from a provider
+	// (SSO, external process, STS or instance metadata)
+	if !userConfig.HasStaticCredentials() {
+		return nil
+	}
+
 	creds := userConfig.Credentials
 	accessKeyID := creds.AccessKeyID
 	secretAccessKey := creds.SecretAccessKey
@@ -61,11 +59,18 @@ func (u UserConfigValidator) Validate(userConfig *userconfig.Config) error {
 		return err
 	}
 
+	if err := u.validateSessionToken(accessKeyID, creds.SessionToken); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// validateRegion checks that the region belongs to a supported
+// partition. Regions that are unknown to AWS or not enabled
+// on the user account are reported by the RegionOptInValidator.
 func (UserConfigValidator) validateRegion(region string) error {
-	if _, ok := validAWSRegions[region]; !ok {
+	if _, ok := LookupPartitionForRegion(region); !ok {
 		return ErrInvalidRegion{
 			Region: region,
 		}
@@ -105,3 +110,56 @@ func (UserConfigValidator) validateSecretAccessKey(secretAccessKey string) error
 
 	return nil
 }
+
+func (UserConfigValidator) validateSessionToken(
+	accessKeyID string,
+	sessionToken string,
+) error {
+
+	// Temporary access keys (returned by STS)
+	// are only valid with their session token
+	if len(sessionToken) == 0 {
+		if strings.HasPrefix(accessKeyID, awsTemporaryAccessKeyIDPrefix) {
+			return ErrMissingSessionToken{
+				AccessKeyID: accessKeyID,
+			}
+		}
+
+		return nil
+	}
+
+	match, err := regexp.MatchString(awsSessionTokenPattern, sessionToken)
+
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return ErrInvalidSessionToken{
+			SessionToken: sessionToken,
+		}
+	}
+
+	return nil
+}
+
+// validateRoleARN checks that the role ARN is well-formed
+// and that it belongs to the partition of the region
+// (roles cannot be assumed across partitions).
+func (UserConfigValidator) validateRoleARN(region, roleARN string) error {
+	match, err := regexp.MatchString(awsRoleARNPattern, roleARN)
+
+	if err != nil {
+		return err
+	}
+
+	partition, _ := LookupPartitionForRegion(region)
+
+	if !match || !strings.HasPrefix(roleARN, "arn:"+partition.ID+":") {
+		return ErrInvalidRoleARN{
+			RoleARN: roleARN,
+		}
+	}
+
+	return nil
+}
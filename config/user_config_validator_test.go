@@ -27,6 +27,157 @@ func TestUserConfigValidator(t *testing.T) {
 			expectedError: nil,
 		},
 
+		{
+			test: "with valid temporary credentials",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "ASIA" + strings.Repeat("B", 16),
+					SecretAccessKey: strings.Repeat("b", 40),
+					SessionToken:    "IQoJb3JpZ2luX2VjEJr//////////wEaCXVzLWVhc3QtMSJHMEUCIQ+/c=",
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with temporary credentials without session token",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "ASIA" + strings.Repeat("B", 16),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: config.ErrMissingSessionToken{},
+		},
+
+		{
+			test: "with invalid session token",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "ASIA" + strings.Repeat("B", 16),
+					SecretAccessKey: strings.Repeat("b", 40),
+					SessionToken:    "invalid session token",
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: config.ErrInvalidSessionToken{},
+		},
+
+		{
+			test: "with valid SSO config",
+			userconfig: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL:    "https://valid.awsapps.com/start",
+				AccessToken: "valid_access_token",
+			}, "eu-west-1"),
+			expectedError: nil,
+		},
+
+		{
+			test: "with invalid region in SSO config",
+			userconfig: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL:    "https://valid.awsapps.com/start",
+				AccessToken: "valid_access_token",
+			}, "invalid_region"),
+			expectedError: config.ErrInvalidRegion{},
+		},
+
+		{
+			test: "with valid role chain",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{RoleARN: "arn:aws:iam::123456789012:role/sandbox"},
+					{RoleARN: "arn:aws:iam::210987654321:role/path/to/eleven-role"},
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with invalid role ARN",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{RoleARN: "arn:aws:iam::123456789012:role/sandbox"},
+					{RoleARN: "arn:aws:iam::12345:user/sandbox"},
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: config.ErrInvalidRoleARN{},
+		},
+
+		{
+			test: "with GovCloud role chain",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{RoleARN: "arn:aws-us-gov:iam::123456789012:role/sandbox"},
+				},
+				Region: "us-gov-west-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with role ARN from another partition",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{RoleARN: "arn:aws-cn:iam::123456789012:role/sandbox"},
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: config.ErrInvalidRoleARN{},
+		},
+
+		{
+			test: "with valid web identity",
+			userconfig: &userconfig.Config{
+				WebIdentity: userconfig.WebIdentity{
+					RoleARN:       "arn:aws:iam::123456789012:role/ci",
+					TokenFilePath: "/var/run/secrets/token",
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with invalid web identity role ARN",
+			userconfig: &userconfig.Config{
+				WebIdentity: userconfig.WebIdentity{
+					RoleARN:       "arn:aws:iam::123456789012:ci",
+					TokenFilePath: "/var/run/secrets/token",
+				},
+				Region: "eu-west-1",
+			},
+			expectedError: config.ErrInvalidRoleARN{},
+		},
+
+		{
+			test: "with instance metadata",
+			userconfig: &userconfig.Config{
+				UseInstanceMetadata: true,
+				Region:              "eu-west-1",
+			},
+			expectedError: nil,
+		},
+
 		{
 			test: "with invalid region",
 			userconfig: &userconfig.Config{
@@ -39,6 +190,54 @@ func TestUserConfigValidator(t *testing.T) {
 			expectedError: config.ErrInvalidRegion{},
 		},
 
+		{
+			test: "with recently launched region",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				Region: "il-central-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with China region",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				Region: "cn-northwest-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with GovCloud region",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				Region: "us-gov-east-1",
+			},
+			expectedError: nil,
+		},
+
+		{
+			test: "with region from unknown partition",
+			userconfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     strings.Repeat("B", 20),
+					SecretAccessKey: strings.Repeat("b", 40),
+				},
+				Region: "xx-west-1",
+			},
+			expectedError: config.ErrInvalidRegion{},
+		},
+
 		{
 			test: "with invalid access key ID",
 			userconfig: &userconfig.Config{
@@ -87,6 +286,36 @@ func TestUserConfigValidator(t *testing.T) {
 				}
 			}
 
+			if _, ok := tc.expectedError.(config.ErrMissingSessionToken); ok {
+				if !errors.As(err, &config.ErrMissingSessionToken{}) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+			}
+
+			if _, ok := tc.expectedError.(config.ErrInvalidSessionToken); ok {
+				if !errors.As(err, &config.ErrInvalidSessionToken{}) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+			}
+
+			if _, ok := tc.expectedError.(config.ErrInvalidRoleARN); ok {
+				if !errors.As(err, &config.ErrInvalidRoleARN{}) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+			}
+
 			if _, ok := tc.expectedError.(config.ErrInvalidAccessKeyID); ok {
 				if !errors.As(err, &config.ErrInvalidAccessKeyID{}) {
 					t.Fatalf(
@@ -0,0 +1,308 @@
+package fakeaws
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+)
+
+const dynamoDBTargetPrefix = "DynamoDB_20120810."
+
+type dynamoDBHandler func(s *Server, body []byte) (interface{}, *apiError)
+
+var dynamoDBHandlers = map[string]dynamoDBHandler{
+	"CreateTable":   (*Server).createTable,
+	"DescribeTable": (*Server).describeTable,
+	"DeleteTable":   (*Server).deleteTable,
+	"Scan":          (*Server).scan,
+	"PutItem":       (*Server).putItem,
+}
+
+type dynamoDBItem map[string]json.RawMessage
+
+type dynamoDBTable struct {
+	name                 string
+	attributeDefinitions json.RawMessage
+	keySchema            []dynamoDBKeySchemaElement
+	// hash key value (as raw JSON) -> item
+	items map[string]dynamoDBItem
+}
+
+func (t *dynamoDBTable) hashKey() string {
+	for _, keySchemaElement := range t.keySchema {
+		if keySchemaElement.KeyType == "HASH" {
+			return keySchemaElement.AttributeName
+		}
+	}
+
+	return ""
+}
+
+type dynamoDBState struct {
+	tables map[string]*dynamoDBTable
+}
+
+func newDynamoDBState() *dynamoDBState {
+	return &dynamoDBState{
+		tables: map[string]*dynamoDBTable{},
+	}
+}
+
+type dynamoDBErrorResponse struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// serveDynamoDB must be called with the lock held.
+func (s *Server) serveDynamoDB(w http.ResponseWriter, r *http.Request, operation string) {
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		writeDynamoDBError(w, newAPIError("InternalServerError", "%v", err))
+		return
+	}
+
+	if errorCode := s.popInjectedError(operation); len(errorCode) > 0 {
+		writeDynamoDBError(w, newAPIError(errorCode, "Injected error for operation %s", operation))
+		return
+	}
+
+	handler, ok := dynamoDBHandlers[operation]
+
+	if !ok {
+		writeDynamoDBError(w, newAPIError("UnknownOperationException", "The operation %s is not supported", operation))
+		return
+	}
+
+	resp, apiErr := handler(s, body)
+
+	if apiErr != nil {
+		writeDynamoDBError(w, apiErr)
+		return
+	}
+
+	encodedResp, err := json.Marshal(resp)
+
+	if err != nil {
+		writeDynamoDBError(w, newAPIError("InternalServerError", "%v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(encodedResp)
+}
+
+func writeDynamoDBError(w http.ResponseWriter, apiErr *apiError) {
+	encodedResp, _ := json.Marshal(dynamoDBErrorResponse{
+		Type:    "com.amazonaws.dynamodb.v20120810#" + apiErr.code,
+		Message: apiErr.message,
+	})
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write(encodedResp)
+}
+
+func decodeDynamoDBRequest(body []byte, req interface{}) *apiError {
+	if err := json.Unmarshal(body, req); err != nil {
+		return newAPIError("SerializationException", "%v", err)
+	}
+
+	return nil
+}
+
+type dynamoDBKeySchemaElement struct {
+	AttributeName string `json:"AttributeName"`
+	KeyType       string `json:"KeyType"`
+}
+
+type dynamoDBTableDescription struct {
+	TableName            string                     `json:"TableName"`
+	TableStatus          string                     `json:"TableStatus"`
+	AttributeDefinitions json.RawMessage            `json:"AttributeDefinitions,omitempty"`
+	KeySchema            []dynamoDBKeySchemaElement `json:"KeySchema"`
+	ItemCount            int                        `json:"ItemCount"`
+}
+
+func (t *dynamoDBTable) description(status string) dynamoDBTableDescription {
+	return dynamoDBTableDescription{
+		TableName:            t.name,
+		TableStatus:          status,
+		AttributeDefinitions: t.attributeDefinitions,
+		KeySchema:            t.keySchema,
+		ItemCount:            len(t.items),
+	}
+}
+
+type createTableRequest struct {
+	TableName            string                     `json:"TableName"`
+	AttributeDefinitions json.RawMessage            `json:"AttributeDefinitions"`
+	KeySchema            []dynamoDBKeySchemaElement `json:"KeySchema"`
+}
+
+type tableDescriptionResponse struct {
+	TableDescription dynamoDBTableDescription `json:"TableDescription"`
+}
+
+func (s *Server) createTable(body []byte) (interface{}, *apiError) {
+	var req createTableRequest
+
+	if apiErr := decodeDynamoDBRequest(body, &req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if _, ok := s.dynamoDB.tables[req.TableName]; ok {
+		return nil, newAPIError("ResourceInUseException", "Table already exists: %s", req.TableName)
+	}
+
+	createdTable := &dynamoDBTable{
+		name:                 req.TableName,
+		attributeDefinitions: req.AttributeDefinitions,
+		keySchema:            req.KeySchema,
+		items:                map[string]dynamoDBItem{},
+	}
+
+	if len(createdTable.hashKey()) == 0 {
+		return nil, newAPIError("ValidationException", "No Hash Key specified in schema.")
+	}
+
+	s.dynamoDB.tables[createdTable.name] = createdTable
+
+	return tableDescriptionResponse{
+		TableDescription: createdTable.description("CREATING"),
+	}, nil
+}
+
+type tableNameRequest struct {
+	TableName string `json:"TableName"`
+}
+
+type describeTableResponse struct {
+	Table dynamoDBTableDescription `json:"Table"`
+}
+
+// lookupTable must be called with the lock held.
+func (s *Server) lookupTable(tableName string) (*dynamoDBTable, *apiError) {
+	table, ok := s.dynamoDB.tables[tableName]
+
+	if !ok {
+		return nil, newAPIError("ResourceNotFoundException", "Requested resource not found: Table: %s not found", tableName)
+	}
+
+	return table, nil
+}
+
+func (s *Server) describeTable(body []byte) (interface{}, *apiError) {
+	var req tableNameRequest
+
+	if apiErr := decodeDynamoDBRequest(body, &req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	table, apiErr := s.lookupTable(req.TableName)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	return describeTableResponse{
+		Table: table.description("ACTIVE"),
+	}, nil
+}
+
+func (s *Server) deleteTable(body []byte) (interface{}, *apiError) {
+	var req tableNameRequest
+
+	if apiErr := decodeDynamoDBRequest(body, &req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	table, apiErr := s.lookupTable(req.TableName)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	delete(s.dynamoDB.tables, table.name)
+
+	return tableDescriptionResponse{
+		TableDescription: table.description("DELETING"),
+	}, nil
+}
+
+type scanResponse struct {
+	Items        []dynamoDBItem `json:"Items"`
+	Count        int            `json:"Count"`
+	ScannedCount int            `json:"ScannedCount"`
+}
+
+func (s *Server) scan(body []byte) (interface{}, *apiError) {
+	var req tableNameRequest
+
+	if apiErr := decodeDynamoDBRequest(body, &req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	table, apiErr := s.lookupTable(req.TableName)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	itemKeys := []string{}
+
+	for itemKey := range table.items {
+		itemKeys = append(itemKeys, itemKey)
+	}
+
+	// Make sure that scan results are stable
+	sort.Strings(itemKeys)
+
+	items := []dynamoDBItem{}
+
+	for _, itemKey := range itemKeys {
+		items = append(items, table.items[itemKey])
+	}
+
+	return scanResponse{
+		Items:        items,
+		Count:        len(items),
+		ScannedCount: len(items),
+	}, nil
+}
+
+type putItemRequest struct {
+	TableName string       `json:"TableName"`
+	Item      dynamoDBItem `json:"Item"`
+}
+
+func (s *Server) putItem(body []byte) (interface{}, *apiError) {
+	var req putItemRequest
+
+	if apiErr := decodeDynamoDBRequest(body, &req); apiErr != nil {
+		return nil, apiErr
+	}
+
+	table, apiErr := s.lookupTable(req.TableName)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	hashKey := table.hashKey()
+	hashKeyValue, ok := req.Item[hashKey]
+
+	if !ok {
+		return nil, newAPIError(
+			"ValidationException",
+			"One or more parameter values were invalid: Missing the key %s in the item",
+			hashKey,
+		)
+	}
+
+	table.items[string(hashKeyValue)] = req.Item
+
+	return struct{}{}, nil
+}
@@ -0,0 +1,355 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const ec2XMLNamespace = "http://ec2.amazonaws.com/doc/2016-11-15/"
+
+type ec2Handler func(s *Server, params ec2Params) (interface{}, *apiError)
+
+var ec2Handlers = map[string]ec2Handler{
+	"CreateVpc":          (*Server).createVPC,
+	"DescribeVpcs":       (*Server).describeVPCs,
+	"ModifyVpcAttribute": (*Server).modifyVPCAttribute,
+	"DeleteVpc":          (*Server).deleteVPC,
+
+	"CreateSubnet":          (*Server).createSubnet,
+	"DescribeSubnets":       (*Server).describeSubnets,
+	"ModifySubnetAttribute": (*Server).modifySubnetAttribute,
+	"DeleteSubnet":          (*Server).deleteSubnet,
+
+	"CreateInternetGateway":    (*Server).createInternetGateway,
+	"DescribeInternetGateways": (*Server).describeInternetGateways,
+	"AttachInternetGateway":    (*Server).attachInternetGateway,
+	"DetachInternetGateway":    (*Server).detachInternetGateway,
+	"DeleteInternetGateway":    (*Server).deleteInternetGateway,
+
+	"CreateRouteTable":    (*Server).createRouteTable,
+	"DescribeRouteTables": (*Server).describeRouteTables,
+	"CreateRoute":         (*Server).createRoute,
+	"AssociateRouteTable": (*Server).associateRouteTable,
+	"DeleteRouteTable":    (*Server).deleteRouteTable,
+
+	"CreateNatGateway":    (*Server).createNATGateway,
+	"DescribeNatGateways": (*Server).describeNATGateways,
+	"DeleteNatGateway":    (*Server).deleteNATGateway,
+
+	"CreateVpcEndpoint":    (*Server).createVPCEndpoint,
+	"DescribeVpcEndpoints": (*Server).describeVPCEndpoints,
+	"DeleteVpcEndpoints":   (*Server).deleteVPCEndpoints,
+
+	"CreateSecurityGroup":           (*Server).createSecurityGroup,
+	"DescribeSecurityGroups":        (*Server).describeSecurityGroups,
+	"AuthorizeSecurityGroupIngress": (*Server).authorizeSecurityGroupIngress,
+	"RevokeSecurityGroupIngress":    (*Server).revokeSecurityGroupIngress,
+	"DeleteSecurityGroup":           (*Server).deleteSecurityGroup,
+
+	"CreateNetworkInterface":    (*Server).createNetworkInterface,
+	"DescribeNetworkInterfaces": (*Server).describeNetworkInterfaces,
+	"DeleteNetworkInterface":    (*Server).deleteNetworkInterface,
+
+	"AllocateAddress":     (*Server).allocateAddress,
+	"AssociateAddress":    (*Server).associateAddress,
+	"DisassociateAddress": (*Server).disassociateAddress,
+	"ReleaseAddress":      (*Server).releaseAddress,
+
+	"CreateKeyPair":    (*Server).createKeyPair,
+	"DescribeKeyPairs": (*Server).describeKeyPairs,
+	"DeleteKeyPair":    (*Server).deleteKeyPair,
+
+	"DescribeRegions":           (*Server).describeRegions,
+	"DescribeAvailabilityZones": (*Server).describeAvailabilityZones,
+
+	"DescribeInstanceTypes":         (*Server).describeInstanceTypes,
+	"DescribeInstanceTypeOfferings": (*Server).describeInstanceTypeOfferings,
+	"DescribeImages":                (*Server).describeImages,
+
+	"RunInstances":            (*Server).runInstances,
+	"DescribeInstances":       (*Server).describeInstances,
+	"StopInstances":           (*Server).stopInstances,
+	"StartInstances":          (*Server).startInstances,
+	"ModifyInstanceAttribute": (*Server).modifyInstanceAttribute,
+	"TerminateInstances":      (*Server).terminateInstances,
+	"GetConsoleOutput":        (*Server).getConsoleOutput,
+
+	"DescribeSpotInstanceRequests": (*Server).describeSpotInstanceRequests,
+	"CancelSpotInstanceRequests":   (*Server).cancelSpotInstanceRequests,
+
+	"CreateVolume":                 (*Server).createVolume,
+	"DescribeVolumes":              (*Server).describeVolumes,
+	"ModifyVolume":                 (*Server).modifyVolume,
+	"DescribeVolumesModifications": (*Server).describeVolumesModifications,
+	"AttachVolume":                 (*Server).attachVolume,
+	"DetachVolume":                 (*Server).detachVolume,
+	"DeleteVolume":                 (*Server).deleteVolume,
+
+	"CreateSnapshot":    (*Server).createSnapshot,
+	"DescribeSnapshots": (*Server).describeSnapshots,
+	"DeleteSnapshot":    (*Server).deleteSnapshot,
+}
+
+type apiError struct {
+	code    string
+	message string
+}
+
+func newAPIError(code string, format string, args ...interface{}) *apiError {
+	return &apiError{
+		code:    code,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+type ec2ErrorResponse struct {
+	XMLName   xml.Name `xml:"Response"`
+	Code      string   `xml:"Errors>Error>Code"`
+	Message   string   `xml:"Errors>Error>Message"`
+	RequestID string   `xml:"RequestID"`
+}
+
+// serveEC2 must be called with the lock held.
+func (s *Server) serveEC2(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		s.writeEC2Error(w, newAPIError("InternalError", "%v", err))
+		return
+	}
+
+	values, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		s.writeEC2Error(w, newAPIError("MalformedQueryString", "%v", err))
+		return
+	}
+
+	params := ec2Params(values)
+	action := params.get("Action")
+
+	if errorCode := s.popInjectedError(action); len(errorCode) > 0 {
+		s.writeEC2Error(w, newAPIError(errorCode, "Injected error for action %s", action))
+		return
+	}
+
+	handler, ok := ec2Handlers[action]
+
+	if !ok {
+		s.writeEC2Error(w, newAPIError("InvalidAction", "The action %s is not valid for this web service", action))
+		return
+	}
+
+	resp, apiErr := handler(s, params)
+
+	if apiErr != nil {
+		s.writeEC2Error(w, apiErr)
+		return
+	}
+
+	encodedResp, err := xml.Marshal(resp)
+
+	if err != nil {
+		s.writeEC2Error(w, newAPIError("InternalError", "%v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(encodedResp)
+}
+
+// writeEC2Error must be called with the lock held.
+func (s *Server) writeEC2Error(w http.ResponseWriter, apiErr *apiError) {
+	encodedResp, _ := xml.Marshal(ec2ErrorResponse{
+		Code:      apiErr.code,
+		Message:   apiErr.message,
+		RequestID: s.newRequestID(),
+	})
+
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(encodedResp)
+}
+
+// ec2Params represents the form-encoded
+// parameters of an EC2 query request.
+type ec2Params url.Values
+
+func (p ec2Params) get(key string) string {
+	return url.Values(p).Get(key)
+}
+
+func (p ec2Params) has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+func (p ec2Params) int32(key string, defaultValue int32) (int32, *apiError) {
+	value := p.get(key)
+
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	parsedValue, err := strconv.ParseInt(value, 10, 32)
+
+	if err != nil {
+		return 0, newAPIError("InvalidParameterValue", "Invalid value '%s' for %s", value, key)
+	}
+
+	return int32(parsedValue), nil
+}
+
+func (p ec2Params) bool(key string) bool {
+	return p.get(key) == "true"
+}
+
+// hasIndex checks if at least one parameter
+// is set for the item at "prefix.index".
+func (p ec2Params) hasIndex(prefix string, index int) bool {
+	itemKey := prefix + "." + strconv.Itoa(index)
+
+	for key := range p {
+		if key == itemKey || strings.HasPrefix(key, itemKey+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indexes returns the item prefixes ("prefix.1", "prefix.2"...)
+// of a list parameter.
+func (p ec2Params) indexes(prefix string) []string {
+	itemPrefixes := []string{}
+
+	for index := 1; p.hasIndex(prefix, index); index++ {
+		itemPrefixes = append(itemPrefixes, prefix+"."+strconv.Itoa(index))
+	}
+
+	return itemPrefixes
+}
+
+func (p ec2Params) list(prefix string) []string {
+	values := []string{}
+
+	for _, itemPrefix := range p.indexes(prefix) {
+		values = append(values, p.get(itemPrefix))
+	}
+
+	return values
+}
+
+func (p ec2Params) tags(resourceType string) map[string]string {
+	tags := map[string]string{}
+
+	for _, specPrefix := range p.indexes("TagSpecification") {
+		if p.get(specPrefix+".ResourceType") != resourceType {
+			continue
+		}
+
+		for _, tagPrefix := range p.indexes(specPrefix + ".Tag") {
+			tags[p.get(tagPrefix+".Key")] = p.get(tagPrefix + ".Value")
+		}
+	}
+
+	return tags
+}
+
+func (p ec2Params) filters() map[string][]string {
+	filters := map[string][]string{}
+
+	for _, filterPrefix := range p.indexes("Filter") {
+		filterName := p.get(filterPrefix + ".Name")
+		filters[filterName] = append(
+			filters[filterName],
+			p.list(filterPrefix+".Value")...,
+		)
+	}
+
+	return filters
+}
+
+type xmlTag struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+func toXMLTags(tags map[string]string) []xmlTag {
+	XMLTags := []xmlTag{}
+
+	for key, value := range tags {
+		XMLTags = append(XMLTags, xmlTag{
+			Key:   key,
+			Value: value,
+		})
+	}
+
+	return XMLTags
+}
+
+type ec2BooleanResponse struct {
+	XMLName   xml.Name `xml:"Response"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	Return    bool     `xml:"return"`
+}
+
+// newEC2BooleanResponse must be called with the lock held.
+func (s *Server) newEC2BooleanResponse() ec2BooleanResponse {
+	return ec2BooleanResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Return:    true,
+	}
+}
+
+// matchesFilterValues checks if at least one of the passed
+// values matches one of the filter values.
+// Filter values may contain "*" wildcards.
+func matchesFilterValues(filterValues []string, values ...string) bool {
+	for _, filterValue := range filterValues {
+		for _, value := range values {
+			if matchesWildcard(filterValue, value) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchesWildcard(pattern string, value string) bool {
+	patternParts := strings.Split(pattern, "*")
+
+	if len(patternParts) == 1 {
+		return pattern == value
+	}
+
+	if !strings.HasPrefix(value, patternParts[0]) {
+		return false
+	}
+
+	value = value[len(patternParts[0]):]
+	lastPart := patternParts[len(patternParts)-1]
+
+	for _, part := range patternParts[1 : len(patternParts)-1] {
+		partIndex := strings.Index(value, part)
+
+		if partIndex == -1 {
+			return false
+		}
+
+		value = value[partIndex+len(part):]
+	}
+
+	return strings.HasSuffix(value, lastPart)
+}
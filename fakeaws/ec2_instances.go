@@ -0,0 +1,1184 @@
+package fakeaws
+
+import (
+	"crypto/ed25519"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// UbuntuOwnerID represents the ID of the account
+	// that owns the Ubuntu images returned by the server.
+	UbuntuOwnerID = "099720109477"
+
+	instanceStateCodePending    = 0
+	instanceStateCodeRunning    = 16
+	instanceStateCodeTerminated = 48
+	instanceStateCodeStopping   = 64
+	instanceStateCodeStopped    = 80
+
+	defaultRootVolumeSizeGb = 8
+)
+
+type instanceTypeInfos struct {
+	name                 string
+	architectures        []string
+	memorySizeInMiB      int64
+	defaultVCPUs         int32
+	hibernationSupported bool
+	usageClasses         []string
+}
+
+// instanceTypes lists the instance types known by the server.
+var instanceTypes = map[string]instanceTypeInfos{
+	"t2.micro":     {"t2.micro", []string{"i386", "x86_64"}, 1024, 1, true, []string{"on-demand", "spot"}},
+	"t2.medium":    {"t2.medium", []string{"i386", "x86_64"}, 4096, 2, true, []string{"on-demand", "spot"}},
+	"t3.medium":    {"t3.medium", []string{"x86_64"}, 4096, 2, true, []string{"on-demand", "spot"}},
+	"m5.large":     {"m5.large", []string{"x86_64"}, 8192, 2, true, []string{"on-demand", "spot"}},
+	"c5.12xlarge":  {"c5.12xlarge", []string{"x86_64"}, 98304, 48, false, []string{"on-demand", "spot"}},
+	"m6g.large":    {"m6g.large", []string{"arm64"}, 8192, 2, false, []string{"on-demand", "spot"}},
+	"a1.xlarge":    {"a1.xlarge", []string{"arm64"}, 8192, 4, false, []string{"on-demand", "spot"}},
+	"mac1.metal":   {"mac1.metal", []string{"x86_64_mac"}, 32768, 12, false, []string{"on-demand"}},
+	"u-6tb1.metal": {"u-6tb1.metal", []string{"x86_64"}, 6291456, 448, false, []string{"on-demand"}},
+}
+
+// instanceTypeUnofferedZones lists the availability
+// zones where an instance type is not offered.
+var instanceTypeUnofferedZones = map[string][]string{
+	"m6g.large": {AvailabilityZone},
+}
+
+func isInstanceTypeOfferedInZone(instanceTypeName, availabilityZone string) bool {
+	for _, unofferedZone := range instanceTypeUnofferedZones[instanceTypeName] {
+		if unofferedZone == availabilityZone {
+			return false
+		}
+	}
+
+	return isAvailabilityZone(availabilityZone)
+}
+
+type image struct {
+	id             string
+	name           string
+	architecture   string
+	creationDate   string
+	rootDeviceName string
+}
+
+// images lists the images known by the server.
+var images = map[string]image{
+	"ami-0a1b2c3d4e5f60001": {
+		id:             "ami-0a1b2c3d4e5f60001",
+		name:           "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-amd64-server-20220609",
+		architecture:   "x86_64",
+		creationDate:   "2022-06-09T13:12:38.000Z",
+		rootDeviceName: "/dev/sda1",
+	},
+	"ami-0a1b2c3d4e5f60002": {
+		id:             "ami-0a1b2c3d4e5f60002",
+		name:           "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-amd64-server-20220706",
+		architecture:   "x86_64",
+		creationDate:   "2022-07-06T20:48:41.000Z",
+		rootDeviceName: "/dev/sda1",
+	},
+	"ami-0a1b2c3d4e5f60003": {
+		id:             "ami-0a1b2c3d4e5f60003",
+		name:           "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-arm64-server-20220609",
+		architecture:   "arm64",
+		creationDate:   "2022-06-09T13:12:41.000Z",
+		rootDeviceName: "/dev/sda1",
+	},
+	"ami-0a1b2c3d4e5f60004": {
+		id:             "ami-0a1b2c3d4e5f60004",
+		name:           "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-arm64-server-20220706",
+		architecture:   "arm64",
+		creationDate:   "2022-07-06T20:48:45.000Z",
+		rootDeviceName: "/dev/sda1",
+	},
+}
+
+type xmlKeyPair struct {
+	KeyPairID      string   `xml:"keyPairId"`
+	KeyName        string   `xml:"keyName"`
+	KeyFingerprint string   `xml:"keyFingerprint"`
+	KeyType        string   `xml:"keyType"`
+	Tags           []xmlTag `xml:"tagSet>item"`
+}
+
+func (k *keyPair) toXML() xmlKeyPair {
+	return xmlKeyPair{
+		KeyPairID:      k.id,
+		KeyName:        k.name,
+		KeyFingerprint: k.fingerprint,
+		KeyType:        "ed25519",
+		Tags:           toXMLTags(k.tags),
+	}
+}
+
+type createKeyPairResponse struct {
+	XMLName        xml.Name `xml:"CreateKeyPairResponse"`
+	Namespace      string   `xml:"xmlns,attr"`
+	RequestID      string   `xml:"requestId"`
+	KeyPairID      string   `xml:"keyPairId"`
+	KeyName        string   `xml:"keyName"`
+	KeyFingerprint string   `xml:"keyFingerprint"`
+	KeyMaterial    string   `xml:"keyMaterial"`
+}
+
+func (s *Server) createKeyPair(params ec2Params) (interface{}, *apiError) {
+	keyName := params.get("KeyName")
+
+	if len(keyName) == 0 {
+		return nil, newAPIError("MissingParameter", "The request must contain the parameter KeyName")
+	}
+
+	if keyType := params.get("KeyType"); len(keyType) > 0 && keyType != "ed25519" {
+		return nil, newAPIError("InvalidParameterValue", "Only ed25519 key pairs are supported by the fake backend, got '%s'", keyType)
+	}
+
+	for _, existingKeyPair := range s.ec2.keyPairs {
+		if existingKeyPair.name == keyName {
+			return nil, newAPIError("InvalidKeyPair.Duplicate", "The keypair '%s' already exists.", keyName)
+		}
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		return nil, newAPIError("InternalError", "%v", err)
+	}
+
+	encodedPrivateKey, err := x509.MarshalPKCS8PrivateKey(privateKey)
+
+	if err != nil {
+		return nil, newAPIError("InternalError", "%v", err)
+	}
+
+	SSHPublicKey, err := ssh.NewPublicKey(publicKey)
+
+	if err != nil {
+		return nil, newAPIError("InternalError", "%v", err)
+	}
+
+	keyMaterial := pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: encodedPrivateKey,
+	})
+
+	fingerprintBytes := md5.Sum(SSHPublicKey.Marshal())
+	fingerprintParts := make([]string, len(fingerprintBytes))
+
+	for index, fingerprintByte := range fingerprintBytes {
+		fingerprintParts[index] = fmt.Sprintf("%02x", fingerprintByte)
+	}
+
+	createdKeyPair := &keyPair{
+		id:          s.newID("key"),
+		name:        keyName,
+		publicKey:   SSHPublicKey,
+		fingerprint: strings.Join(fingerprintParts, ":"),
+		tags:        params.tags("key-pair"),
+	}
+
+	s.ec2.keyPairs[createdKeyPair.id] = createdKeyPair
+
+	return createKeyPairResponse{
+		Namespace:      ec2XMLNamespace,
+		RequestID:      s.newRequestID(),
+		KeyPairID:      createdKeyPair.id,
+		KeyName:        createdKeyPair.name,
+		KeyFingerprint: createdKeyPair.fingerprint,
+		KeyMaterial:    string(keyMaterial),
+	}, nil
+}
+
+type describeKeyPairsResponse struct {
+	XMLName   xml.Name     `xml:"DescribeKeyPairsResponse"`
+	Namespace string       `xml:"xmlns,attr"`
+	RequestID string       `xml:"requestId"`
+	KeyPairs  []xmlKeyPair `xml:"keySet>item"`
+}
+
+func (s *Server) describeKeyPairs(params ec2Params) (interface{}, *apiError) {
+	keyPairs := []xmlKeyPair{}
+
+	for _, keyPairID := range params.list("KeyPairId") {
+		keyPair, ok := s.ec2.keyPairs[keyPairID]
+
+		if !ok {
+			return nil, newAPIError("InvalidKeyPair.NotFound", "The key pair '%s' does not exist", keyPairID)
+		}
+
+		keyPairs = append(keyPairs, keyPair.toXML())
+	}
+
+	return describeKeyPairsResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		KeyPairs:  keyPairs,
+	}, nil
+}
+
+func (s *Server) deleteKeyPair(params ec2Params) (interface{}, *apiError) {
+	// Like the real API, deleting an
+	// unknown key pair is not an error
+	if keyPairID := params.get("KeyPairId"); len(keyPairID) > 0 {
+		delete(s.ec2.keyPairs, keyPairID)
+		return s.newEC2BooleanResponse(), nil
+	}
+
+	keyName := params.get("KeyName")
+
+	for keyPairID, keyPair := range s.ec2.keyPairs {
+		if keyPair.name == keyName {
+			delete(s.ec2.keyPairs, keyPairID)
+		}
+	}
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlInstanceType struct {
+	InstanceType             string   `xml:"instanceType"`
+	SupportedArchitectures   []string `xml:"processorInfo>supportedArchitectures>item"`
+	DefaultVCPUs             int32    `xml:"vCpuInfo>defaultVCpus"`
+	MemorySizeInMiB          int64    `xml:"memoryInfo>sizeInMiB"`
+	HibernationSupported     bool     `xml:"hibernationSupported"`
+	SupportedRootDeviceTypes []string `xml:"supportedRootDeviceTypes>item"`
+	SupportedUsageClasses    []string `xml:"supportedUsageClasses>item"`
+}
+
+type describeInstanceTypesResponse struct {
+	XMLName       xml.Name          `xml:"DescribeInstanceTypesResponse"`
+	Namespace     string            `xml:"xmlns,attr"`
+	RequestID     string            `xml:"requestId"`
+	InstanceTypes []xmlInstanceType `xml:"instanceTypeSet>item"`
+}
+
+func (s *Server) describeInstanceTypes(params ec2Params) (interface{}, *apiError) {
+	filters := params.filters()
+	XMLInstanceTypes := []xmlInstanceType{}
+
+	for _, instanceTypeName := range params.list("InstanceType") {
+		instanceType, ok := instanceTypes[instanceTypeName]
+
+		if !ok {
+			return nil, newAPIError(
+				"InvalidInstanceType",
+				"The following supplied instance types do not exist: [%s]",
+				instanceTypeName,
+			)
+		}
+
+		if values, ok := filters["processor-info.supported-architecture"]; ok &&
+			!matchesFilterValues(values, instanceType.architectures...) {
+
+			continue
+		}
+
+		if values, ok := filters["supported-root-device-type"]; ok &&
+			!matchesFilterValues(values, "ebs") {
+
+			continue
+		}
+
+		if values, ok := filters["supported-usage-class"]; ok &&
+			!matchesFilterValues(values, instanceType.usageClasses...) {
+
+			continue
+		}
+
+		XMLInstanceTypes = append(XMLInstanceTypes, xmlInstanceType{
+			InstanceType:             instanceType.name,
+			SupportedArchitectures:   instanceType.architectures,
+			DefaultVCPUs:             instanceType.defaultVCPUs,
+			MemorySizeInMiB:          instanceType.memorySizeInMiB,
+			HibernationSupported:     instanceType.hibernationSupported,
+			SupportedRootDeviceTypes: []string{"ebs"},
+			SupportedUsageClasses:    instanceType.usageClasses,
+		})
+	}
+
+	return describeInstanceTypesResponse{
+		Namespace:     ec2XMLNamespace,
+		RequestID:     s.newRequestID(),
+		InstanceTypes: XMLInstanceTypes,
+	}, nil
+}
+
+type xmlInstanceTypeOffering struct {
+	InstanceType string `xml:"instanceType"`
+	LocationType string `xml:"locationType"`
+	Location     string `xml:"location"`
+}
+
+type describeInstanceTypeOfferingsResponse struct {
+	XMLName               xml.Name                  `xml:"DescribeInstanceTypeOfferingsResponse"`
+	Namespace             string                    `xml:"xmlns,attr"`
+	RequestID             string                    `xml:"requestId"`
+	InstanceTypeOfferings []xmlInstanceTypeOffering `xml:"instanceTypeOfferingSet>item"`
+}
+
+func (s *Server) describeInstanceTypeOfferings(params ec2Params) (interface{}, *apiError) {
+	if params.get("LocationType") != "availability-zone" {
+		return nil, newAPIError("InvalidParameterValue", "Only the availability-zone location type is supported")
+	}
+
+	filters := params.filters()
+
+	instanceTypeNames := []string{}
+
+	for instanceTypeName := range instanceTypes {
+		instanceTypeNames = append(instanceTypeNames, instanceTypeName)
+	}
+
+	sort.Strings(instanceTypeNames)
+
+	XMLOfferings := []xmlInstanceTypeOffering{}
+
+	for _, instanceTypeName := range instanceTypeNames {
+		if values, ok := filters["instance-type"]; ok &&
+			!matchesFilterValues(values, instanceTypeName) {
+
+			continue
+		}
+
+		for _, availabilityZone := range availabilityZones {
+			if !isInstanceTypeOfferedInZone(instanceTypeName, availabilityZone) {
+				continue
+			}
+
+			if values, ok := filters["location"]; ok &&
+				!matchesFilterValues(values, availabilityZone) {
+
+				continue
+			}
+
+			XMLOfferings = append(XMLOfferings, xmlInstanceTypeOffering{
+				InstanceType: instanceTypeName,
+				LocationType: "availability-zone",
+				Location:     availabilityZone,
+			})
+		}
+	}
+
+	return describeInstanceTypeOfferingsResponse{
+		Namespace:             ec2XMLNamespace,
+		RequestID:             s.newRequestID(),
+		InstanceTypeOfferings: XMLOfferings,
+	}, nil
+}
+
+type xmlImage struct {
+	ImageID            string `xml:"imageId"`
+	Name               string `xml:"name"`
+	ImageState         string `xml:"imageState"`
+	ImageOwnerID       string `xml:"imageOwnerId"`
+	Architecture       string `xml:"architecture"`
+	CreationDate       string `xml:"creationDate"`
+	RootDeviceName     string `xml:"rootDeviceName"`
+	RootDeviceType     string `xml:"rootDeviceType"`
+	VirtualizationType string `xml:"virtualizationType"`
+}
+
+type describeImagesResponse struct {
+	XMLName   xml.Name   `xml:"DescribeImagesResponse"`
+	Namespace string     `xml:"xmlns,attr"`
+	RequestID string     `xml:"requestId"`
+	Images    []xmlImage `xml:"imagesSet>item"`
+}
+
+func (s *Server) describeImages(params ec2Params) (interface{}, *apiError) {
+	filters := params.filters()
+	owners := params.list("Owner")
+	XMLImages := []xmlImage{}
+
+	for _, image := range images {
+		if len(owners) > 0 && !matchesFilterValues(owners, UbuntuOwnerID) {
+			continue
+		}
+
+		if values, ok := filters["name"]; ok && !matchesFilterValues(values, image.name) {
+			continue
+		}
+
+		if values, ok := filters["architecture"]; ok && !matchesFilterValues(values, image.architecture) {
+			continue
+		}
+
+		if values, ok := filters["root-device-type"]; ok && !matchesFilterValues(values, "ebs") {
+			continue
+		}
+
+		if values, ok := filters["virtualization-type"]; ok && !matchesFilterValues(values, "hvm") {
+			continue
+		}
+
+		XMLImages = append(XMLImages, xmlImage{
+			ImageID:            image.id,
+			Name:               image.name,
+			ImageState:         "available",
+			ImageOwnerID:       UbuntuOwnerID,
+			Architecture:       image.architecture,
+			CreationDate:       image.creationDate,
+			RootDeviceName:     image.rootDeviceName,
+			RootDeviceType:     "ebs",
+			VirtualizationType: "hvm",
+		})
+	}
+
+	return describeImagesResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Images:    XMLImages,
+	}, nil
+}
+
+type xmlInstanceState struct {
+	Code int32  `xml:"code"`
+	Name string `xml:"name"`
+}
+
+type xmlInstanceBlockDevice struct {
+	DeviceName          string `xml:"deviceName"`
+	VolumeID            string `xml:"ebs>volumeId"`
+	Status              string `xml:"ebs>status"`
+	DeleteOnTermination bool   `xml:"ebs>deleteOnTermination"`
+}
+
+type xmlInstanceNetworkInterface struct {
+	NetworkInterfaceID string `xml:"networkInterfaceId"`
+	SubnetID           string `xml:"subnetId"`
+	VPCID              string `xml:"vpcId"`
+	PrivateIPAddress   string `xml:"privateIpAddress"`
+	Status             string `xml:"status"`
+}
+
+type xmlInstance struct {
+	InstanceID         string                        `xml:"instanceId"`
+	ImageID            string                        `xml:"imageId"`
+	InstanceState      xmlInstanceState              `xml:"instanceState"`
+	KeyName            string                        `xml:"keyName"`
+	InstanceType       string                        `xml:"instanceType"`
+	AvailabilityZone   string                        `xml:"placement>availabilityZone"`
+	SubnetID           string                        `xml:"subnetId,omitempty"`
+	VPCID              string                        `xml:"vpcId,omitempty"`
+	PrivateIPAddress   string                        `xml:"privateIpAddress,omitempty"`
+	PublicIPAddress    string                        `xml:"ipAddress,omitempty"`
+	RootDeviceName     string                        `xml:"rootDeviceName"`
+	RootDeviceType     string                        `xml:"rootDeviceType"`
+	BlockDeviceMapping []xmlInstanceBlockDevice      `xml:"blockDeviceMapping>item"`
+	NetworkInterfaces  []xmlInstanceNetworkInterface `xml:"networkInterfaceSet>item"`
+	Tags               []xmlTag                      `xml:"tagSet>item"`
+
+	InstanceLifecycle     string `xml:"instanceLifecycle,omitempty"`
+	SpotInstanceRequestID string `xml:"spotInstanceRequestId,omitempty"`
+}
+
+func (s *Server) instanceToXML(i *instance) xmlInstance {
+	XMLInstance := xmlInstance{
+		InstanceID:     i.id,
+		ImageID:        i.imageID,
+		KeyName:        i.keyName,
+		InstanceType:   i.instanceType,
+		RootDeviceName: i.rootDeviceName,
+		RootDeviceType: "ebs",
+		Tags:           toXMLTags(i.tags),
+	}
+
+	if len(i.spotInstanceRequestID) > 0 {
+		XMLInstance.InstanceLifecycle = "spot"
+		XMLInstance.SpotInstanceRequestID = i.spotInstanceRequestID
+	}
+
+	subnet := s.ec2.subnets[i.subnetID]
+
+	if subnet != nil {
+		XMLInstance.AvailabilityZone = subnet.availabilityZone
+	}
+
+	switch i.state {
+	case instanceStatePending:
+		XMLInstance.InstanceState = xmlInstanceState{instanceStateCodePending, i.state}
+	case instanceStateRunning:
+		XMLInstance.InstanceState = xmlInstanceState{instanceStateCodeRunning, i.state}
+	case instanceStateStopped:
+		XMLInstance.InstanceState = xmlInstanceState{instanceStateCodeStopped, i.state}
+	default:
+		XMLInstance.InstanceState = xmlInstanceState{instanceStateCodeTerminated, i.state}
+	}
+
+	// Terminated instances lose their network
+	// and block devices information
+	if i.state == instanceStateTerminated {
+		return XMLInstance
+	}
+
+	XMLInstance.SubnetID = i.subnetID
+	XMLInstance.PrivateIPAddress = i.privateIPAddress
+	XMLInstance.PublicIPAddress = i.publicIPAddress
+
+	if subnet != nil {
+		XMLInstance.VPCID = subnet.vpcID
+	}
+
+	for _, volume := range i.volumes {
+		XMLInstance.BlockDeviceMapping = append(XMLInstance.BlockDeviceMapping, xmlInstanceBlockDevice{
+			DeviceName:          volume.deviceName,
+			VolumeID:            volume.volumeID,
+			Status:              "attached",
+			DeleteOnTermination: volume.deviceName == i.rootDeviceName,
+		})
+	}
+
+	XMLInstance.NetworkInterfaces = []xmlInstanceNetworkInterface{{
+		NetworkInterfaceID: i.networkInterfaceID,
+		SubnetID:           i.subnetID,
+		VPCID:              XMLInstance.VPCID,
+		PrivateIPAddress:   i.privateIPAddress,
+		Status:             "in-use",
+	}}
+
+	return XMLInstance
+}
+
+type xmlReservation struct {
+	ReservationID string        `xml:"reservationId"`
+	OwnerID       string        `xml:"ownerId"`
+	Instances     []xmlInstance `xml:"instancesSet>item"`
+}
+
+type runInstancesResponse struct {
+	XMLName   xml.Name `xml:"RunInstancesResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	xmlReservation
+}
+
+func (s *Server) runInstances(params ec2Params) (interface{}, *apiError) {
+	if params.get("MinCount") != "1" || params.get("MaxCount") != "1" {
+		return nil, newAPIError("InvalidParameterValue", "The fake backend only supports launching one instance at a time")
+	}
+
+	instanceTypeName := params.get("InstanceType")
+	instanceType, ok := instanceTypes[instanceTypeName]
+
+	if !ok {
+		return nil, newAPIError("InvalidParameterValue", "Invalid value '%s' for InstanceType.", instanceTypeName)
+	}
+
+	imageID := params.get("ImageId")
+	image, ok := images[imageID]
+
+	if !ok {
+		return nil, newAPIError("InvalidAMIID.NotFound", "The image id '[%s]' does not exist", imageID)
+	}
+
+	if !matchesFilterValues(instanceType.architectures, image.architecture) {
+		return nil, newAPIError(
+			"InvalidParameterValue",
+			"The architecture '%s' of the specified instance type does not match the architecture '%s' of the specified AMI.",
+			strings.Join(instanceType.architectures, ", "),
+			image.architecture,
+		)
+	}
+
+	keyName := params.get("KeyName")
+	keyPairFound := false
+
+	for _, keyPair := range s.ec2.keyPairs {
+		keyPairFound = keyPairFound || keyPair.name == keyName
+	}
+
+	if !keyPairFound {
+		return nil, newAPIError("InvalidKeyPair.NotFound", "The key pair '%s' does not exist", keyName)
+	}
+
+	networkInterfaceID := params.get("NetworkInterface.1.NetworkInterfaceId")
+	networkInterface, ok := s.ec2.networkInterfaces[networkInterfaceID]
+
+	if !ok {
+		return nil, newAPIError("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", networkInterfaceID)
+	}
+
+	if len(networkInterface.instanceID) > 0 {
+		return nil, newAPIError("InvalidNetworkInterface.InUse", "Interface: [%s] in use.", networkInterfaceID)
+	}
+
+	rootVolumeSize, apiErr := params.int32("BlockDeviceMapping.1.Ebs.VolumeSize", defaultRootVolumeSizeGb)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rootVolumeType := params.get("BlockDeviceMapping.1.Ebs.VolumeType")
+
+	if len(rootVolumeType) == 0 {
+		rootVolumeType = "gp2"
+	}
+
+	if rootVolumeType != "gp2" && rootVolumeType != "gp3" {
+		return nil, newAPIError("InvalidParameterValue", "The fake backend only supports the 'gp2' and 'gp3' volume types, got '%s'.", rootVolumeType)
+	}
+
+	rootVolumeIOPS, apiErr := params.int32("BlockDeviceMapping.1.Ebs.Iops", 0)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	rootVolumeThroughput, apiErr := params.int32("BlockDeviceMapping.1.Ebs.Throughput", 0)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if rootVolumeType != "gp3" && (rootVolumeIOPS != 0 || rootVolumeThroughput != 0) {
+		return nil, newAPIError("InvalidParameterCombination", "The parameters iops and throughput are only supported for gp3 volumes.")
+	}
+
+	// Like the real API, gp3 volumes default to the baseline performance
+	if rootVolumeType == "gp3" && rootVolumeIOPS == 0 {
+		rootVolumeIOPS = 3000
+	}
+
+	if rootVolumeType == "gp3" && rootVolumeThroughput == 0 {
+		rootVolumeThroughput = 125
+	}
+
+	rootVolumeEncrypted := params.bool("BlockDeviceMapping.1.Ebs.Encrypted")
+	rootVolumeKMSKeyID := params.get("BlockDeviceMapping.1.Ebs.KmsKeyId")
+
+	if len(rootVolumeKMSKeyID) > 0 && !rootVolumeEncrypted {
+		return nil, newAPIError("InvalidParameterDependency", "The parameter KmsKeyId requires the parameter Encrypted to be set.")
+	}
+	hibernationConfigured := params.bool("HibernationOptions.Configured")
+
+	if hibernationConfigured {
+		memorySizeInGb := int32((instanceType.memorySizeInMiB + 1023) / 1024)
+
+		if !instanceType.hibernationSupported {
+			return nil, newAPIError(
+				"UnsupportedHibernationConfiguration",
+				"The instance type '%s' does not support hibernation.",
+				instanceTypeName,
+			)
+		}
+
+		if !rootVolumeEncrypted {
+			return nil, newAPIError(
+				"UnsupportedHibernationConfiguration",
+				"The root volume must be encrypted to enable hibernation.",
+			)
+		}
+
+		if rootVolumeSize < defaultRootVolumeSizeGb+memorySizeInGb {
+			return nil, newAPIError(
+				"UnsupportedHibernationConfiguration",
+				"The root volume must be large enough to store the RAM (%d GiB).",
+				memorySizeInGb,
+			)
+		}
+	}
+
+	marketType := params.get("InstanceMarketOptions.MarketType")
+	spotInstanceType := params.get("InstanceMarketOptions.SpotOptions.SpotInstanceType")
+	spotInterruptionBehavior := params.get("InstanceMarketOptions.SpotOptions.InstanceInterruptionBehavior")
+
+	if len(marketType) > 0 && marketType != "spot" {
+		return nil, newAPIError("InvalidParameterValue", "Invalid value '%s' for MarketType.", marketType)
+	}
+
+	if marketType == "spot" {
+		if !matchesFilterValues(instanceType.usageClasses, "spot") {
+			return nil, newAPIError(
+				"Unsupported",
+				"The instance type '%s' is not supported for spot instances.",
+				instanceTypeName,
+			)
+		}
+
+		if len(spotInstanceType) == 0 {
+			spotInstanceType = "one-time"
+		}
+
+		if len(spotInterruptionBehavior) == 0 {
+			spotInterruptionBehavior = "terminate"
+		}
+
+		if spotInterruptionBehavior != "terminate" && spotInstanceType != "persistent" {
+			return nil, newAPIError(
+				"InvalidParameterCombination",
+				"The interruption behavior '%s' is only supported for persistent spot requests.",
+				spotInterruptionBehavior,
+			)
+		}
+
+		if spotInterruptionBehavior == "hibernate" && !hibernationConfigured {
+			return nil, newAPIError(
+				"InvalidParameterCombination",
+				"The interruption behavior 'hibernate' requires hibernation to be configured.",
+			)
+		}
+	}
+
+	subnet := s.ec2.subnets[networkInterface.subnetID]
+
+	if !isInstanceTypeOfferedInZone(instanceTypeName, subnet.availabilityZone) {
+		return nil, newAPIError(
+			"Unsupported",
+			"Your requested instance type (%s) is not supported in your requested Availability Zone (%s).",
+			instanceTypeName,
+			subnet.availabilityZone,
+		)
+	}
+
+	createdInstance := &instance{
+		id:                 s.newID("i"),
+		instanceType:       instanceTypeName,
+		imageID:            imageID,
+		keyName:            keyName,
+		instanceProfile:    params.get("IamInstanceProfile.Name"),
+		state:              instanceStateRunning,
+		subnetID:           subnet.id,
+		networkInterfaceID: networkInterfaceID,
+		privateIPAddress:   networkInterface.privateIPAddress,
+		rootDeviceName:     image.rootDeviceName,
+		userData:           params.get("UserData"),
+		agentHostKey:       string(ssh.MarshalAuthorizedKey(s.hostKeySigner.PublicKey())),
+		tags:               params.tags("instance"),
+
+		hibernationConfigured: hibernationConfigured,
+	}
+
+	if subnet.mapPublicIPOnLaunch {
+		createdInstance.publicIPAddress = s.ec2.newPublicIPAddress("203.0.113")
+	}
+
+	rootVolume := &volume{
+		id:               s.newID("vol"),
+		size:             rootVolumeSize,
+		volumeType:       rootVolumeType,
+		availabilityZone: subnet.availabilityZone,
+		instanceID:       createdInstance.id,
+		deviceName:       image.rootDeviceName,
+		encrypted:        rootVolumeEncrypted,
+		kmsKeyID:         rootVolumeKMSKeyID,
+		iops:             rootVolumeIOPS,
+		throughput:       rootVolumeThroughput,
+		tags:             map[string]string{},
+	}
+
+	createdInstance.volumes = []instanceVolume{{
+		deviceName: rootVolume.deviceName,
+		volumeID:   rootVolume.id,
+	}}
+
+	networkInterface.instanceID = createdInstance.id
+
+	// Like the real API, the spot request is
+	// fulfilled when the instance is launched
+	if marketType == "spot" {
+		spotRequest := &spotInstanceRequest{
+			id:                   s.newID("sir"),
+			instanceID:           createdInstance.id,
+			requestType:          spotInstanceType,
+			maxPrice:             params.get("InstanceMarketOptions.SpotOptions.MaxPrice"),
+			interruptionBehavior: spotInterruptionBehavior,
+		}
+
+		spotRequest.setStatus(spotInstanceRequestStateActive, "fulfilled", "Your spot request is fulfilled.")
+
+		createdInstance.spotInstanceRequestID = spotRequest.id
+		s.ec2.spotInstanceRequests[spotRequest.id] = spotRequest
+	}
+
+	s.ec2.volumes[rootVolume.id] = rootVolume
+	s.ec2.instances[createdInstance.id] = createdInstance
+
+	// The instance is immediately running but,
+	// like the real API, is returned as pending
+	XMLInstance := s.instanceToXML(createdInstance)
+	XMLInstance.InstanceState = xmlInstanceState{instanceStateCodePending, instanceStatePending}
+	XMLInstance.PublicIPAddress = ""
+
+	return runInstancesResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		xmlReservation: xmlReservation{
+			ReservationID: s.newID("r"),
+			OwnerID:       "000000000000",
+			Instances:     []xmlInstance{XMLInstance},
+		},
+	}, nil
+}
+
+type describeInstancesResponse struct {
+	XMLName      xml.Name         `xml:"DescribeInstancesResponse"`
+	Namespace    string           `xml:"xmlns,attr"`
+	RequestID    string           `xml:"requestId"`
+	Reservations []xmlReservation `xml:"reservationSet>item"`
+}
+
+func (s *Server) describeInstances(params ec2Params) (interface{}, *apiError) {
+	reservations := []xmlReservation{}
+
+	for _, instanceID := range params.list("InstanceId") {
+		instance, ok := s.ec2.instances[instanceID]
+
+		if !ok {
+			return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+		}
+
+		reservations = append(reservations, xmlReservation{
+			ReservationID: "r-" + strings.TrimPrefix(instance.id, "i-"),
+			OwnerID:       "000000000000",
+			Instances:     []xmlInstance{s.instanceToXML(instance)},
+		})
+	}
+
+	return describeInstancesResponse{
+		Namespace:    ec2XMLNamespace,
+		RequestID:    s.newRequestID(),
+		Reservations: reservations,
+	}, nil
+}
+
+type xmlInstanceStateChange struct {
+	InstanceID    string           `xml:"instanceId"`
+	CurrentState  xmlInstanceState `xml:"currentState"`
+	PreviousState xmlInstanceState `xml:"previousState"`
+}
+
+type terminateInstancesResponse struct {
+	XMLName   xml.Name                 `xml:"TerminateInstancesResponse"`
+	Namespace string                   `xml:"xmlns,attr"`
+	RequestID string                   `xml:"requestId"`
+	Instances []xmlInstanceStateChange `xml:"instancesSet>item"`
+}
+
+func (s *Server) terminateInstances(params ec2Params) (interface{}, *apiError) {
+	stateChanges := []xmlInstanceStateChange{}
+	instanceIDs := params.list("InstanceId")
+
+	for _, instanceID := range instanceIDs {
+		if _, ok := s.ec2.instances[instanceID]; !ok {
+			return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+		}
+	}
+
+	for _, instanceID := range instanceIDs {
+		instance := s.ec2.instances[instanceID]
+		previousState := s.instanceToXML(instance).InstanceState
+
+		if instance.state != instanceStateTerminated {
+			s.terminateInstance(instance)
+		}
+
+		stateChanges = append(stateChanges, xmlInstanceStateChange{
+			InstanceID:    instanceID,
+			CurrentState:  xmlInstanceState{instanceStateCodeTerminated, instanceStateTerminated},
+			PreviousState: previousState,
+		})
+	}
+
+	return terminateInstancesResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Instances: stateChanges,
+	}, nil
+}
+
+type stopInstancesResponse struct {
+	XMLName   xml.Name                 `xml:"StopInstancesResponse"`
+	Namespace string                   `xml:"xmlns,attr"`
+	RequestID string                   `xml:"requestId"`
+	Instances []xmlInstanceStateChange `xml:"instancesSet>item"`
+}
+
+// stopInstances immediately stops (or hibernates) the instances
+// but, like the real API, returns them as stopping. The
+// auto-assigned public IP addresses are released but the
+// elastic IP associations are kept.
+func (s *Server) stopInstances(params ec2Params) (interface{}, *apiError) {
+	stateChanges := []xmlInstanceStateChange{}
+	instanceIDs := params.list("InstanceId")
+	hibernate := params.bool("Hibernate")
+
+	for _, instanceID := range instanceIDs {
+		instance, ok := s.ec2.instances[instanceID]
+
+		if !ok {
+			return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+		}
+
+		if instance.state == instanceStateTerminated {
+			return nil, newAPIError("IncorrectInstanceState", "This instance '%s' is not in a state from which it can be stopped.", instanceID)
+		}
+
+		if hibernate && !instance.hibernationConfigured {
+			return nil, newAPIError("UnsupportedHibernationConfiguration", "Hibernation is not configured for the instance '%s'.", instanceID)
+		}
+	}
+
+	for _, instanceID := range instanceIDs {
+		instance := s.ec2.instances[instanceID]
+		previousState := s.instanceToXML(instance).InstanceState
+		currentState := xmlInstanceState{instanceStateCodeStopping, instanceStateStopping}
+
+		if instance.state == instanceStateStopped {
+			currentState = previousState
+		} else {
+			instance.hibernated = hibernate
+		}
+
+		// Like the real API, the persistent spot requests
+		// are disabled while their instance is stopped
+		if spotRequest := s.instanceSpotRequest(instance.id); spotRequest != nil &&
+			spotRequest.state == spotInstanceRequestStateActive {
+
+			spotRequest.setStatus(spotInstanceRequestStateDisabled, "instance-stopped-by-user", "Spot instance stopped by user.")
+		}
+
+		instance.state = instanceStateStopped
+
+		if s.ec2.elasticIPByInstanceID(instance.id) == nil {
+			instance.publicIPAddress = ""
+		}
+
+		stateChanges = append(stateChanges, xmlInstanceStateChange{
+			InstanceID:    instanceID,
+			CurrentState:  currentState,
+			PreviousState: previousState,
+		})
+	}
+
+	return stopInstancesResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Instances: stateChanges,
+	}, nil
+}
+
+type startInstancesResponse struct {
+	XMLName   xml.Name                 `xml:"StartInstancesResponse"`
+	Namespace string                   `xml:"xmlns,attr"`
+	RequestID string                   `xml:"requestId"`
+	Instances []xmlInstanceStateChange `xml:"instancesSet>item"`
+}
+
+// startInstances immediately starts the instances but, like
+// the real API, returns them as pending. A new public IP address
+// is assigned to the instances without elastic IP when their
+// subnet maps public IPs on launch.
+func (s *Server) startInstances(params ec2Params) (interface{}, *apiError) {
+	stateChanges := []xmlInstanceStateChange{}
+	instanceIDs := params.list("InstanceId")
+
+	for _, instanceID := range instanceIDs {
+		instance, ok := s.ec2.instances[instanceID]
+
+		if !ok {
+			return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+		}
+
+		if instance.state == instanceStateTerminated {
+			return nil, newAPIError("IncorrectInstanceState", "The instance '%s' is not in a state from which it can be started.", instanceID)
+		}
+	}
+
+	for _, instanceID := range instanceIDs {
+		instance := s.ec2.instances[instanceID]
+		previousState := s.instanceToXML(instance).InstanceState
+		currentState := xmlInstanceState{instanceStateCodePending, instanceStatePending}
+
+		if instance.state == instanceStateRunning {
+			currentState = previousState
+		}
+
+		instance.state = instanceStateRunning
+		instance.hibernated = false
+
+		if spotRequest := s.instanceSpotRequest(instance.id); spotRequest != nil &&
+			spotRequest.state == spotInstanceRequestStateDisabled {
+
+			spotRequest.setStatus(spotInstanceRequestStateActive, "fulfilled", "Your spot request is fulfilled.")
+		}
+
+		subnet := s.ec2.subnets[instance.subnetID]
+
+		if len(instance.publicIPAddress) == 0 && subnet != nil && subnet.mapPublicIPOnLaunch {
+			instance.publicIPAddress = s.ec2.newPublicIPAddress("203.0.113")
+		}
+
+		stateChanges = append(stateChanges, xmlInstanceStateChange{
+			InstanceID:    instanceID,
+			CurrentState:  currentState,
+			PreviousState: previousState,
+		})
+	}
+
+	return startInstancesResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Instances: stateChanges,
+	}, nil
+}
+
+// modifyInstanceAttribute only supports the instance type
+// attribute which, like the real API, could only be modified
+// when the instance is stopped.
+func (s *Server) modifyInstanceAttribute(params ec2Params) (interface{}, *apiError) {
+	instanceID := params.get("InstanceId")
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok || instance.state == instanceStateTerminated {
+		return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+	}
+
+	if !params.has("InstanceType.Value") {
+		return nil, newAPIError("InvalidParameterCombination", "The fake backend only supports modifying the instance type")
+	}
+
+	if instance.state != instanceStateStopped {
+		return nil, newAPIError("IncorrectInstanceState", "The instance '%s' is not in the 'stopped' state.", instanceID)
+	}
+
+	if instance.hibernationConfigured {
+		return nil, newAPIError("UnsupportedOperation", "The instance type of the instance '%s' cannot be modified because hibernation is enabled.", instanceID)
+	}
+
+	if len(instance.spotInstanceRequestID) > 0 {
+		return nil, newAPIError("UnsupportedOperation", "The instance type of the spot instance '%s' cannot be modified.", instanceID)
+	}
+
+	instanceTypeName := params.get("InstanceType.Value")
+	instanceType, ok := instanceTypes[instanceTypeName]
+
+	if !ok {
+		return nil, newAPIError("InvalidParameterValue", "Invalid value '%s' for InstanceType.", instanceTypeName)
+	}
+
+	image := images[instance.imageID]
+
+	if !matchesFilterValues(instanceType.architectures, image.architecture) {
+		return nil, newAPIError(
+			"InvalidParameterValue",
+			"The architecture '%s' of the specified instance type does not match the architecture '%s' of the instance AMI.",
+			strings.Join(instanceType.architectures, ", "),
+			image.architecture,
+		)
+	}
+
+	instance.instanceType = instanceTypeName
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+// terminateInstance detaches the network interface and the
+// additional volumes of the passed instance and deletes its root volume.
+//
+// Like the real API, elastic IP associations are kept and
+// must be explicitly removed via DisassociateAddress.
+func (s *Server) terminateInstance(i *instance) {
+	i.state = instanceStateTerminated
+	i.publicIPAddress = ""
+
+	if networkInterface, ok := s.ec2.networkInterfaces[i.networkInterfaceID]; ok {
+		networkInterface.instanceID = ""
+	}
+
+	for _, instanceVolume := range i.volumes {
+		if instanceVolume.deviceName == i.rootDeviceName {
+			delete(s.ec2.volumes, instanceVolume.volumeID)
+			continue
+		}
+
+		if volume, ok := s.ec2.volumes[instanceVolume.volumeID]; ok {
+			volume.instanceID = ""
+			volume.deviceName = ""
+		}
+	}
+
+	i.volumes = nil
+
+	// Like the real API, persistent spot requests that are
+	// not cancelled go back to open and would launch a new
+	// instance (the fake backend never does)
+	if spotRequest, ok := s.ec2.spotInstanceRequests[i.spotInstanceRequestID]; ok &&
+		(spotRequest.state == spotInstanceRequestStateActive ||
+			spotRequest.state == spotInstanceRequestStateDisabled) {
+
+		if spotRequest.requestType == "persistent" {
+			spotRequest.setStatus(spotInstanceRequestStateOpen, "instance-terminated-by-user", "Spot instance terminated by user.")
+		} else {
+			spotRequest.setStatus(spotInstanceRequestStateClosed, "instance-terminated-by-user", "Spot instance terminated by user.")
+		}
+	}
+}
+
+type getConsoleOutputResponse struct {
+	XMLName    xml.Name `xml:"GetConsoleOutputResponse"`
+	Namespace  string   `xml:"xmlns,attr"`
+	RequestID  string   `xml:"requestId"`
+	InstanceID string   `xml:"instanceId"`
+	Timestamp  string   `xml:"timestamp"`
+	Output     string   `xml:"output"`
+}
+
+// getConsoleOutput returns the SSH host keys printed by
+// cloud-init on first boot. Like the buffered output of the
+// Nitro instances, the keys are only returned when the latest
+// output is requested.
+func (s *Server) getConsoleOutput(params ec2Params) (interface{}, *apiError) {
+	instanceID := params.get("InstanceId")
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok {
+		return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+	}
+
+	bootLine := "[    0.000000] Linux version 5.15.0-1019-aws (buildd@lcy02-amd64-001)"
+
+	if !params.bool("Latest") {
+		return getConsoleOutputResponse{
+			Namespace:  ec2XMLNamespace,
+			RequestID:  s.newRequestID(),
+			InstanceID: instance.id,
+			Timestamp:  time.Now().UTC().Format(time.RFC3339),
+			Output:     base64.StdEncoding.EncodeToString([]byte(bootLine + "\r\n")),
+		}, nil
+	}
+
+	consoleOutput := strings.Join([]string{
+		bootLine,
+		"ec2: #############################################################",
+		"ec2: -----BEGIN SSH HOST KEY FINGERPRINTS-----",
+		"ec2: 256 " + ssh.FingerprintSHA256(s.hostKeySigner.PublicKey()) + " root@" + instance.id + " (ED25519)",
+		"ec2: -----END SSH HOST KEY FINGERPRINTS-----",
+		"ec2: #############################################################",
+		"-----BEGIN SSH HOST KEY KEYS-----",
+		strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(s.hostKeySigner.PublicKey())), "\n") + " root@" + instance.id,
+		"-----END SSH HOST KEY KEYS-----",
+		"",
+	}, "\r\n")
+
+	return getConsoleOutputResponse{
+		Namespace:  ec2XMLNamespace,
+		RequestID:  s.newRequestID(),
+		InstanceID: instance.id,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Output:     base64.StdEncoding.EncodeToString([]byte(consoleOutput)),
+	}, nil
+}
@@ -0,0 +1,1371 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"net"
+	"sort"
+	"strings"
+)
+
+type xmlIPv6CIDRBlockState struct {
+	State string `xml:"state"`
+}
+
+type xmlIPv6CIDRBlockAssociation struct {
+	AssociationID string                `xml:"associationId"`
+	IPv6CIDRBlock string                `xml:"ipv6CidrBlock"`
+	State         xmlIPv6CIDRBlockState `xml:"ipv6CidrBlockState"`
+}
+
+func toXMLIPv6CIDRBlockAssociations(
+	resourceID string,
+	IPv6CIDRBlock string,
+) []xmlIPv6CIDRBlockAssociation {
+
+	if len(IPv6CIDRBlock) == 0 {
+		return nil
+	}
+
+	return []xmlIPv6CIDRBlockAssociation{{
+		AssociationID: resourceID + "-cidr-assoc",
+		IPv6CIDRBlock: IPv6CIDRBlock,
+		State: xmlIPv6CIDRBlockState{
+			State: "associated",
+		},
+	}}
+}
+
+type xmlVPC struct {
+	VPCID                       string                        `xml:"vpcId"`
+	CIDRBlock                   string                        `xml:"cidrBlock"`
+	IPv6CIDRBlockAssociationSet []xmlIPv6CIDRBlockAssociation `xml:"ipv6CidrBlockAssociationSet>item"`
+	State                       string                        `xml:"state"`
+	Tags                        []xmlTag                      `xml:"tagSet>item"`
+}
+
+func (v *vpc) toXML() xmlVPC {
+	return xmlVPC{
+		VPCID:                       v.id,
+		CIDRBlock:                   v.cidrBlock,
+		IPv6CIDRBlockAssociationSet: toXMLIPv6CIDRBlockAssociations(v.id, v.IPv6CIDRBlock),
+		State:                       "available",
+		Tags:                        toXMLTags(v.tags),
+	}
+}
+
+type createVPCResponse struct {
+	XMLName   xml.Name `xml:"CreateVpcResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	VPC       xmlVPC   `xml:"vpc"`
+}
+
+func (s *Server) createVPC(params ec2Params) (interface{}, *apiError) {
+	CIDRBlock := params.get("CidrBlock")
+
+	if _, _, err := net.ParseCIDR(CIDRBlock); err != nil {
+		return nil, newAPIError("InvalidParameterValue", "Value (%s) for parameter cidrBlock is invalid", CIDRBlock)
+	}
+
+	createdVPC := &vpc{
+		id:        s.newID("vpc"),
+		cidrBlock: CIDRBlock,
+		tags:      params.tags("vpc"),
+	}
+
+	if params.bool("AmazonProvidedIpv6CidrBlock") {
+		createdVPC.IPv6CIDRBlock = s.ec2.newIPv6CIDRBlock()
+	}
+
+	s.ec2.vpcs[createdVPC.id] = createdVPC
+
+	return createVPCResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		VPC:       createdVPC.toXML(),
+	}, nil
+}
+
+type describeVPCsResponse struct {
+	XMLName   xml.Name `xml:"DescribeVpcsResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	VPCs      []xmlVPC `xml:"vpcSet>item"`
+}
+
+func (s *Server) describeVPCs(params ec2Params) (interface{}, *apiError) {
+	VPCs := []xmlVPC{}
+
+	for _, VPCID := range params.list("VpcId") {
+		VPC, ok := s.ec2.vpcs[VPCID]
+
+		if !ok {
+			return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+		}
+
+		VPCs = append(VPCs, VPC.toXML())
+	}
+
+	return describeVPCsResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		VPCs:      VPCs,
+	}, nil
+}
+
+func (s *Server) modifyVPCAttribute(params ec2Params) (interface{}, *apiError) {
+	VPCID := params.get("VpcId")
+	VPC, ok := s.ec2.vpcs[VPCID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+	}
+
+	if params.has("EnableDnsSupport.Value") && params.has("EnableDnsHostnames.Value") {
+		return nil, newAPIError("InvalidParameterCombination", "Only one attribute can be modified at a time")
+	}
+
+	if params.has("EnableDnsSupport.Value") {
+		VPC.enableDNSSupport = params.bool("EnableDnsSupport.Value")
+	}
+
+	if params.has("EnableDnsHostnames.Value") {
+		VPC.enableDNSHostnames = params.bool("EnableDnsHostnames.Value")
+	}
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+func (s *Server) deleteVPC(params ec2Params) (interface{}, *apiError) {
+	VPCID := params.get("VpcId")
+
+	if _, ok := s.ec2.vpcs[VPCID]; !ok {
+		return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+	}
+
+	hasDependencies := false
+
+	for _, subnet := range s.ec2.subnets {
+		hasDependencies = hasDependencies || subnet.vpcID == VPCID
+	}
+
+	for _, internetGateway := range s.ec2.internetGateways {
+		hasDependencies = hasDependencies || internetGateway.vpcID == VPCID
+	}
+
+	for _, routeTable := range s.ec2.routeTables {
+		hasDependencies = hasDependencies || routeTable.vpcID == VPCID
+	}
+
+	for _, securityGroup := range s.ec2.securityGroups {
+		hasDependencies = hasDependencies || securityGroup.vpcID == VPCID
+	}
+
+	if hasDependencies {
+		return nil, newAPIError("DependencyViolation", "The vpc '%s' has dependencies and cannot be deleted.", VPCID)
+	}
+
+	delete(s.ec2.vpcs, VPCID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlSubnet struct {
+	SubnetID                    string                        `xml:"subnetId"`
+	VPCID                       string                        `xml:"vpcId"`
+	CIDRBlock                   string                        `xml:"cidrBlock"`
+	IPv6CIDRBlockAssociationSet []xmlIPv6CIDRBlockAssociation `xml:"ipv6CidrBlockAssociationSet>item"`
+	AvailabilityZone            string                        `xml:"availabilityZone"`
+	MapPublicIPOnLaunch         bool                          `xml:"mapPublicIpOnLaunch"`
+	AssignIPv6AddressOnCreation bool                          `xml:"assignIpv6AddressOnCreation"`
+	State                       string                        `xml:"state"`
+	Tags                        []xmlTag                      `xml:"tagSet>item"`
+}
+
+func (s *subnet) toXML() xmlSubnet {
+	return xmlSubnet{
+		SubnetID:                    s.id,
+		VPCID:                       s.vpcID,
+		CIDRBlock:                   s.cidrBlock,
+		IPv6CIDRBlockAssociationSet: toXMLIPv6CIDRBlockAssociations(s.id, s.IPv6CIDRBlock),
+		AvailabilityZone:            s.availabilityZone,
+		MapPublicIPOnLaunch:         s.mapPublicIPOnLaunch,
+		AssignIPv6AddressOnCreation: s.assignIPv6AddressOnCreation,
+		State:                       "available",
+		Tags:                        toXMLTags(s.tags),
+	}
+}
+
+type createSubnetResponse struct {
+	XMLName   xml.Name  `xml:"CreateSubnetResponse"`
+	Namespace string    `xml:"xmlns,attr"`
+	RequestID string    `xml:"requestId"`
+	Subnet    xmlSubnet `xml:"subnet"`
+}
+
+func (s *Server) createSubnet(params ec2Params) (interface{}, *apiError) {
+	VPCID := params.get("VpcId")
+	VPC, ok := s.ec2.vpcs[VPCID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+	}
+
+	CIDRBlock := params.get("CidrBlock")
+	_, subnetNetwork, err := net.ParseCIDR(CIDRBlock)
+
+	if err != nil {
+		return nil, newAPIError("InvalidParameterValue", "Value (%s) for parameter cidrBlock is invalid", CIDRBlock)
+	}
+
+	_, VPCNetwork, _ := net.ParseCIDR(VPC.cidrBlock)
+
+	if !VPCNetwork.Contains(subnetNetwork.IP) {
+		return nil, newAPIError("InvalidSubnet.Range", "The CIDR '%s' is invalid.", CIDRBlock)
+	}
+
+	for _, existingSubnet := range s.ec2.subnets {
+		_, existingNetwork, _ := net.ParseCIDR(existingSubnet.cidrBlock)
+
+		if existingSubnet.vpcID == VPCID &&
+			(existingNetwork.Contains(subnetNetwork.IP) || subnetNetwork.Contains(existingNetwork.IP)) {
+
+			return nil, newAPIError("InvalidSubnet.Conflict", "The CIDR '%s' conflicts with another subnet", CIDRBlock)
+		}
+	}
+
+	IPv6CIDRBlock := params.get("Ipv6CidrBlock")
+
+	if len(IPv6CIDRBlock) > 0 {
+		_, subnetIPv6Network, err := net.ParseCIDR(IPv6CIDRBlock)
+
+		if err != nil || len(VPC.IPv6CIDRBlock) == 0 {
+			return nil, newAPIError("InvalidParameterValue", "Value (%s) for parameter ipv6CidrBlock is invalid", IPv6CIDRBlock)
+		}
+
+		_, VPCIPv6Network, _ := net.ParseCIDR(VPC.IPv6CIDRBlock)
+		subnetIPv6MaskSize, _ := subnetIPv6Network.Mask.Size()
+
+		if !VPCIPv6Network.Contains(subnetIPv6Network.IP) || subnetIPv6MaskSize != 64 {
+			return nil, newAPIError("InvalidSubnet.Range", "The CIDR '%s' is invalid.", IPv6CIDRBlock)
+		}
+	}
+
+	availabilityZone := params.get("AvailabilityZone")
+
+	if len(availabilityZone) == 0 {
+		availabilityZone = AvailabilityZone
+	}
+
+	if !isAvailabilityZone(availabilityZone) {
+		return nil, newAPIError("InvalidParameterValue", "Value (%s) for parameter availabilityZone is invalid. Subnets can currently only be created in the following availability zones: %s.", availabilityZone, strings.Join(availabilityZones, ", "))
+	}
+
+	createdSubnet := &subnet{
+		id:               s.newID("subnet"),
+		vpcID:            VPCID,
+		cidrBlock:        CIDRBlock,
+		IPv6CIDRBlock:    IPv6CIDRBlock,
+		availabilityZone: availabilityZone,
+		tags:             params.tags("subnet"),
+	}
+
+	s.ec2.subnets[createdSubnet.id] = createdSubnet
+
+	return createSubnetResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Subnet:    createdSubnet.toXML(),
+	}, nil
+}
+
+type describeSubnetsResponse struct {
+	XMLName   xml.Name    `xml:"DescribeSubnetsResponse"`
+	Namespace string      `xml:"xmlns,attr"`
+	RequestID string      `xml:"requestId"`
+	Subnets   []xmlSubnet `xml:"subnetSet>item"`
+}
+
+func (s *Server) describeSubnets(params ec2Params) (interface{}, *apiError) {
+	subnets := []xmlSubnet{}
+
+	for _, subnetID := range params.list("SubnetId") {
+		subnet, ok := s.ec2.subnets[subnetID]
+
+		if !ok {
+			return nil, newAPIError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetID)
+		}
+
+		subnets = append(subnets, subnet.toXML())
+	}
+
+	return describeSubnetsResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Subnets:   subnets,
+	}, nil
+}
+
+func (s *Server) modifySubnetAttribute(params ec2Params) (interface{}, *apiError) {
+	subnetID := params.get("SubnetId")
+	subnet, ok := s.ec2.subnets[subnetID]
+
+	if !ok {
+		return nil, newAPIError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetID)
+	}
+
+	if params.has("MapPublicIpOnLaunch.Value") {
+		subnet.mapPublicIPOnLaunch = params.bool("MapPublicIpOnLaunch.Value")
+	}
+
+	if params.has("AssignIpv6AddressOnCreation.Value") {
+		if len(subnet.IPv6CIDRBlock) == 0 {
+			return nil, newAPIError("InvalidParameterValue", "The subnet '%s' has no IPv6 CIDR block", subnetID)
+		}
+
+		subnet.assignIPv6AddressOnCreation = params.bool("AssignIpv6AddressOnCreation.Value")
+	}
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+func (s *Server) deleteSubnet(params ec2Params) (interface{}, *apiError) {
+	subnetID := params.get("SubnetId")
+	subnet, ok := s.ec2.subnets[subnetID]
+
+	if !ok {
+		return nil, newAPIError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetID)
+	}
+
+	for _, networkInterface := range s.ec2.networkInterfaces {
+		if networkInterface.subnetID == subnetID {
+			return nil, newAPIError("DependencyViolation", "The subnet '%s' has dependencies and cannot be deleted.", subnetID)
+		}
+	}
+
+	if s.ec2.hasActiveNATGateway(subnet.vpcID, subnetID) || s.ec2.hasVPCEndpointInSubnet(subnetID) {
+		return nil, newAPIError("DependencyViolation", "The subnet '%s' has dependencies and cannot be deleted.", subnetID)
+	}
+
+	for _, routeTable := range s.ec2.routeTables {
+		for associationID, associatedSubnetID := range routeTable.associations {
+			if associatedSubnetID == subnetID {
+				delete(routeTable.associations, associationID)
+			}
+		}
+	}
+
+	delete(s.ec2.subnets, subnetID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlInternetGatewayAttachment struct {
+	VPCID string `xml:"vpcId"`
+	State string `xml:"state"`
+}
+
+type xmlInternetGateway struct {
+	InternetGatewayID string                         `xml:"internetGatewayId"`
+	Attachments       []xmlInternetGatewayAttachment `xml:"attachmentSet>item"`
+	Tags              []xmlTag                       `xml:"tagSet>item"`
+}
+
+func (i *internetGateway) toXML() xmlInternetGateway {
+	attachments := []xmlInternetGatewayAttachment{}
+
+	if len(i.vpcID) > 0 {
+		attachments = append(attachments, xmlInternetGatewayAttachment{
+			VPCID: i.vpcID,
+			State: "available",
+		})
+	}
+
+	return xmlInternetGateway{
+		InternetGatewayID: i.id,
+		Attachments:       attachments,
+		Tags:              toXMLTags(i.tags),
+	}
+}
+
+type createInternetGatewayResponse struct {
+	XMLName         xml.Name           `xml:"CreateInternetGatewayResponse"`
+	Namespace       string             `xml:"xmlns,attr"`
+	RequestID       string             `xml:"requestId"`
+	InternetGateway xmlInternetGateway `xml:"internetGateway"`
+}
+
+func (s *Server) createInternetGateway(params ec2Params) (interface{}, *apiError) {
+	createdInternetGateway := &internetGateway{
+		id:   s.newID("igw"),
+		tags: params.tags("internet-gateway"),
+	}
+
+	s.ec2.internetGateways[createdInternetGateway.id] = createdInternetGateway
+
+	return createInternetGatewayResponse{
+		Namespace:       ec2XMLNamespace,
+		RequestID:       s.newRequestID(),
+		InternetGateway: createdInternetGateway.toXML(),
+	}, nil
+}
+
+type describeInternetGatewaysResponse struct {
+	XMLName          xml.Name             `xml:"DescribeInternetGatewaysResponse"`
+	Namespace        string               `xml:"xmlns,attr"`
+	RequestID        string               `xml:"requestId"`
+	InternetGateways []xmlInternetGateway `xml:"internetGatewaySet>item"`
+}
+
+func (s *Server) describeInternetGateways(params ec2Params) (interface{}, *apiError) {
+	internetGateways := []xmlInternetGateway{}
+
+	for _, internetGatewayID := range params.list("InternetGatewayId") {
+		internetGateway, ok := s.ec2.internetGateways[internetGatewayID]
+
+		if !ok {
+			return nil, newAPIError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", internetGatewayID)
+		}
+
+		internetGateways = append(internetGateways, internetGateway.toXML())
+	}
+
+	return describeInternetGatewaysResponse{
+		Namespace:        ec2XMLNamespace,
+		RequestID:        s.newRequestID(),
+		InternetGateways: internetGateways,
+	}, nil
+}
+
+func (s *Server) attachInternetGateway(params ec2Params) (interface{}, *apiError) {
+	internetGatewayID := params.get("InternetGatewayId")
+	internetGateway, ok := s.ec2.internetGateways[internetGatewayID]
+
+	if !ok {
+		return nil, newAPIError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", internetGatewayID)
+	}
+
+	VPCID := params.get("VpcId")
+
+	if _, ok := s.ec2.vpcs[VPCID]; !ok {
+		return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+	}
+
+	if len(internetGateway.vpcID) > 0 {
+		return nil, newAPIError("Resource.AlreadyAssociated", "resource %s is already attached to network %s", internetGatewayID, internetGateway.vpcID)
+	}
+
+	internetGateway.vpcID = VPCID
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+func (s *Server) detachInternetGateway(params ec2Params) (interface{}, *apiError) {
+	internetGatewayID := params.get("InternetGatewayId")
+	internetGateway, ok := s.ec2.internetGateways[internetGatewayID]
+
+	if !ok {
+		return nil, newAPIError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", internetGatewayID)
+	}
+
+	VPCID := params.get("VpcId")
+
+	if internetGateway.vpcID != VPCID {
+		return nil, newAPIError("Gateway.NotAttached", "resource %s is not attached to network %s", internetGatewayID, VPCID)
+	}
+
+	if s.ec2.hasActiveNATGateway(VPCID, "") {
+		return nil, newAPIError("DependencyViolation", "Network %s has some mapped public address(es). Please unmap those public address(es) before detaching the gateway.", VPCID)
+	}
+
+	internetGateway.vpcID = ""
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+func (s *Server) deleteInternetGateway(params ec2Params) (interface{}, *apiError) {
+	internetGatewayID := params.get("InternetGatewayId")
+	internetGateway, ok := s.ec2.internetGateways[internetGatewayID]
+
+	if !ok {
+		return nil, newAPIError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", internetGatewayID)
+	}
+
+	if len(internetGateway.vpcID) > 0 {
+		return nil, newAPIError("DependencyViolation", "The internetGateway '%s' has dependencies and cannot be deleted.", internetGatewayID)
+	}
+
+	delete(s.ec2.internetGateways, internetGatewayID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlRoute struct {
+	DestinationCIDRBlock     string `xml:"destinationCidrBlock,omitempty"`
+	DestinationIPv6CIDRBlock string `xml:"destinationIpv6CidrBlock,omitempty"`
+	GatewayID                string `xml:"gatewayId,omitempty"`
+	NATGatewayID             string `xml:"natGatewayId,omitempty"`
+	State                    string `xml:"state"`
+}
+
+type xmlRouteTableAssociation struct {
+	AssociationID string `xml:"routeTableAssociationId"`
+	RouteTableID  string `xml:"routeTableId"`
+	SubnetID      string `xml:"subnetId"`
+	Main          bool   `xml:"main"`
+}
+
+type xmlRouteTable struct {
+	RouteTableID string                     `xml:"routeTableId"`
+	VPCID        string                     `xml:"vpcId"`
+	Routes       []xmlRoute                 `xml:"routeSet>item"`
+	Associations []xmlRouteTableAssociation `xml:"associationSet>item"`
+	Tags         []xmlTag                   `xml:"tagSet>item"`
+}
+
+func (r *routeTable) toXML() xmlRouteTable {
+	associations := []xmlRouteTableAssociation{}
+
+	for associationID, subnetID := range r.associations {
+		associations = append(associations, xmlRouteTableAssociation{
+			AssociationID: associationID,
+			RouteTableID:  r.id,
+			SubnetID:      subnetID,
+		})
+	}
+
+	routes := []xmlRoute{}
+
+	for _, route := range r.routes {
+		routes = append(routes, xmlRoute{
+			DestinationCIDRBlock:     route.destinationCIDRBlock,
+			DestinationIPv6CIDRBlock: route.destinationIPv6CIDRBlock,
+			GatewayID:                route.gatewayID,
+			NATGatewayID:             route.natGatewayID,
+			State:                    "active",
+		})
+	}
+
+	return xmlRouteTable{
+		RouteTableID: r.id,
+		VPCID:        r.vpcID,
+		Routes:       routes,
+		Associations: associations,
+		Tags:         toXMLTags(r.tags),
+	}
+}
+
+type createRouteTableResponse struct {
+	XMLName    xml.Name      `xml:"CreateRouteTableResponse"`
+	Namespace  string        `xml:"xmlns,attr"`
+	RequestID  string        `xml:"requestId"`
+	RouteTable xmlRouteTable `xml:"routeTable"`
+}
+
+func (s *Server) createRouteTable(params ec2Params) (interface{}, *apiError) {
+	VPCID := params.get("VpcId")
+
+	if _, ok := s.ec2.vpcs[VPCID]; !ok {
+		return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+	}
+
+	createdRouteTable := &routeTable{
+		id:           s.newID("rtb"),
+		vpcID:        VPCID,
+		associations: map[string]string{},
+		tags:         params.tags("route-table"),
+	}
+
+	s.ec2.routeTables[createdRouteTable.id] = createdRouteTable
+
+	return createRouteTableResponse{
+		Namespace:  ec2XMLNamespace,
+		RequestID:  s.newRequestID(),
+		RouteTable: createdRouteTable.toXML(),
+	}, nil
+}
+
+type describeRouteTablesResponse struct {
+	XMLName     xml.Name        `xml:"DescribeRouteTablesResponse"`
+	Namespace   string          `xml:"xmlns,attr"`
+	RequestID   string          `xml:"requestId"`
+	RouteTables []xmlRouteTable `xml:"routeTableSet>item"`
+}
+
+// matchesFilters returns true if the route table matches
+// the "vpc-id", "association.subnet-id" and "association.main" filters.
+// Route tables created by the server are never main route tables.
+func (r *routeTable) matchesFilters(filters map[string][]string) bool {
+	if values, ok := filters["vpc-id"]; ok && !matchesFilterValues(values, r.vpcID) {
+		return false
+	}
+
+	if values, ok := filters["association.main"]; ok && !matchesFilterValues(values, "false") {
+		return false
+	}
+
+	if values, ok := filters["association.subnet-id"]; ok {
+		associatedSubnetIDs := []string{}
+
+		for _, subnetID := range r.associations {
+			associatedSubnetIDs = append(associatedSubnetIDs, subnetID)
+		}
+
+		return matchesFilterValues(values, associatedSubnetIDs...)
+	}
+
+	return true
+}
+
+func (s *Server) describeRouteTables(params ec2Params) (interface{}, *apiError) {
+	routeTableIDs := params.list("RouteTableId")
+
+	if len(routeTableIDs) == 0 {
+		for routeTableID := range s.ec2.routeTables {
+			routeTableIDs = append(routeTableIDs, routeTableID)
+		}
+
+		sort.Strings(routeTableIDs)
+	}
+
+	filters := params.filters()
+	routeTables := []xmlRouteTable{}
+
+	for _, routeTableID := range routeTableIDs {
+		routeTable, ok := s.ec2.routeTables[routeTableID]
+
+		if !ok {
+			return nil, newAPIError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableID)
+		}
+
+		if !routeTable.matchesFilters(filters) {
+			continue
+		}
+
+		routeTables = append(routeTables, routeTable.toXML())
+	}
+
+	return describeRouteTablesResponse{
+		Namespace:   ec2XMLNamespace,
+		RequestID:   s.newRequestID(),
+		RouteTables: routeTables,
+	}, nil
+}
+
+func (s *Server) createRoute(params ec2Params) (interface{}, *apiError) {
+	routeTableID := params.get("RouteTableId")
+	routeTable, ok := s.ec2.routeTables[routeTableID]
+
+	if !ok {
+		return nil, newAPIError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableID)
+	}
+
+	gatewayID := params.get("GatewayId")
+	NATGatewayID := params.get("NatGatewayId")
+
+	if (len(gatewayID) == 0) == (len(NATGatewayID) == 0) {
+		return nil, newAPIError("InvalidParameterCombination", "Exactly one of gatewayId and natGatewayId must be specified")
+	}
+
+	if len(NATGatewayID) > 0 {
+		NATGateway, ok := s.ec2.natGateways[NATGatewayID]
+
+		if !ok || NATGateway.state == natGatewayStateDeleted {
+			return nil, newAPIError("InvalidNatGatewayID.NotFound", "The natGateway ID '%s' does not exist", NATGatewayID)
+		}
+
+		if NATGateway.vpcID != routeTable.vpcID {
+			return nil, newAPIError("InvalidParameterValue", "route table %s and network gateway %s belong to different networks", routeTableID, NATGatewayID)
+		}
+	} else {
+		internetGateway, ok := s.ec2.internetGateways[gatewayID]
+
+		if !ok {
+			return nil, newAPIError("InvalidInternetGatewayID.NotFound", "The internetGateway ID '%s' does not exist", gatewayID)
+		}
+
+		if internetGateway.vpcID != routeTable.vpcID {
+			return nil, newAPIError("InvalidParameterValue", "route table %s and network gateway %s belong to different networks", routeTableID, gatewayID)
+		}
+	}
+
+	destinationCIDRBlock := params.get("DestinationCidrBlock")
+	destinationIPv6CIDRBlock := params.get("DestinationIpv6CidrBlock")
+
+	if (len(destinationCIDRBlock) == 0) == (len(destinationIPv6CIDRBlock) == 0) {
+		return nil, newAPIError("InvalidParameterCombination", "Exactly one of destinationCidrBlock and destinationIpv6CidrBlock must be specified")
+	}
+
+	for _, existingRoute := range routeTable.routes {
+		if existingRoute.destinationCIDRBlock == destinationCIDRBlock &&
+			existingRoute.destinationIPv6CIDRBlock == destinationIPv6CIDRBlock {
+
+			return nil, newAPIError("RouteAlreadyExists", "The route identified by %s%s already exists.", destinationCIDRBlock, destinationIPv6CIDRBlock)
+		}
+	}
+
+	routeTable.routes = append(routeTable.routes, route{
+		destinationCIDRBlock:     destinationCIDRBlock,
+		destinationIPv6CIDRBlock: destinationIPv6CIDRBlock,
+		gatewayID:                gatewayID,
+		natGatewayID:             NATGatewayID,
+	})
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type associateRouteTableResponse struct {
+	XMLName       xml.Name `xml:"AssociateRouteTableResponse"`
+	Namespace     string   `xml:"xmlns,attr"`
+	RequestID     string   `xml:"requestId"`
+	AssociationID string   `xml:"associationId"`
+}
+
+func (s *Server) associateRouteTable(params ec2Params) (interface{}, *apiError) {
+	routeTableID := params.get("RouteTableId")
+	routeTable, ok := s.ec2.routeTables[routeTableID]
+
+	if !ok {
+		return nil, newAPIError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableID)
+	}
+
+	subnetID := params.get("SubnetId")
+	subnet, ok := s.ec2.subnets[subnetID]
+
+	if !ok {
+		return nil, newAPIError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetID)
+	}
+
+	if subnet.vpcID != routeTable.vpcID {
+		return nil, newAPIError("InvalidParameterValue", "route table %s and subnet %s belong to different networks", routeTableID, subnetID)
+	}
+
+	associationID := s.newID("rtbassoc")
+	routeTable.associations[associationID] = subnetID
+
+	return associateRouteTableResponse{
+		Namespace:     ec2XMLNamespace,
+		RequestID:     s.newRequestID(),
+		AssociationID: associationID,
+	}, nil
+}
+
+func (s *Server) deleteRouteTable(params ec2Params) (interface{}, *apiError) {
+	routeTableID := params.get("RouteTableId")
+	routeTable, ok := s.ec2.routeTables[routeTableID]
+
+	if !ok {
+		return nil, newAPIError("InvalidRouteTableID.NotFound", "The routeTable ID '%s' does not exist", routeTableID)
+	}
+
+	if len(routeTable.associations) > 0 {
+		return nil, newAPIError("DependencyViolation", "The routeTable '%s' has dependencies and cannot be deleted.", routeTableID)
+	}
+
+	delete(s.ec2.routeTables, routeTableID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlIPRange struct {
+	CIDRIP      string `xml:"cidrIp"`
+	Description string `xml:"description,omitempty"`
+}
+
+type xmlIPv6Range struct {
+	CIDRIPv6    string `xml:"cidrIpv6"`
+	Description string `xml:"description,omitempty"`
+}
+
+type xmlIPPermission struct {
+	IPProtocol string         `xml:"ipProtocol"`
+	FromPort   int32          `xml:"fromPort"`
+	ToPort     int32          `xml:"toPort"`
+	IPRanges   []xmlIPRange   `xml:"ipRanges>item"`
+	IPv6Ranges []xmlIPv6Range `xml:"ipv6Ranges>item"`
+}
+
+type xmlSecurityGroup struct {
+	GroupID          string            `xml:"groupId"`
+	GroupName        string            `xml:"groupName"`
+	GroupDescription string            `xml:"groupDescription"`
+	VPCID            string            `xml:"vpcId"`
+	IPPermissions    []xmlIPPermission `xml:"ipPermissions>item"`
+	Tags             []xmlTag          `xml:"tagSet>item"`
+}
+
+func (g *securityGroup) toXML() xmlSecurityGroup {
+	IPPermissions := []xmlIPPermission{}
+
+	for _, rule := range g.ingress {
+		IPPermission := xmlIPPermission{
+			IPProtocol: rule.protocol,
+			FromPort:   rule.fromPort,
+			ToPort:     rule.toPort,
+		}
+
+		if len(rule.CIDRIPv4) > 0 {
+			IPPermission.IPRanges = []xmlIPRange{{
+				CIDRIP:      rule.CIDRIPv4,
+				Description: rule.description,
+			}}
+		}
+
+		if len(rule.CIDRIPv6) > 0 {
+			IPPermission.IPv6Ranges = []xmlIPv6Range{{
+				CIDRIPv6:    rule.CIDRIPv6,
+				Description: rule.description,
+			}}
+		}
+
+		IPPermissions = append(IPPermissions, IPPermission)
+	}
+
+	return xmlSecurityGroup{
+		GroupID:          g.id,
+		GroupName:        g.name,
+		GroupDescription: g.description,
+		VPCID:            g.vpcID,
+		IPPermissions:    IPPermissions,
+		Tags:             toXMLTags(g.tags),
+	}
+}
+
+type createSecurityGroupResponse struct {
+	XMLName   xml.Name `xml:"CreateSecurityGroupResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	Return    bool     `xml:"return"`
+	GroupID   string   `xml:"groupId"`
+}
+
+func (s *Server) createSecurityGroup(params ec2Params) (interface{}, *apiError) {
+	VPCID := params.get("VpcId")
+
+	if _, ok := s.ec2.vpcs[VPCID]; !ok {
+		return nil, newAPIError("InvalidVpcID.NotFound", "The vpc ID '%s' does not exist", VPCID)
+	}
+
+	groupName := params.get("GroupName")
+
+	for _, existingGroup := range s.ec2.securityGroups {
+		if existingGroup.vpcID == VPCID && existingGroup.name == groupName {
+			return nil, newAPIError("InvalidGroup.Duplicate", "The security group '%s' already exists for VPC '%s'", groupName, VPCID)
+		}
+	}
+
+	createdSecurityGroup := &securityGroup{
+		id:          s.newID("sg"),
+		name:        groupName,
+		description: params.get("GroupDescription"),
+		vpcID:       VPCID,
+		tags:        params.tags("security-group"),
+	}
+
+	s.ec2.securityGroups[createdSecurityGroup.id] = createdSecurityGroup
+
+	return createSecurityGroupResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Return:    true,
+		GroupID:   createdSecurityGroup.id,
+	}, nil
+}
+
+type describeSecurityGroupsResponse struct {
+	XMLName        xml.Name           `xml:"DescribeSecurityGroupsResponse"`
+	Namespace      string             `xml:"xmlns,attr"`
+	RequestID      string             `xml:"requestId"`
+	SecurityGroups []xmlSecurityGroup `xml:"securityGroupInfo>item"`
+}
+
+func (s *Server) describeSecurityGroups(params ec2Params) (interface{}, *apiError) {
+	securityGroups := []xmlSecurityGroup{}
+
+	for _, groupID := range params.list("GroupId") {
+		securityGroup, ok := s.ec2.securityGroups[groupID]
+
+		if !ok {
+			return nil, newAPIError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupID)
+		}
+
+		securityGroups = append(securityGroups, securityGroup.toXML())
+	}
+
+	return describeSecurityGroupsResponse{
+		Namespace:      ec2XMLNamespace,
+		RequestID:      s.newRequestID(),
+		SecurityGroups: securityGroups,
+	}, nil
+}
+
+// securityGroupRules returns the rules passed either via
+// the "IpPermissions" list or via the top-level parameters.
+func (p ec2Params) securityGroupRules() ([]securityGroupRule, *apiError) {
+	rules := []securityGroupRule{}
+
+	if p.has("IpProtocol") {
+		fromPort, err := p.int32("FromPort", -1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		toPort, err := p.int32("ToPort", -1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, securityGroupRule{
+			protocol: p.get("IpProtocol"),
+			fromPort: fromPort,
+			toPort:   toPort,
+			CIDRIPv4: p.get("CidrIp"),
+		})
+	}
+
+	for _, permissionPrefix := range p.indexes("IpPermissions") {
+		fromPort, err := p.int32(permissionPrefix+".FromPort", -1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		toPort, err := p.int32(permissionPrefix+".ToPort", -1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rule := securityGroupRule{
+			protocol: p.get(permissionPrefix + ".IpProtocol"),
+			fromPort: fromPort,
+			toPort:   toPort,
+		}
+
+		for _, rangePrefix := range p.indexes(permissionPrefix + ".IpRanges") {
+			IPv4Rule := rule
+			IPv4Rule.CIDRIPv4 = p.get(rangePrefix + ".CidrIp")
+			IPv4Rule.description = p.get(rangePrefix + ".Description")
+			rules = append(rules, IPv4Rule)
+		}
+
+		for _, rangePrefix := range p.indexes(permissionPrefix + ".Ipv6Ranges") {
+			IPv6Rule := rule
+			IPv6Rule.CIDRIPv6 = p.get(rangePrefix + ".CidrIpv6")
+			IPv6Rule.description = p.get(rangePrefix + ".Description")
+			rules = append(rules, IPv6Rule)
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.protocol != "tcp" && rule.protocol != "udp" &&
+			rule.protocol != "icmp" && rule.protocol != "-1" {
+
+			return nil, newAPIError("InvalidParameterValue", "Invalid value '%s' for IP protocol.", rule.protocol)
+		}
+
+		if rule.protocol != "-1" && rule.protocol != "icmp" &&
+			(rule.fromPort < 0 || rule.toPort > 65535 || rule.fromPort > rule.toPort) {
+
+			return nil, newAPIError("InvalidParameterValue", "Invalid port range %d-%d.", rule.fromPort, rule.toPort)
+		}
+
+		if len(rule.CIDRIPv4) == 0 && len(rule.CIDRIPv6) == 0 {
+			return nil, newAPIError("MissingParameter", "The request must contain the parameter ipRanges or ipv6Ranges")
+		}
+
+		for _, CIDR := range []string{rule.CIDRIPv4, rule.CIDRIPv6} {
+			if len(CIDR) == 0 {
+				continue
+			}
+
+			if _, _, err := net.ParseCIDR(CIDR); err != nil {
+				return nil, newAPIError("InvalidParameterValue", "CIDR block %s is malformed", CIDR)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+type authorizeSecurityGroupIngressResponse struct {
+	XMLName   xml.Name `xml:"AuthorizeSecurityGroupIngressResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	Return    bool     `xml:"return"`
+}
+
+func (s *Server) authorizeSecurityGroupIngress(params ec2Params) (interface{}, *apiError) {
+	groupID := params.get("GroupId")
+	securityGroup, ok := s.ec2.securityGroups[groupID]
+
+	if !ok {
+		return nil, newAPIError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupID)
+	}
+
+	rules, apiErr := params.securityGroupRules()
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	for _, rule := range rules {
+		for _, existingRule := range securityGroup.ingress {
+			if existingRule.sameAs(rule) {
+				return nil, newAPIError(
+					"InvalidPermission.Duplicate",
+					"the specified rule \"peer: %s%s, %s, from port: %d, to port: %d, ALLOW\" already exists",
+					rule.CIDRIPv4,
+					rule.CIDRIPv6,
+					rule.protocol,
+					rule.fromPort,
+					rule.toPort,
+				)
+			}
+		}
+	}
+
+	securityGroup.ingress = append(securityGroup.ingress, rules...)
+
+	return authorizeSecurityGroupIngressResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Return:    true,
+	}, nil
+}
+
+func (s *Server) revokeSecurityGroupIngress(params ec2Params) (interface{}, *apiError) {
+	groupID := params.get("GroupId")
+	securityGroup, ok := s.ec2.securityGroups[groupID]
+
+	if !ok {
+		return nil, newAPIError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupID)
+	}
+
+	rules, apiErr := params.securityGroupRules()
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	remainingRules := securityGroup.ingress
+
+	for _, rule := range rules {
+		found := false
+		filteredRules := []securityGroupRule{}
+
+		for _, existingRule := range remainingRules {
+			if existingRule.sameAs(rule) {
+				found = true
+				continue
+			}
+
+			filteredRules = append(filteredRules, existingRule)
+		}
+
+		if !found {
+			return nil, newAPIError(
+				"InvalidPermission.NotFound",
+				"The specified rule does not exist in this security group.",
+			)
+		}
+
+		remainingRules = filteredRules
+	}
+
+	securityGroup.ingress = remainingRules
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+func (s *Server) deleteSecurityGroup(params ec2Params) (interface{}, *apiError) {
+	groupID := params.get("GroupId")
+
+	if _, ok := s.ec2.securityGroups[groupID]; !ok {
+		return nil, newAPIError("InvalidGroup.NotFound", "The security group '%s' does not exist", groupID)
+	}
+
+	for _, networkInterface := range s.ec2.networkInterfaces {
+		for _, securityGroupID := range networkInterface.securityGroupIDs {
+			if securityGroupID == groupID {
+				return nil, newAPIError("DependencyViolation", "resource %s has a dependent object", groupID)
+			}
+		}
+	}
+
+	if s.ec2.hasVPCEndpointWithSecurityGroup(groupID) {
+		return nil, newAPIError("DependencyViolation", "resource %s has a dependent object", groupID)
+	}
+
+	delete(s.ec2.securityGroups, groupID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlNetworkInterfaceAttachment struct {
+	InstanceID  string `xml:"instanceId"`
+	DeviceIndex int32  `xml:"deviceIndex"`
+	Status      string `xml:"status"`
+}
+
+type xmlNetworkInterfaceIPv6Address struct {
+	IPv6Address string `xml:"ipv6Address"`
+}
+
+type xmlNetworkInterface struct {
+	NetworkInterfaceID string                           `xml:"networkInterfaceId"`
+	SubnetID           string                           `xml:"subnetId"`
+	VPCID              string                           `xml:"vpcId"`
+	AvailabilityZone   string                           `xml:"availabilityZone"`
+	Description        string                           `xml:"description"`
+	PrivateIPAddress   string                           `xml:"privateIpAddress"`
+	IPv6Addresses      []xmlNetworkInterfaceIPv6Address `xml:"ipv6AddressesSet>item"`
+	Status             string                           `xml:"status"`
+	Attachment         *xmlNetworkInterfaceAttachment   `xml:"attachment,omitempty"`
+	Tags               []xmlTag                         `xml:"tagSet>item"`
+}
+
+func (s *Server) networkInterfaceToXML(n *networkInterface) xmlNetworkInterface {
+	subnet := s.ec2.subnets[n.subnetID]
+
+	XMLNetworkInterface := xmlNetworkInterface{
+		NetworkInterfaceID: n.id,
+		SubnetID:           n.subnetID,
+		VPCID:              subnet.vpcID,
+		AvailabilityZone:   subnet.availabilityZone,
+		Description:        n.description,
+		PrivateIPAddress:   n.privateIPAddress,
+		Status:             "available",
+		Tags:               toXMLTags(n.tags),
+	}
+
+	if len(n.IPv6Address) > 0 {
+		XMLNetworkInterface.IPv6Addresses = []xmlNetworkInterfaceIPv6Address{{
+			IPv6Address: n.IPv6Address,
+		}}
+	}
+
+	if len(n.instanceID) > 0 {
+		XMLNetworkInterface.Status = "in-use"
+		XMLNetworkInterface.Attachment = &xmlNetworkInterfaceAttachment{
+			InstanceID:  n.instanceID,
+			DeviceIndex: 0,
+			Status:      "attached",
+		}
+	}
+
+	return XMLNetworkInterface
+}
+
+type createNetworkInterfaceResponse struct {
+	XMLName          xml.Name            `xml:"CreateNetworkInterfaceResponse"`
+	Namespace        string              `xml:"xmlns,attr"`
+	RequestID        string              `xml:"requestId"`
+	NetworkInterface xmlNetworkInterface `xml:"networkInterface"`
+}
+
+func (s *Server) createNetworkInterface(params ec2Params) (interface{}, *apiError) {
+	subnetID := params.get("SubnetId")
+	subnet, ok := s.ec2.subnets[subnetID]
+
+	if !ok {
+		return nil, newAPIError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetID)
+	}
+
+	securityGroupIDs := params.list("SecurityGroupId")
+
+	for _, securityGroupID := range securityGroupIDs {
+		securityGroup, ok := s.ec2.securityGroups[securityGroupID]
+
+		if !ok {
+			return nil, newAPIError("InvalidGroup.NotFound", "The security group '%s' does not exist", securityGroupID)
+		}
+
+		if securityGroup.vpcID != subnet.vpcID {
+			return nil, newAPIError("InvalidParameterValue", "security group %s and subnet %s belong to different networks", securityGroupID, subnetID)
+		}
+	}
+
+	createdNetworkInterface := &networkInterface{
+		id:               s.newID("eni"),
+		subnetID:         subnetID,
+		securityGroupIDs: securityGroupIDs,
+		description:      params.get("Description"),
+		privateIPAddress: subnet.newPrivateIPAddress(),
+		tags:             params.tags("network-interface"),
+	}
+
+	IPv6AddressCount, apiErr := params.int32("Ipv6AddressCount", 0)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if IPv6AddressCount > 0 || subnet.assignIPv6AddressOnCreation {
+		if len(subnet.IPv6CIDRBlock) == 0 {
+			return nil, newAPIError("InvalidParameterValue", "The subnet '%s' has no IPv6 CIDR block", subnetID)
+		}
+
+		createdNetworkInterface.IPv6Address = subnet.newIPv6Address()
+	}
+
+	s.ec2.networkInterfaces[createdNetworkInterface.id] = createdNetworkInterface
+
+	return createNetworkInterfaceResponse{
+		Namespace:        ec2XMLNamespace,
+		RequestID:        s.newRequestID(),
+		NetworkInterface: s.networkInterfaceToXML(createdNetworkInterface),
+	}, nil
+}
+
+type describeNetworkInterfacesResponse struct {
+	XMLName           xml.Name              `xml:"DescribeNetworkInterfacesResponse"`
+	Namespace         string                `xml:"xmlns,attr"`
+	RequestID         string                `xml:"requestId"`
+	NetworkInterfaces []xmlNetworkInterface `xml:"networkInterfaceSet>item"`
+}
+
+func (s *Server) describeNetworkInterfaces(params ec2Params) (interface{}, *apiError) {
+	networkInterfaces := []xmlNetworkInterface{}
+
+	for _, networkInterfaceID := range params.list("NetworkInterfaceId") {
+		networkInterface, ok := s.ec2.networkInterfaces[networkInterfaceID]
+
+		if !ok {
+			return nil, newAPIError("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", networkInterfaceID)
+		}
+
+		networkInterfaces = append(networkInterfaces, s.networkInterfaceToXML(networkInterface))
+	}
+
+	return describeNetworkInterfacesResponse{
+		Namespace:         ec2XMLNamespace,
+		RequestID:         s.newRequestID(),
+		NetworkInterfaces: networkInterfaces,
+	}, nil
+}
+
+func (s *Server) deleteNetworkInterface(params ec2Params) (interface{}, *apiError) {
+	networkInterfaceID := params.get("NetworkInterfaceId")
+	networkInterface, ok := s.ec2.networkInterfaces[networkInterfaceID]
+
+	if !ok {
+		return nil, newAPIError("InvalidNetworkInterfaceID.NotFound", "The networkInterface ID '%s' does not exist", networkInterfaceID)
+	}
+
+	if len(networkInterface.instanceID) > 0 {
+		return nil, newAPIError("InvalidNetworkInterface.InUse", "Interface: [%s] in use.", networkInterfaceID)
+	}
+
+	delete(s.ec2.networkInterfaces, networkInterfaceID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type allocateAddressResponse struct {
+	XMLName      xml.Name `xml:"AllocateAddressResponse"`
+	Namespace    string   `xml:"xmlns,attr"`
+	RequestID    string   `xml:"requestId"`
+	PublicIP     string   `xml:"publicIp"`
+	Domain       string   `xml:"domain"`
+	AllocationID string   `xml:"allocationId"`
+}
+
+func (s *Server) allocateAddress(params ec2Params) (interface{}, *apiError) {
+	if domain := params.get("Domain"); domain != "vpc" {
+		return nil, newAPIError("InvalidParameterValue", "Invalid value '%s' for domain.", domain)
+	}
+
+	createdElasticIP := &elasticIP{
+		allocationID: s.newID("eipalloc"),
+		publicIP:     s.ec2.newPublicIPAddress("198.51.100"),
+		tags:         params.tags("elastic-ip"),
+	}
+
+	s.ec2.elasticIPs[createdElasticIP.allocationID] = createdElasticIP
+
+	return allocateAddressResponse{
+		Namespace:    ec2XMLNamespace,
+		RequestID:    s.newRequestID(),
+		PublicIP:     createdElasticIP.publicIP,
+		Domain:       "vpc",
+		AllocationID: createdElasticIP.allocationID,
+	}, nil
+}
+
+type associateAddressResponse struct {
+	XMLName       xml.Name `xml:"AssociateAddressResponse"`
+	Namespace     string   `xml:"xmlns,attr"`
+	RequestID     string   `xml:"requestId"`
+	Return        bool     `xml:"return"`
+	AssociationID string   `xml:"associationId"`
+}
+
+func (s *Server) associateAddress(params ec2Params) (interface{}, *apiError) {
+	allocationID := params.get("AllocationId")
+	elasticIP, ok := s.ec2.elasticIPs[allocationID]
+
+	if !ok {
+		return nil, newAPIError("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", allocationID)
+	}
+
+	instanceID := params.get("InstanceId")
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok || instance.state != instanceStateRunning {
+		return nil, newAPIError("InvalidInstanceID", "The instance ID '%s' is not in a valid state for this operation.", instanceID)
+	}
+
+	elasticIP.associationID = s.newID("eipassoc")
+	elasticIP.instanceID = instanceID
+	instance.publicIPAddress = elasticIP.publicIP
+
+	return associateAddressResponse{
+		Namespace:     ec2XMLNamespace,
+		RequestID:     s.newRequestID(),
+		Return:        true,
+		AssociationID: elasticIP.associationID,
+	}, nil
+}
+
+func (s *Server) disassociateAddress(params ec2Params) (interface{}, *apiError) {
+	associationID := params.get("AssociationId")
+
+	for _, elasticIP := range s.ec2.elasticIPs {
+		if elasticIP.associationID != associationID {
+			continue
+		}
+
+		if instance, ok := s.ec2.instances[elasticIP.instanceID]; ok &&
+			instance.publicIPAddress == elasticIP.publicIP {
+
+			instance.publicIPAddress = ""
+		}
+
+		elasticIP.associationID = ""
+		elasticIP.instanceID = ""
+
+		return s.newEC2BooleanResponse(), nil
+	}
+
+	return nil, newAPIError("InvalidAssociationID.NotFound", "The association ID '%s' does not exist", associationID)
+}
+
+func (s *Server) releaseAddress(params ec2Params) (interface{}, *apiError) {
+	allocationID := params.get("AllocationId")
+	elasticIP, ok := s.ec2.elasticIPs[allocationID]
+
+	if !ok {
+		return nil, newAPIError("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", allocationID)
+	}
+
+	if len(elasticIP.associationID) > 0 || s.ec2.natGatewayUsingElasticIP(allocationID) != nil {
+		return nil, newAPIError("InvalidIPAddress.InUse", "Address %s is in use.", elasticIP.publicIP)
+	}
+
+	delete(s.ec2.elasticIPs, allocationID)
+
+	return s.newEC2BooleanResponse(), nil
+}
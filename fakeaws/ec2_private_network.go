@@ -0,0 +1,480 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// sessionManagerServices represents the services that
+// the SSM agent needs to reach to accept sessions.
+var sessionManagerServices = []string{
+	"ssm",
+	"ssmmessages",
+	"ec2messages",
+}
+
+type xmlNATGatewayAddress struct {
+	AllocationID string `xml:"allocationId"`
+	PublicIP     string `xml:"publicIp"`
+}
+
+type xmlNATGateway struct {
+	NATGatewayID     string                 `xml:"natGatewayId"`
+	SubnetID         string                 `xml:"subnetId"`
+	VPCID            string                 `xml:"vpcId"`
+	State            string                 `xml:"state"`
+	ConnectivityType string                 `xml:"connectivityType"`
+	Addresses        []xmlNATGatewayAddress `xml:"natGatewayAddressSet>item"`
+	Tags             []xmlTag               `xml:"tagSet>item"`
+}
+
+func (s *Server) natGatewayToXML(n *natGateway) xmlNATGateway {
+	publicIP := ""
+
+	if elasticIP, ok := s.ec2.elasticIPs[n.allocationID]; ok {
+		publicIP = elasticIP.publicIP
+	}
+
+	return xmlNATGateway{
+		NATGatewayID:     n.id,
+		SubnetID:         n.subnetID,
+		VPCID:            n.vpcID,
+		State:            n.state,
+		ConnectivityType: "public",
+		Addresses: []xmlNATGatewayAddress{{
+			AllocationID: n.allocationID,
+			PublicIP:     publicIP,
+		}},
+		Tags: toXMLTags(n.tags),
+	}
+}
+
+type createNATGatewayResponse struct {
+	XMLName    xml.Name      `xml:"CreateNatGatewayResponse"`
+	Namespace  string        `xml:"xmlns,attr"`
+	RequestID  string        `xml:"requestId"`
+	NATGateway xmlNATGateway `xml:"natGateway"`
+}
+
+func (s *Server) createNATGateway(params ec2Params) (interface{}, *apiError) {
+	subnetID := params.get("SubnetId")
+	subnet, ok := s.ec2.subnets[subnetID]
+
+	if !ok {
+		return nil, newAPIError("InvalidSubnetID.NotFound", "The subnet ID '%s' does not exist", subnetID)
+	}
+
+	allocationID := params.get("AllocationId")
+	elasticIP, ok := s.ec2.elasticIPs[allocationID]
+
+	if !ok {
+		return nil, newAPIError("InvalidAllocationID.NotFound", "The allocation ID '%s' does not exist", allocationID)
+	}
+
+	if len(elasticIP.associationID) > 0 || s.ec2.natGatewayUsingElasticIP(allocationID) != nil {
+		return nil, newAPIError("Resource.AlreadyAssociated", "Elastic IP address [%s] is already associated", allocationID)
+	}
+
+	createdNATGateway := &natGateway{
+		id:           s.newID("nat"),
+		vpcID:        subnet.vpcID,
+		subnetID:     subnetID,
+		allocationID: allocationID,
+		state:        natGatewayStateAvailable,
+		tags:         params.tags("natgateway"),
+	}
+
+	s.ec2.natGateways[createdNATGateway.id] = createdNATGateway
+
+	// Returned as pending like the real API
+	XMLNATGateway := s.natGatewayToXML(createdNATGateway)
+	XMLNATGateway.State = "pending"
+
+	return createNATGatewayResponse{
+		Namespace:  ec2XMLNamespace,
+		RequestID:  s.newRequestID(),
+		NATGateway: XMLNATGateway,
+	}, nil
+}
+
+type describeNATGatewaysResponse struct {
+	XMLName     xml.Name        `xml:"DescribeNatGatewaysResponse"`
+	Namespace   string          `xml:"xmlns,attr"`
+	RequestID   string          `xml:"requestId"`
+	NATGateways []xmlNATGateway `xml:"natGatewaySet>item"`
+}
+
+// Deleted NAT gateways are still returned
+// (with the "deleted" state) like the real API.
+func (s *Server) describeNATGateways(params ec2Params) (interface{}, *apiError) {
+	NATGatewayIDs := params.list("NatGatewayId")
+
+	if len(NATGatewayIDs) == 0 {
+		for NATGatewayID := range s.ec2.natGateways {
+			NATGatewayIDs = append(NATGatewayIDs, NATGatewayID)
+		}
+
+		sort.Strings(NATGatewayIDs)
+	}
+
+	NATGateways := []xmlNATGateway{}
+
+	for _, NATGatewayID := range NATGatewayIDs {
+		NATGateway, ok := s.ec2.natGateways[NATGatewayID]
+
+		if !ok {
+			return nil, newAPIError("NatGatewayNotFound", "The Nat Gateway %s was not found", NATGatewayID)
+		}
+
+		NATGateways = append(NATGateways, s.natGatewayToXML(NATGateway))
+	}
+
+	return describeNATGatewaysResponse{
+		Namespace:   ec2XMLNamespace,
+		RequestID:   s.newRequestID(),
+		NATGateways: NATGateways,
+	}, nil
+}
+
+type deleteNATGatewayResponse struct {
+	XMLName      xml.Name `xml:"DeleteNatGatewayResponse"`
+	Namespace    string   `xml:"xmlns,attr"`
+	RequestID    string   `xml:"requestId"`
+	NATGatewayID string   `xml:"natGatewayId"`
+}
+
+func (s *Server) deleteNATGateway(params ec2Params) (interface{}, *apiError) {
+	NATGatewayID := params.get("NatGatewayId")
+	NATGateway, ok := s.ec2.natGateways[NATGatewayID]
+
+	if !ok || NATGateway.state == natGatewayStateDeleted {
+		return nil, newAPIError("NatGatewayNotFound", "The Nat Gateway %s was not found", NATGatewayID)
+	}
+
+	NATGateway.state = natGatewayStateDeleted
+
+	return deleteNATGatewayResponse{
+		Namespace:    ec2XMLNamespace,
+		RequestID:    s.newRequestID(),
+		NATGatewayID: NATGatewayID,
+	}, nil
+}
+
+// natGatewayUsingElasticIP returns the NAT gateway (not deleted)
+// that uses the passed elastic IP, if any.
+func (e *ec2State) natGatewayUsingElasticIP(allocationID string) *natGateway {
+	for _, NATGateway := range e.natGateways {
+		if NATGateway.state != natGatewayStateDeleted &&
+			NATGateway.allocationID == allocationID {
+
+			return NATGateway
+		}
+	}
+
+	return nil
+}
+
+// hasActiveNATGateway returns true if a NAT gateway (not deleted)
+// is in the passed subnet (or VPC when subnetID is empty).
+func (e *ec2State) hasActiveNATGateway(VPCID, subnetID string) bool {
+	for _, NATGateway := range e.natGateways {
+		if NATGateway.state == natGatewayStateDeleted {
+			continue
+		}
+
+		if NATGateway.vpcID == VPCID &&
+			(len(subnetID) == 0 || NATGateway.subnetID == subnetID) {
+
+			return true
+		}
+	}
+
+	return false
+}
+
+type xmlVPCEndpoint struct {
+	VPCEndpointID     string   `xml:"vpcEndpointId"`
+	VPCEndpointType   string   `xml:"vpcEndpointType"`
+	VPCID             string   `xml:"vpcId"`
+	ServiceName       string   `xml:"serviceName"`
+	State             string   `xml:"state"`
+	PrivateDNSEnabled bool     `xml:"privateDnsEnabled"`
+	SubnetIDs         []string `xml:"subnetIdSet>item"`
+	Tags              []xmlTag `xml:"tagSet>item"`
+}
+
+func (v *vpcEndpoint) toXML() xmlVPCEndpoint {
+	return xmlVPCEndpoint{
+		VPCEndpointID:     v.id,
+		VPCEndpointType:   "Interface",
+		VPCID:             v.vpcID,
+		ServiceName:       v.serviceName,
+		State:             "available",
+		PrivateDNSEnabled: true,
+		SubnetIDs:         v.subnetIDs,
+		Tags:              toXMLTags(v.tags),
+	}
+}
+
+type createVPCEndpointResponse struct {
+	XMLName     xml.Name       `xml:"CreateVpcEndpointResponse"`
+	Namespace   string         `xml:"xmlns,attr"`
+	RequestID   string         `xml:"requestId"`
+	VPCEndpoint xmlVPCEndpoint `xml:"vpcEndpoint"`
+}
+
+// Only the interface endpoints of the
+// SSM services (with private DNS) are supported.
+func (s *Server) createVPCEndpoint(params ec2Params) (interface{}, *apiError) {
+	VPCID := params.get("VpcId")
+	VPC, ok := s.ec2.vpcs[VPCID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVpcId.NotFound", "The Vpc Id '%s' does not exist", VPCID)
+	}
+
+	if params.get("VpcEndpointType") != "Interface" || !params.bool("PrivateDnsEnabled") {
+		return nil, newAPIError("InvalidParameter", "The fake backend only supports interface endpoints with private DNS")
+	}
+
+	serviceName := params.get("ServiceName")
+
+	if !isSessionManagerServiceName(serviceName) {
+		return nil, newAPIError("InvalidServiceName", "The Vpc Endpoint Service '%s' does not exist", serviceName)
+	}
+
+	if !VPC.enableDNSSupport || !VPC.enableDNSHostnames {
+		return nil, newAPIError("InvalidParameter", "Enabling private DNS requires both enableDnsSupport and enableDnsHostnames VPC attributes set to true for %s", VPCID)
+	}
+
+	subnetIDs := params.list("SubnetId")
+	subnetAvailabilityZones := map[string]bool{}
+
+	for _, subnetID := range subnetIDs {
+		subnet, ok := s.ec2.subnets[subnetID]
+
+		if !ok || subnet.vpcID != VPCID {
+			return nil, newAPIError("InvalidSubnetId.NotFound", "The subnet ID '%s' does not exist", subnetID)
+		}
+
+		if subnetAvailabilityZones[subnet.availabilityZone] {
+			return nil, newAPIError("DuplicateSubnetsInSameZone", "Found another VPC endpoint subnet in the availability zone of %s", subnetID)
+		}
+
+		subnetAvailabilityZones[subnet.availabilityZone] = true
+	}
+
+	securityGroupIDs := params.list("SecurityGroupId")
+
+	for _, securityGroupID := range securityGroupIDs {
+		if _, ok := s.ec2.securityGroups[securityGroupID]; !ok {
+			return nil, newAPIError("InvalidSecurityGroupId.NotFound", "The security group ID '%s' does not exist", securityGroupID)
+		}
+	}
+
+	for _, existingVPCEndpoint := range s.ec2.vpcEndpoints {
+		if existingVPCEndpoint.vpcID == VPCID && existingVPCEndpoint.serviceName == serviceName {
+			return nil, newAPIError("InvalidParameter", "private-dns-enabled cannot be set because there is already a conflicting DNS domain for %s in the VPC %s", serviceName, VPCID)
+		}
+	}
+
+	createdVPCEndpoint := &vpcEndpoint{
+		id:               s.newID("vpce"),
+		vpcID:            VPCID,
+		serviceName:      serviceName,
+		subnetIDs:        subnetIDs,
+		securityGroupIDs: securityGroupIDs,
+		tags:             params.tags("vpc-endpoint"),
+	}
+
+	s.ec2.vpcEndpoints[createdVPCEndpoint.id] = createdVPCEndpoint
+
+	// Returned as pending like the real API
+	XMLVPCEndpoint := createdVPCEndpoint.toXML()
+	XMLVPCEndpoint.State = "pending"
+
+	return createVPCEndpointResponse{
+		Namespace:   ec2XMLNamespace,
+		RequestID:   s.newRequestID(),
+		VPCEndpoint: XMLVPCEndpoint,
+	}, nil
+}
+
+type describeVPCEndpointsResponse struct {
+	XMLName      xml.Name         `xml:"DescribeVpcEndpointsResponse"`
+	Namespace    string           `xml:"xmlns,attr"`
+	RequestID    string           `xml:"requestId"`
+	VPCEndpoints []xmlVPCEndpoint `xml:"vpcEndpointSet>item"`
+}
+
+func (s *Server) describeVPCEndpoints(params ec2Params) (interface{}, *apiError) {
+	VPCEndpointIDs := params.list("VpcEndpointId")
+
+	if len(VPCEndpointIDs) == 0 {
+		for VPCEndpointID := range s.ec2.vpcEndpoints {
+			VPCEndpointIDs = append(VPCEndpointIDs, VPCEndpointID)
+		}
+
+		sort.Strings(VPCEndpointIDs)
+	}
+
+	VPCEndpoints := []xmlVPCEndpoint{}
+
+	for _, VPCEndpointID := range VPCEndpointIDs {
+		VPCEndpoint, ok := s.ec2.vpcEndpoints[VPCEndpointID]
+
+		if !ok {
+			return nil, newAPIError("InvalidVpcEndpointId.NotFound", "The Vpc Endpoint Id '%s' does not exist", VPCEndpointID)
+		}
+
+		VPCEndpoints = append(VPCEndpoints, VPCEndpoint.toXML())
+	}
+
+	return describeVPCEndpointsResponse{
+		Namespace:    ec2XMLNamespace,
+		RequestID:    s.newRequestID(),
+		VPCEndpoints: VPCEndpoints,
+	}, nil
+}
+
+type xmlUnsuccessfulItemError struct {
+	Code    string `xml:"code"`
+	Message string `xml:"message"`
+}
+
+type xmlUnsuccessfulItem struct {
+	ResourceID string                   `xml:"resourceId"`
+	Error      xmlUnsuccessfulItemError `xml:"error"`
+}
+
+type deleteVPCEndpointsResponse struct {
+	XMLName      xml.Name              `xml:"DeleteVpcEndpointsResponse"`
+	Namespace    string                `xml:"xmlns,attr"`
+	RequestID    string                `xml:"requestId"`
+	Unsuccessful []xmlUnsuccessfulItem `xml:"unsuccessful>item"`
+}
+
+// VPC endpoints are removed immediately.
+// Unknown endpoints are reported as unsuccessful items.
+func (s *Server) deleteVPCEndpoints(params ec2Params) (interface{}, *apiError) {
+	unsuccessful := []xmlUnsuccessfulItem{}
+
+	for _, VPCEndpointID := range params.list("VpcEndpointId") {
+		if _, ok := s.ec2.vpcEndpoints[VPCEndpointID]; !ok {
+			unsuccessful = append(unsuccessful, xmlUnsuccessfulItem{
+				ResourceID: VPCEndpointID,
+				Error: xmlUnsuccessfulItemError{
+					Code:    "InvalidVpcEndpoint.NotFound",
+					Message: fmt.Sprintf("The VPC endpoint '%s' does not exist", VPCEndpointID),
+				},
+			})
+			continue
+		}
+
+		delete(s.ec2.vpcEndpoints, VPCEndpointID)
+	}
+
+	return deleteVPCEndpointsResponse{
+		Namespace:    ec2XMLNamespace,
+		RequestID:    s.newRequestID(),
+		Unsuccessful: unsuccessful,
+	}, nil
+}
+
+func isSessionManagerServiceName(serviceName string) bool {
+	for _, service := range sessionManagerServices {
+		if serviceName == fmt.Sprintf("com.amazonaws.%s.%s", Region, service) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasVPCEndpointInSubnet returns true if a VPC
+// endpoint has a network interface in the subnet.
+func (e *ec2State) hasVPCEndpointInSubnet(subnetID string) bool {
+	for _, VPCEndpoint := range e.vpcEndpoints {
+		for _, endpointSubnetID := range VPCEndpoint.subnetIDs {
+			if endpointSubnetID == subnetID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasVPCEndpointWithSecurityGroup returns true if a
+// VPC endpoint uses the passed security group.
+func (e *ec2State) hasVPCEndpointWithSecurityGroup(securityGroupID string) bool {
+	for _, VPCEndpoint := range e.vpcEndpoints {
+		for _, endpointSecurityGroupID := range VPCEndpoint.securityGroupIDs {
+			if endpointSecurityGroupID == securityGroupID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// canReachSessionManager returns true if the instance could
+// register with SSM: it needs an instance profile and a route
+// to the SSM endpoints (via a NAT gateway, an internet gateway
+// with a public IP or the SSM VPC endpoints).
+func (e *ec2State) canReachSessionManager(i *instance) bool {
+	if len(i.instanceProfile) == 0 {
+		return false
+	}
+
+	subnet, ok := e.subnets[i.subnetID]
+
+	if !ok {
+		return false
+	}
+
+	for _, routeTable := range e.routeTables {
+		for _, associatedSubnetID := range routeTable.associations {
+			if associatedSubnetID != subnet.id {
+				continue
+			}
+
+			for _, route := range routeTable.routes {
+				if route.destinationCIDRBlock != "0.0.0.0/0" {
+					continue
+				}
+
+				if NATGateway, ok := e.natGateways[route.natGatewayID]; ok &&
+					NATGateway.state != natGatewayStateDeleted {
+
+					return true
+				}
+
+				if strings.HasPrefix(route.gatewayID, "igw-") && len(i.publicIPAddress) > 0 {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, service := range sessionManagerServices {
+		hasVPCEndpoint := false
+
+		for _, VPCEndpoint := range e.vpcEndpoints {
+			if VPCEndpoint.vpcID == subnet.vpcID &&
+				VPCEndpoint.serviceName == fmt.Sprintf("com.amazonaws.%s.%s", Region, service) {
+
+				hasVPCEndpoint = true
+			}
+		}
+
+		if !hasVPCEndpoint {
+			return false
+		}
+	}
+
+	return true
+}
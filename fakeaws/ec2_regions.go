@@ -0,0 +1,139 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// regions lists the regions returned by
+// DescribeRegions with their opt-in status.
+var regions = map[string]string{
+	"us-east-1":      "opt-in-not-required",
+	"us-west-2":      "opt-in-not-required",
+	"eu-west-1":      "opt-in-not-required",
+	"eu-west-3":      "opt-in-not-required",
+	"af-south-1":     "not-opted-in",
+	"ap-southeast-4": "opted-in",
+}
+
+type xmlRegion struct {
+	RegionName  string `xml:"regionName"`
+	Endpoint    string `xml:"regionEndpoint"`
+	OptInStatus string `xml:"optInStatus"`
+}
+
+type describeRegionsResponse struct {
+	XMLName   xml.Name    `xml:"DescribeRegionsResponse"`
+	Namespace string      `xml:"xmlns,attr"`
+	RequestID string      `xml:"requestId"`
+	Regions   []xmlRegion `xml:"regionInfo>item"`
+}
+
+func (s *Server) describeRegions(params ec2Params) (interface{}, *apiError) {
+	regionNames := params.list("RegionName")
+
+	if len(regionNames) == 0 {
+		for regionName := range regions {
+			regionNames = append(regionNames, regionName)
+		}
+
+		sort.Strings(regionNames)
+	}
+
+	XMLRegions := []xmlRegion{}
+
+	for _, regionName := range regionNames {
+		optInStatus, ok := regions[regionName]
+
+		if !ok {
+			return nil, newAPIError(
+				"InvalidParameterValue",
+				"Invalid region: %s",
+				regionName,
+			)
+		}
+
+		// Disabled regions are only returned
+		// when AllRegions is set
+		if optInStatus == "not-opted-in" && !params.bool("AllRegions") {
+			continue
+		}
+
+		XMLRegions = append(XMLRegions, xmlRegion{
+			RegionName:  regionName,
+			Endpoint:    "ec2." + regionName + ".amazonaws.com",
+			OptInStatus: optInStatus,
+		})
+	}
+
+	return describeRegionsResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Regions:   XMLRegions,
+	}, nil
+}
+
+// availabilityZones lists the availability zones
+// of the region returned in the SDK config.
+var availabilityZones = []string{
+	Region + "a",
+	Region + "b",
+	Region + "c",
+}
+
+func isAvailabilityZone(name string) bool {
+	for _, availabilityZone := range availabilityZones {
+		if availabilityZone == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+type xmlAvailabilityZone struct {
+	ZoneName   string `xml:"zoneName"`
+	ZoneType   string `xml:"zoneType"`
+	State      string `xml:"zoneState"`
+	RegionName string `xml:"regionName"`
+}
+
+type describeAvailabilityZonesResponse struct {
+	XMLName           xml.Name              `xml:"DescribeAvailabilityZonesResponse"`
+	Namespace         string                `xml:"xmlns,attr"`
+	RequestID         string                `xml:"requestId"`
+	AvailabilityZones []xmlAvailabilityZone `xml:"availabilityZoneInfo>item"`
+}
+
+func (s *Server) describeAvailabilityZones(params ec2Params) (interface{}, *apiError) {
+	zoneNames := params.list("ZoneName")
+
+	if len(zoneNames) == 0 {
+		zoneNames = availabilityZones
+	}
+
+	XMLAvailabilityZones := []xmlAvailabilityZone{}
+
+	for _, zoneName := range zoneNames {
+		if !isAvailabilityZone(zoneName) {
+			return nil, newAPIError(
+				"InvalidParameterValue",
+				"Invalid availability zone: [%s]",
+				zoneName,
+			)
+		}
+
+		XMLAvailabilityZones = append(XMLAvailabilityZones, xmlAvailabilityZone{
+			ZoneName:   zoneName,
+			ZoneType:   "availability-zone",
+			State:      "available",
+			RegionName: Region,
+		})
+	}
+
+	return describeAvailabilityZonesResponse{
+		Namespace:         ec2XMLNamespace,
+		RequestID:         s.newRequestID(),
+		AvailabilityZones: XMLAvailabilityZones,
+	}, nil
+}
@@ -0,0 +1,191 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// setStatus must be called with the lock held.
+func (r *spotInstanceRequest) setStatus(state, code, message string) {
+	r.state = state
+	r.statusCode = code
+	r.statusMessage = message
+	r.updateTime = time.Now().UTC().Format(time.RFC3339)
+}
+
+type xmlSpotInstanceRequest struct {
+	SpotInstanceRequestID        string `xml:"spotInstanceRequestId"`
+	State                        string `xml:"state"`
+	StatusCode                   string `xml:"status>code"`
+	StatusMessage                string `xml:"status>message"`
+	StatusUpdateTime             string `xml:"status>updateTime"`
+	InstanceID                   string `xml:"instanceId,omitempty"`
+	SpotPrice                    string `xml:"spotPrice,omitempty"`
+	Type                         string `xml:"type"`
+	InstanceInterruptionBehavior string `xml:"instanceInterruptionBehavior"`
+}
+
+type describeSpotInstanceRequestsResponse struct {
+	XMLName              xml.Name                 `xml:"DescribeSpotInstanceRequestsResponse"`
+	Namespace            string                   `xml:"xmlns,attr"`
+	RequestID            string                   `xml:"requestId"`
+	SpotInstanceRequests []xmlSpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+func (s *Server) describeSpotInstanceRequests(params ec2Params) (interface{}, *apiError) {
+	spotRequests := []xmlSpotInstanceRequest{}
+
+	for _, spotRequestID := range params.list("SpotInstanceRequestId") {
+		spotRequest, ok := s.ec2.spotInstanceRequests[spotRequestID]
+
+		if !ok {
+			return nil, newAPIError(
+				"InvalidSpotInstanceRequestID.NotFound",
+				"The spot instance request ID '%s' does not exist",
+				spotRequestID,
+			)
+		}
+
+		spotRequests = append(spotRequests, xmlSpotInstanceRequest{
+			SpotInstanceRequestID:        spotRequest.id,
+			State:                        spotRequest.state,
+			StatusCode:                   spotRequest.statusCode,
+			StatusMessage:                spotRequest.statusMessage,
+			StatusUpdateTime:             spotRequest.updateTime,
+			InstanceID:                   spotRequest.instanceID,
+			SpotPrice:                    spotRequest.maxPrice,
+			Type:                         spotRequest.requestType,
+			InstanceInterruptionBehavior: spotRequest.interruptionBehavior,
+		})
+	}
+
+	return describeSpotInstanceRequestsResponse{
+		Namespace:            ec2XMLNamespace,
+		RequestID:            s.newRequestID(),
+		SpotInstanceRequests: spotRequests,
+	}, nil
+}
+
+type xmlCancelledSpotInstanceRequest struct {
+	SpotInstanceRequestID string `xml:"spotInstanceRequestId"`
+	State                 string `xml:"state"`
+}
+
+type cancelSpotInstanceRequestsResponse struct {
+	XMLName              xml.Name                          `xml:"CancelSpotInstanceRequestsResponse"`
+	Namespace            string                            `xml:"xmlns,attr"`
+	RequestID            string                            `xml:"requestId"`
+	SpotInstanceRequests []xmlCancelledSpotInstanceRequest `xml:"spotInstanceRequestSet>item"`
+}
+
+// cancelSpotInstanceRequests cancels the spot requests.
+// Like the real API, their instances are not terminated.
+func (s *Server) cancelSpotInstanceRequests(params ec2Params) (interface{}, *apiError) {
+	spotRequestIDs := params.list("SpotInstanceRequestId")
+
+	for _, spotRequestID := range spotRequestIDs {
+		if _, ok := s.ec2.spotInstanceRequests[spotRequestID]; !ok {
+			return nil, newAPIError(
+				"InvalidSpotInstanceRequestID.NotFound",
+				"The spot instance request ID '%s' does not exist",
+				spotRequestID,
+			)
+		}
+	}
+
+	cancelledSpotRequests := []xmlCancelledSpotInstanceRequest{}
+
+	for _, spotRequestID := range spotRequestIDs {
+		spotRequest := s.ec2.spotInstanceRequests[spotRequestID]
+
+		if spotRequest.state != spotInstanceRequestStateCancelled &&
+			spotRequest.state != spotInstanceRequestStateClosed {
+
+			spotRequest.setStatus(
+				spotInstanceRequestStateCancelled,
+				"request-canceled-and-instance-running",
+				"Spot request canceled but instance remains.",
+			)
+		}
+
+		cancelledSpotRequests = append(cancelledSpotRequests, xmlCancelledSpotInstanceRequest{
+			SpotInstanceRequestID: spotRequest.id,
+			State:                 spotRequest.state,
+		})
+	}
+
+	return cancelSpotInstanceRequestsResponse{
+		Namespace:            ec2XMLNamespace,
+		RequestID:            s.newRequestID(),
+		SpotInstanceRequests: cancelledSpotRequests,
+	}, nil
+}
+
+// NotifySpotInstanceInterruption sends the interruption notice
+// that EC2 sends two minutes before interrupting a spot instance.
+// It does nothing if the instance is not a spot instance.
+func (s *Server) NotifySpotInstanceInterruption(instanceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spotRequest := s.instanceSpotRequest(instanceID)
+
+	if spotRequest == nil {
+		return
+	}
+
+	spotRequest.setStatus(
+		spotRequest.state,
+		"marked-for-"+spotRequest.interruptionBehavior,
+		"Spot instance is marked for interruption.",
+	)
+}
+
+// InterruptSpotInstance stops (or hibernates) the spot instance
+// like EC2 does when it reclaims its capacity. It does nothing
+// if the instance is not a running spot instance.
+func (s *Server) InterruptSpotInstance(instanceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spotRequest := s.instanceSpotRequest(instanceID)
+	instance := s.ec2.instances[instanceID]
+
+	if spotRequest == nil || instance.state != instanceStateRunning {
+		return
+	}
+
+	if spotRequest.interruptionBehavior == "terminate" {
+		s.terminateInstance(instance)
+		spotRequest.setStatus(
+			spotInstanceRequestStateClosed,
+			"instance-terminated-no-capacity",
+			"Spot instance terminated due to no available capacity.",
+		)
+		return
+	}
+
+	instance.state = instanceStateStopped
+	instance.hibernated = spotRequest.interruptionBehavior == "hibernate"
+
+	if s.ec2.elasticIPByInstanceID(instance.id) == nil {
+		instance.publicIPAddress = ""
+	}
+
+	spotRequest.setStatus(
+		spotInstanceRequestStateDisabled,
+		"instance-stopped-no-capacity",
+		"Spot instance stopped due to no available capacity.",
+	)
+}
+
+// instanceSpotRequest must be called with the lock held.
+func (s *Server) instanceSpotRequest(instanceID string) *spotInstanceRequest {
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok {
+		return nil
+	}
+
+	return s.ec2.spotInstanceRequests[instance.spotInstanceRequestID]
+}
@@ -0,0 +1,337 @@
+package fakeaws
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	instanceStatePending    = "pending"
+	instanceStateRunning    = "running"
+	instanceStateStopping   = "stopping"
+	instanceStateStopped    = "stopped"
+	instanceStateTerminated = "terminated"
+
+	natGatewayStateAvailable = "available"
+	natGatewayStateDeleted   = "deleted"
+
+	volumeStateAvailable = "available"
+	volumeStateInUse     = "in-use"
+)
+
+type vpc struct {
+	id                 string
+	cidrBlock          string
+	IPv6CIDRBlock      string
+	enableDNSSupport   bool
+	enableDNSHostnames bool
+	tags               map[string]string
+}
+
+type subnet struct {
+	id                          string
+	vpcID                       string
+	cidrBlock                   string
+	IPv6CIDRBlock               string
+	availabilityZone            string
+	mapPublicIPOnLaunch         bool
+	assignIPv6AddressOnCreation bool
+	lastIPSuffix                int
+	tags                        map[string]string
+}
+
+type internetGateway struct {
+	id    string
+	vpcID string
+	tags  map[string]string
+}
+
+type route struct {
+	destinationCIDRBlock     string
+	destinationIPv6CIDRBlock string
+	gatewayID                string
+	natGatewayID             string
+}
+
+type routeTable struct {
+	id     string
+	vpcID  string
+	routes []route
+	// association ID -> subnet ID
+	associations map[string]string
+	tags         map[string]string
+}
+
+type natGateway struct {
+	id           string
+	vpcID        string
+	subnetID     string
+	allocationID string
+	state        string
+	tags         map[string]string
+}
+
+type vpcEndpoint struct {
+	id               string
+	vpcID            string
+	serviceName      string
+	subnetIDs        []string
+	securityGroupIDs []string
+	tags             map[string]string
+}
+
+type securityGroupRule struct {
+	protocol    string
+	fromPort    int32
+	toPort      int32
+	CIDRIPv4    string
+	CIDRIPv6    string
+	description string
+}
+
+func (r securityGroupRule) sameAs(other securityGroupRule) bool {
+	return r.protocol == other.protocol &&
+		r.fromPort == other.fromPort &&
+		r.toPort == other.toPort &&
+		r.CIDRIPv4 == other.CIDRIPv4 &&
+		r.CIDRIPv6 == other.CIDRIPv6
+}
+
+type securityGroup struct {
+	id          string
+	name        string
+	description string
+	vpcID       string
+	ingress     []securityGroupRule
+	tags        map[string]string
+}
+
+type networkInterface struct {
+	id               string
+	subnetID         string
+	securityGroupIDs []string
+	description      string
+	privateIPAddress string
+	IPv6Address      string
+	instanceID       string
+	tags             map[string]string
+}
+
+type elasticIP struct {
+	allocationID  string
+	publicIP      string
+	associationID string
+	instanceID    string
+	tags          map[string]string
+}
+
+type keyPair struct {
+	id          string
+	name        string
+	publicKey   ssh.PublicKey
+	fingerprint string
+	tags        map[string]string
+}
+
+type instanceVolume struct {
+	deviceName string
+	volumeID   string
+}
+
+type instance struct {
+	id                 string
+	instanceType       string
+	imageID            string
+	keyName            string
+	instanceProfile    string
+	state              string
+	subnetID           string
+	networkInterfaceID string
+	privateIPAddress   string
+	publicIPAddress    string
+	rootDeviceName     string
+	volumes            []instanceVolume
+	userData           string
+	agentHostKey       string
+	tags               map[string]string
+
+	hibernationConfigured bool
+	hibernated            bool
+	spotInstanceRequestID string
+
+	// sshCommands lists the commands run
+	// on the instance via its SSH server
+	sshCommands []string
+}
+
+type volume struct {
+	id               string
+	size             int32
+	volumeType       string
+	availabilityZone string
+	snapshotID       string
+	instanceID       string
+	deviceName       string
+	encrypted        bool
+	kmsKeyID         string
+	iops             int32
+	throughput       int32
+	tags             map[string]string
+
+	// modification is the last
+	// modification of the volume
+	modification *volumeModification
+}
+
+const (
+	volumeModificationStateModifying  = "modifying"
+	volumeModificationStateOptimizing = "optimizing"
+	volumeModificationStateCompleted  = "completed"
+)
+
+type volumeModification struct {
+	originalSize int32
+	targetSize   int32
+	state        string
+	startTime    string
+}
+
+func (v *volume) state() string {
+	if len(v.instanceID) > 0 {
+		return volumeStateInUse
+	}
+
+	return volumeStateAvailable
+}
+
+type snapshot struct {
+	id       string
+	volumeID string
+	size     int32
+	tags     map[string]string
+}
+
+const (
+	spotInstanceRequestStateOpen      = "open"
+	spotInstanceRequestStateActive    = "active"
+	spotInstanceRequestStateDisabled  = "disabled"
+	spotInstanceRequestStateClosed    = "closed"
+	spotInstanceRequestStateCancelled = "cancelled"
+)
+
+type spotInstanceRequest struct {
+	id                   string
+	instanceID           string
+	requestType          string
+	maxPrice             string
+	interruptionBehavior string
+	state                string
+	statusCode           string
+	statusMessage        string
+	updateTime           string
+}
+
+type ec2State struct {
+	vpcs              map[string]*vpc
+	subnets           map[string]*subnet
+	internetGateways  map[string]*internetGateway
+	routeTables       map[string]*routeTable
+	natGateways       map[string]*natGateway
+	vpcEndpoints      map[string]*vpcEndpoint
+	securityGroups    map[string]*securityGroup
+	networkInterfaces map[string]*networkInterface
+	elasticIPs        map[string]*elasticIP
+	keyPairs          map[string]*keyPair
+	instances         map[string]*instance
+	volumes           map[string]*volume
+	snapshots         map[string]*snapshot
+
+	spotInstanceRequests map[string]*spotInstanceRequest
+
+	lastPublicIPSuffix int
+	lastIPv6BlockIndex int
+}
+
+func newEC2State() *ec2State {
+	return &ec2State{
+		vpcs:              map[string]*vpc{},
+		subnets:           map[string]*subnet{},
+		internetGateways:  map[string]*internetGateway{},
+		routeTables:       map[string]*routeTable{},
+		natGateways:       map[string]*natGateway{},
+		vpcEndpoints:      map[string]*vpcEndpoint{},
+		securityGroups:    map[string]*securityGroup{},
+		networkInterfaces: map[string]*networkInterface{},
+		elasticIPs:        map[string]*elasticIP{},
+		keyPairs:          map[string]*keyPair{},
+		instances:         map[string]*instance{},
+		volumes:           map[string]*volume{},
+		snapshots:         map[string]*snapshot{},
+
+		spotInstanceRequests: map[string]*spotInstanceRequest{},
+	}
+}
+
+func (e *ec2State) newPublicIPAddress(prefix string) string {
+	e.lastPublicIPSuffix++
+	return fmt.Sprintf("%s.%d", prefix, e.lastPublicIPSuffix%254+1)
+}
+
+func (s *subnet) newPrivateIPAddress() string {
+	s.lastIPSuffix++
+
+	subnetIP, _, err := net.ParseCIDR(s.cidrBlock)
+
+	if err != nil || subnetIP.To4() == nil {
+		return fmt.Sprintf("10.0.0.%d", s.lastIPSuffix%250+4)
+	}
+
+	IP := subnetIP.To4()
+	return fmt.Sprintf("%d.%d.%d.%d", IP[0], IP[1], IP[2], s.lastIPSuffix%250+4)
+}
+
+// newIPv6CIDRBlock returns an Amazon-provided
+// IPv6 block (a /56) for a VPC.
+func (e *ec2State) newIPv6CIDRBlock() string {
+	e.lastIPv6BlockIndex++
+	return fmt.Sprintf("2600:1f18:1234:%x00::/56", e.lastIPv6BlockIndex%255+1)
+}
+
+func (s *subnet) newIPv6Address() string {
+	s.lastIPSuffix++
+
+	subnetIP, _, _ := net.ParseCIDR(s.IPv6CIDRBlock)
+	IP := make(net.IP, len(subnetIP))
+	copy(IP, subnetIP)
+
+	IP[len(IP)-1] = byte(s.lastIPSuffix%250 + 4)
+	return IP.String()
+}
+
+// elasticIPByInstanceID returns the elastic
+// IP associated with the passed instance, if any.
+func (e *ec2State) elasticIPByInstanceID(instanceID string) *elasticIP {
+	for _, elasticIP := range e.elasticIPs {
+		if elasticIP.instanceID == instanceID {
+			return elasticIP
+		}
+	}
+
+	return nil
+}
+
+// instanceByPublicIPAddress returns the running instance
+// that could be reached with the passed public IP address.
+func (e *ec2State) instanceByPublicIPAddress(publicIPAddress string) *instance {
+	for _, instance := range e.instances {
+		if instance.state == instanceStateRunning &&
+			instance.publicIPAddress == publicIPAddress {
+
+			return instance
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,474 @@
+package fakeaws
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type xmlVolumeAttachment struct {
+	VolumeID   string `xml:"volumeId"`
+	InstanceID string `xml:"instanceId"`
+	Device     string `xml:"device"`
+	Status     string `xml:"status"`
+}
+
+type xmlVolume struct {
+	VolumeID         string                `xml:"volumeId"`
+	Size             int32                 `xml:"size"`
+	SnapshotID       string                `xml:"snapshotId"`
+	AvailabilityZone string                `xml:"availabilityZone"`
+	Status           string                `xml:"status"`
+	VolumeType       string                `xml:"volumeType"`
+	IOPS             int32                 `xml:"iops,omitempty"`
+	Throughput       int32                 `xml:"throughput,omitempty"`
+	Encrypted        bool                  `xml:"encrypted"`
+	KMSKeyID         string                `xml:"kmsKeyId,omitempty"`
+	Attachments      []xmlVolumeAttachment `xml:"attachmentSet>item"`
+	Tags             []xmlTag              `xml:"tagSet>item"`
+}
+
+func (v *volume) toXML() xmlVolume {
+	attachments := []xmlVolumeAttachment{}
+
+	if len(v.instanceID) > 0 {
+		attachments = append(attachments, xmlVolumeAttachment{
+			VolumeID:   v.id,
+			InstanceID: v.instanceID,
+			Device:     v.deviceName,
+			Status:     "attached",
+		})
+	}
+
+	return xmlVolume{
+		VolumeID:         v.id,
+		Size:             v.size,
+		SnapshotID:       v.snapshotID,
+		AvailabilityZone: v.availabilityZone,
+		Status:           v.state(),
+		VolumeType:       v.volumeType,
+		IOPS:             v.iops,
+		Throughput:       v.throughput,
+		Encrypted:        v.encrypted,
+		KMSKeyID:         v.kmsKeyID,
+		Attachments:      attachments,
+		Tags:             toXMLTags(v.tags),
+	}
+}
+
+type createVolumeResponse struct {
+	XMLName   xml.Name `xml:"CreateVolumeResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	xmlVolume
+}
+
+func (s *Server) createVolume(params ec2Params) (interface{}, *apiError) {
+	availabilityZone := params.get("AvailabilityZone")
+
+	if len(availabilityZone) == 0 {
+		return nil, newAPIError("MissingParameter", "The request must contain the parameter AvailabilityZone")
+	}
+
+	size, apiErr := params.int32("Size", 0)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	snapshotID := params.get("SnapshotId")
+
+	if len(snapshotID) > 0 {
+		snapshot, ok := s.ec2.snapshots[snapshotID]
+
+		if !ok {
+			return nil, newAPIError("InvalidSnapshot.NotFound", "The snapshot '%s' does not exist.", snapshotID)
+		}
+
+		if size == 0 {
+			size = snapshot.size
+		}
+	}
+
+	if size == 0 {
+		return nil, newAPIError("MissingParameter", "The request must contain the parameter size or snapshotId")
+	}
+
+	volumeType := params.get("VolumeType")
+
+	if len(volumeType) == 0 {
+		volumeType = "gp2"
+	}
+
+	createdVolume := &volume{
+		id:               s.newID("vol"),
+		size:             size,
+		volumeType:       volumeType,
+		availabilityZone: availabilityZone,
+		snapshotID:       snapshotID,
+		tags:             params.tags("volume"),
+	}
+
+	s.ec2.volumes[createdVolume.id] = createdVolume
+
+	return createVolumeResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		xmlVolume: createdVolume.toXML(),
+	}, nil
+}
+
+type describeVolumesResponse struct {
+	XMLName   xml.Name    `xml:"DescribeVolumesResponse"`
+	Namespace string      `xml:"xmlns,attr"`
+	RequestID string      `xml:"requestId"`
+	Volumes   []xmlVolume `xml:"volumeSet>item"`
+}
+
+func (s *Server) describeVolumes(params ec2Params) (interface{}, *apiError) {
+	volumes := []xmlVolume{}
+
+	for _, volumeID := range params.list("VolumeId") {
+		volume, ok := s.ec2.volumes[volumeID]
+
+		if !ok {
+			return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+		}
+
+		volumes = append(volumes, volume.toXML())
+	}
+
+	return describeVolumesResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Volumes:   volumes,
+	}, nil
+}
+
+type volumeAttachmentResponse struct {
+	XMLName   xml.Name `xml:"Response"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	xmlVolumeAttachment
+}
+
+func (s *Server) attachVolume(params ec2Params) (interface{}, *apiError) {
+	volumeID := params.get("VolumeId")
+	volume, ok := s.ec2.volumes[volumeID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+	}
+
+	instanceID := params.get("InstanceId")
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok || instance.state == instanceStateTerminated {
+		return nil, newAPIError("InvalidInstanceID.NotFound", "The instance ID '%s' does not exist", instanceID)
+	}
+
+	if len(volume.instanceID) > 0 {
+		return nil, newAPIError("VolumeInUse", "%s is already attached to an instance", volumeID)
+	}
+
+	if subnet := s.ec2.subnets[instance.subnetID]; subnet != nil &&
+		subnet.availabilityZone != volume.availabilityZone {
+
+		return nil, newAPIError(
+			"InvalidVolume.ZoneMismatch",
+			"The volume '%s' is not in the same availability zone as instance '%s'",
+			volumeID,
+			instanceID,
+		)
+	}
+
+	deviceName := params.get("Device")
+
+	for _, instanceVolume := range instance.volumes {
+		if instanceVolume.deviceName == deviceName {
+			return nil, newAPIError("InvalidParameterValue", "Attachment point %s is already in use", deviceName)
+		}
+	}
+
+	volume.instanceID = instanceID
+	volume.deviceName = deviceName
+
+	instance.volumes = append(instance.volumes, instanceVolume{
+		deviceName: deviceName,
+		volumeID:   volumeID,
+	})
+
+	return volumeAttachmentResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		xmlVolumeAttachment: xmlVolumeAttachment{
+			VolumeID:   volumeID,
+			InstanceID: instanceID,
+			Device:     deviceName,
+			Status:     "attaching",
+		},
+	}, nil
+}
+
+func (s *Server) detachVolume(params ec2Params) (interface{}, *apiError) {
+	volumeID := params.get("VolumeId")
+	volume, ok := s.ec2.volumes[volumeID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+	}
+
+	if len(volume.instanceID) == 0 {
+		return nil, newAPIError("IncorrectState", "Volume '%s' is in the 'available' state.", volumeID)
+	}
+
+	instanceID := volume.instanceID
+	deviceName := volume.deviceName
+
+	if instance, ok := s.ec2.instances[instanceID]; ok {
+		remainingVolumes := []instanceVolume{}
+
+		for _, instanceVolume := range instance.volumes {
+			if instanceVolume.volumeID != volumeID {
+				remainingVolumes = append(remainingVolumes, instanceVolume)
+			}
+		}
+
+		instance.volumes = remainingVolumes
+	}
+
+	volume.instanceID = ""
+	volume.deviceName = ""
+
+	return volumeAttachmentResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		xmlVolumeAttachment: xmlVolumeAttachment{
+			VolumeID:   volumeID,
+			InstanceID: instanceID,
+			Device:     deviceName,
+			Status:     "detaching",
+		},
+	}, nil
+}
+
+func (s *Server) deleteVolume(params ec2Params) (interface{}, *apiError) {
+	volumeID := params.get("VolumeId")
+	volume, ok := s.ec2.volumes[volumeID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+	}
+
+	if len(volume.instanceID) > 0 {
+		return nil, newAPIError("VolumeInUse", "Volume %s is currently attached to %s", volumeID, volume.instanceID)
+	}
+
+	delete(s.ec2.volumes, volumeID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlSnapshot struct {
+	SnapshotID string   `xml:"snapshotId"`
+	VolumeID   string   `xml:"volumeId"`
+	VolumeSize int32    `xml:"volumeSize"`
+	Status     string   `xml:"status"`
+	Progress   string   `xml:"progress"`
+	Tags       []xmlTag `xml:"tagSet>item"`
+}
+
+func (s *snapshot) toXML() xmlSnapshot {
+	return xmlSnapshot{
+		SnapshotID: s.id,
+		VolumeID:   s.volumeID,
+		VolumeSize: s.size,
+		Status:     "completed",
+		Progress:   "100%",
+		Tags:       toXMLTags(s.tags),
+	}
+}
+
+type createSnapshotResponse struct {
+	XMLName   xml.Name `xml:"CreateSnapshotResponse"`
+	Namespace string   `xml:"xmlns,attr"`
+	RequestID string   `xml:"requestId"`
+	xmlSnapshot
+}
+
+func (s *Server) createSnapshot(params ec2Params) (interface{}, *apiError) {
+	volumeID := params.get("VolumeId")
+	volume, ok := s.ec2.volumes[volumeID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+	}
+
+	createdSnapshot := &snapshot{
+		id:       s.newID("snap"),
+		volumeID: volumeID,
+		size:     volume.size,
+		tags:     params.tags("snapshot"),
+	}
+
+	s.ec2.snapshots[createdSnapshot.id] = createdSnapshot
+
+	return createSnapshotResponse{
+		Namespace:   ec2XMLNamespace,
+		RequestID:   s.newRequestID(),
+		xmlSnapshot: createdSnapshot.toXML(),
+	}, nil
+}
+
+type describeSnapshotsResponse struct {
+	XMLName   xml.Name      `xml:"DescribeSnapshotsResponse"`
+	Namespace string        `xml:"xmlns,attr"`
+	RequestID string        `xml:"requestId"`
+	Snapshots []xmlSnapshot `xml:"snapshotSet>item"`
+}
+
+func (s *Server) describeSnapshots(params ec2Params) (interface{}, *apiError) {
+	snapshots := []xmlSnapshot{}
+
+	for _, snapshotID := range params.list("SnapshotId") {
+		snapshot, ok := s.ec2.snapshots[snapshotID]
+
+		if !ok {
+			return nil, newAPIError("InvalidSnapshot.NotFound", "The snapshot '%s' does not exist.", snapshotID)
+		}
+
+		snapshots = append(snapshots, snapshot.toXML())
+	}
+
+	return describeSnapshotsResponse{
+		Namespace: ec2XMLNamespace,
+		RequestID: s.newRequestID(),
+		Snapshots: snapshots,
+	}, nil
+}
+
+func (s *Server) deleteSnapshot(params ec2Params) (interface{}, *apiError) {
+	snapshotID := params.get("SnapshotId")
+
+	if _, ok := s.ec2.snapshots[snapshotID]; !ok {
+		return nil, newAPIError("InvalidSnapshot.NotFound", "The snapshot '%s' does not exist.", snapshotID)
+	}
+
+	delete(s.ec2.snapshots, snapshotID)
+
+	return s.newEC2BooleanResponse(), nil
+}
+
+type xmlVolumeModification struct {
+	VolumeID          string `xml:"volumeId"`
+	ModificationState string `xml:"modificationState"`
+	OriginalSize      int32  `xml:"originalSize"`
+	TargetSize        int32  `xml:"targetSize"`
+	StartTime         string `xml:"startTime"`
+}
+
+func (v *volume) modificationToXML() xmlVolumeModification {
+	return xmlVolumeModification{
+		VolumeID:          v.id,
+		ModificationState: v.modification.state,
+		OriginalSize:      v.modification.originalSize,
+		TargetSize:        v.modification.targetSize,
+		StartTime:         v.modification.startTime,
+	}
+}
+
+type modifyVolumeResponse struct {
+	XMLName            xml.Name              `xml:"ModifyVolumeResponse"`
+	Namespace          string                `xml:"xmlns,attr"`
+	RequestID          string                `xml:"requestId"`
+	VolumeModification xmlVolumeModification `xml:"volumeModification"`
+}
+
+// modifyVolume only supports size increases. The volume is
+// immediately resized but, like the real API, the modification
+// is returned as modifying then as optimizing and completed
+// during the next calls to DescribeVolumesModifications.
+func (s *Server) modifyVolume(params ec2Params) (interface{}, *apiError) {
+	volumeID := params.get("VolumeId")
+	volume, ok := s.ec2.volumes[volumeID]
+
+	if !ok {
+		return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+	}
+
+	if volume.modification != nil &&
+		volume.modification.state != volumeModificationStateCompleted {
+
+		return nil, newAPIError(
+			"IncorrectModificationState",
+			"Cannot modify volume '%s' as it is currently in the '%s' state.",
+			volumeID,
+			volume.modification.state,
+		)
+	}
+
+	size, apiErr := params.int32("Size", volume.size)
+
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if size < volume.size {
+		return nil, newAPIError("InvalidParameterValue", "New size cannot be smaller than existing size.")
+	}
+
+	if size == volume.size {
+		return nil, newAPIError("InvalidParameterValue", "Modification of volume failed: the requested modification is identical to the current volume configuration.")
+	}
+
+	volume.modification = &volumeModification{
+		originalSize: volume.size,
+		targetSize:   size,
+		state:        volumeModificationStateModifying,
+		startTime:    time.Now().UTC().Format(time.RFC3339),
+	}
+
+	XMLModification := volume.modificationToXML()
+
+	volume.size = size
+	volume.modification.state = volumeModificationStateOptimizing
+
+	return modifyVolumeResponse{
+		Namespace:          ec2XMLNamespace,
+		RequestID:          s.newRequestID(),
+		VolumeModification: XMLModification,
+	}, nil
+}
+
+type describeVolumesModificationsResponse struct {
+	XMLName             xml.Name                `xml:"DescribeVolumesModificationsResponse"`
+	Namespace           string                  `xml:"xmlns,attr"`
+	RequestID           string                  `xml:"requestId"`
+	VolumeModifications []xmlVolumeModification `xml:"volumeModificationSet>item"`
+}
+
+func (s *Server) describeVolumesModifications(params ec2Params) (interface{}, *apiError) {
+	volumeModifications := []xmlVolumeModification{}
+
+	for _, volumeID := range params.list("VolumeId") {
+		volume, ok := s.ec2.volumes[volumeID]
+
+		if !ok {
+			return nil, newAPIError("InvalidVolume.NotFound", "The volume '%s' does not exist.", volumeID)
+		}
+
+		if volume.modification == nil {
+			continue
+		}
+
+		volumeModifications = append(volumeModifications, volume.modificationToXML())
+
+		// The optimization ends once observed
+		volume.modification.state = volumeModificationStateCompleted
+	}
+
+	return describeVolumesModificationsResponse{
+		Namespace:           ec2XMLNamespace,
+		RequestID:           s.newRequestID(),
+		VolumeModifications: volumeModifications,
+	}, nil
+}
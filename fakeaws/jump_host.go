@@ -0,0 +1,221 @@
+package fakeaws
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// JumpHost represents an SSH server that could be used as a jump
+// host (like "ssh -J") to reach the instances running in the fake
+// backend. Only the "direct-tcpip" channels are supported.
+//
+// Addresses that don't match the public IP address of a running
+// instance are dialed directly (to chain several jump hosts).
+type JumpHost struct {
+	server        *Server
+	listener      net.Listener
+	loginUser     string
+	authorizedKey ssh.PublicKey
+	hostKeySigner ssh.Signer
+
+	mu                 sync.Mutex
+	forwardedAddresses []string
+}
+
+// NewJumpHost constructs and starts a new jump host that accepts
+// the passed user authenticated with the passed public key.
+// Close must be called once the jump host is no longer used.
+func (s *Server) NewJumpHost(
+	loginUser string,
+	authorizedKey ssh.PublicKey,
+) *JumpHost {
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		panic(err)
+	}
+
+	hostKeySigner, err := ssh.NewSignerFromKey(hostKey)
+
+	if err != nil {
+		panic(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		panic(err)
+	}
+
+	j := &JumpHost{
+		server:        s,
+		listener:      listener,
+		loginUser:     loginUser,
+		authorizedKey: authorizedKey,
+		hostKeySigner: hostKeySigner,
+	}
+
+	go j.acceptConns()
+
+	return j
+}
+
+// Close shuts down the jump host.
+func (j *JumpHost) Close() {
+	j.listener.Close()
+}
+
+// Address returns the address ("host:port") of the jump host.
+func (j *JumpHost) Address() string {
+	return j.listener.Addr().String()
+}
+
+// HostKey returns the public key of the
+// jump host in authorized keys format.
+func (j *JumpHost) HostKey() string {
+	return strings.TrimSuffix(
+		string(ssh.MarshalAuthorizedKey(j.hostKeySigner.PublicKey())),
+		"\n",
+	)
+}
+
+// ForwardedAddresses returns the addresses
+// reached through the jump host, in order.
+func (j *JumpHost) ForwardedAddresses() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return append([]string{}, j.forwardedAddresses...)
+}
+
+func (j *JumpHost) acceptConns() {
+	for {
+		conn, err := j.listener.Accept()
+
+		if err != nil { // Listener closed
+			return
+		}
+
+		go j.serveConn(conn)
+	}
+}
+
+func (j *JumpHost) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(
+			connMetadata ssh.ConnMetadata,
+			publicKey ssh.PublicKey,
+		) (*ssh.Permissions, error) {
+
+			if connMetadata.User() != j.loginUser {
+				return nil, fmt.Errorf("unknown user %q", connMetadata.User())
+			}
+
+			if !bytes.Equal(j.authorizedKey.Marshal(), publicKey.Marshal()) {
+				return nil, errors.New("unauthorized public key")
+			}
+
+			return &ssh.Permissions{}, nil
+		},
+	}
+
+	config.AddHostKey(j.hostKeySigner)
+
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		go j.forwardChannel(newChannel)
+	}
+}
+
+func (j *JumpHost) forwardChannel(newChannel ssh.NewChannel) {
+	var forwardPayload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &forwardPayload); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, "invalid payload")
+		return
+	}
+
+	address := net.JoinHostPort(
+		forwardPayload.Host,
+		fmt.Sprint(forwardPayload.Port),
+	)
+
+	j.server.mu.Lock()
+	instance := j.server.ec2.instanceByPublicIPAddress(forwardPayload.Host)
+	j.server.mu.Unlock()
+
+	var conn net.Conn
+	var err error
+
+	if instance != nil {
+		conn, err = j.server.Dialer().DialContext(context.Background(), "tcp", address)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.Dial("tcp", address)
+	}
+
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	defer conn.Close()
+
+	channel, requests, err := newChannel.Accept()
+
+	if err != nil {
+		return
+	}
+
+	defer channel.Close()
+
+	go ssh.DiscardRequests(requests)
+
+	j.mu.Lock()
+	j.forwardedAddresses = append(j.forwardedAddresses, address)
+	j.mu.Unlock()
+
+	copyDone := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(conn, channel)
+		copyDone <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(channel, conn)
+		copyDone <- struct{}{}
+	}()
+
+	<-copyDone
+}
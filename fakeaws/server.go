@@ -0,0 +1,257 @@
+// Package fakeaws implements an in-memory emulation of the subset of
+// the EC2 and DynamoDB APIs used by the AWS cloud provider.
+//
+// The emulated APIs are served over a local HTTP endpoint that the
+// AWS SDK could be pointed at through the aws.Config returned by
+// Server.Config. Instances could be reached over SSH through the
+// dialer returned by Server.Dialer.
+//
+// This package is intended to be used in tests only.
+package fakeaws
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// Region represents the region returned in the SDK config.
+	Region = "us-east-1"
+
+	// AvailabilityZone represents the availability zone
+	// used when no one is passed during subnet creation.
+	AvailabilityZone = Region + "a"
+)
+
+// ResourceCounts represents the number of
+// resources currently present in the fake backend.
+type ResourceCounts struct {
+	VPCs              int
+	Subnets           int
+	InternetGateways  int
+	RouteTables       int
+	NATGateways       int
+	VPCEndpoints      int
+	SecurityGroups    int
+	NetworkInterfaces int
+	ElasticIPs        int
+	KeyPairs          int
+	RunningInstances  int
+	StoppedInstances  int
+	// HibernatedInstances is a subset of StoppedInstances
+	HibernatedInstances int
+	Volumes             int
+	Snapshots           int
+	// SpotInstanceRequests only counts the
+	// requests that are not cancelled or closed
+	SpotInstanceRequests int
+	DynamoDBTables       int
+}
+
+// Server represents the fake AWS backend.
+type Server struct {
+	httpServer  *httptest.Server
+	sshListener net.Listener
+
+	mu             sync.Mutex
+	ec2            *ec2State
+	dynamoDB       *dynamoDBState
+	injectedErrors map[string][]string
+	lastID         int
+	hostKeySigner  ssh.Signer
+
+	// impersonatorHostKeySigner is presented instead
+	// of hostKeySigner by the instance SSH servers
+	// once ImpersonateInstanceSSHServers is called
+	impersonatorHostKeySigner ssh.Signer
+}
+
+// NewServer constructs and starts a new fake AWS backend.
+// Close must be called once the server is no longer used.
+func NewServer() *Server {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		panic(err)
+	}
+
+	hostKeySigner, err := ssh.NewSignerFromKey(hostKey)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		ec2:            newEC2State(),
+		dynamoDB:       newDynamoDBState(),
+		injectedErrors: map[string][]string{},
+		hostKeySigner:  hostKeySigner,
+	}
+
+	s.sshListener, err = net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		panic(err)
+	}
+
+	go s.acceptSSHConns()
+
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.httpServer.Close()
+	s.sshListener.Close()
+}
+
+// URL returns the base URL of the server.
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// Config returns an AWS SDK config that points to the server.
+//
+// Retries are disabled to make injected errors deterministic.
+func (s *Server) Config() aws.Config {
+	return aws.Config{
+		Region: Region,
+		Credentials: credentials.NewStaticCredentialsProvider(
+			"AKIAFAKEAWSBACKEND00",
+			"fake-secret-access-key",
+			"",
+		),
+		EndpointResolverWithOptions: aws.EndpointResolverWithOptionsFunc(
+			func(service, region string, options ...interface{}) (aws.Endpoint, error) {
+				return aws.Endpoint{
+					URL:           s.httpServer.URL,
+					SigningRegion: region,
+				}, nil
+			},
+		),
+		Retryer: func() aws.Retryer {
+			return aws.NopRetryer{}
+		},
+	}
+}
+
+// FailNext makes the next call to the passed
+// API action (like "RunInstances" or "PutItem")
+// fail with the passed AWS error code.
+//
+// Calling FailNext multiple times for the same action
+// makes the next calls fail in order.
+func (s *Server) FailNext(action string, errorCode string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.injectedErrors[action] = append(s.injectedErrors[action], errorCode)
+}
+
+// ResourceCounts returns the number of
+// resources currently present in the server.
+func (s *Server) ResourceCounts() ResourceCounts {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	activeNATGateways := 0
+	for _, NATGateway := range s.ec2.natGateways {
+		if NATGateway.state != natGatewayStateDeleted {
+			activeNATGateways++
+		}
+	}
+
+	runningInstances := 0
+	stoppedInstances := 0
+	hibernatedInstances := 0
+	for _, instance := range s.ec2.instances {
+		switch instance.state {
+		case instanceStateStopped:
+			stoppedInstances++
+
+			if instance.hibernated {
+				hibernatedInstances++
+			}
+		case instanceStateTerminated:
+		default:
+			runningInstances++
+		}
+	}
+
+	activeSpotInstanceRequests := 0
+	for _, spotRequest := range s.ec2.spotInstanceRequests {
+		if spotRequest.state != spotInstanceRequestStateCancelled &&
+			spotRequest.state != spotInstanceRequestStateClosed {
+
+			activeSpotInstanceRequests++
+		}
+	}
+
+	return ResourceCounts{
+		VPCs:                 len(s.ec2.vpcs),
+		Subnets:              len(s.ec2.subnets),
+		InternetGateways:     len(s.ec2.internetGateways),
+		RouteTables:          len(s.ec2.routeTables),
+		NATGateways:          activeNATGateways,
+		VPCEndpoints:         len(s.ec2.vpcEndpoints),
+		SecurityGroups:       len(s.ec2.securityGroups),
+		NetworkInterfaces:    len(s.ec2.networkInterfaces),
+		ElasticIPs:           len(s.ec2.elasticIPs),
+		KeyPairs:             len(s.ec2.keyPairs),
+		RunningInstances:     runningInstances,
+		StoppedInstances:     stoppedInstances,
+		HibernatedInstances:  hibernatedInstances,
+		Volumes:              len(s.ec2.volumes),
+		Snapshots:            len(s.ec2.snapshots),
+		SpotInstanceRequests: activeSpotInstanceRequests,
+		DynamoDBTables:       len(s.dynamoDB.tables),
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target := r.Header.Get("X-Amz-Target"); strings.HasPrefix(target, dynamoDBTargetPrefix) {
+		s.serveDynamoDB(w, r, strings.TrimPrefix(target, dynamoDBTargetPrefix))
+		return
+	}
+
+	s.serveEC2(w, r)
+}
+
+// popInjectedError must be called with the lock held.
+func (s *Server) popInjectedError(action string) string {
+	errorCodes := s.injectedErrors[action]
+
+	if len(errorCodes) == 0 {
+		return ""
+	}
+
+	s.injectedErrors[action] = errorCodes[1:]
+	return errorCodes[0]
+}
+
+// newID must be called with the lock held.
+func (s *Server) newID(prefix string) string {
+	s.lastID++
+	return fmt.Sprintf("%s-%017x", prefix, s.lastID)
+}
+
+// newRequestID must be called with the lock held.
+func (s *Server) newRequestID() string {
+	s.lastID++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012x", s.lastID)
+}
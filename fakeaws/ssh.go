@@ -0,0 +1,355 @@
+package fakeaws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	agentConfig "github.com/eleven-sh/agent/config"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	instanceSSHPort         = 22
+	instanceLoginUser       = "ubuntu"
+	instanceInitResultsPath = "/tmp/eleven-init-results"
+)
+
+// Dialer represents a dialer that connects to the
+// SSH server of the instances running in the fake backend.
+//
+// Dialing an address that doesn't match the public IP address
+// of a running instance or a port other than the instance SSH port
+// and the agent SSH port fails with a "connection refused" error.
+type Dialer struct {
+	server *Server
+}
+
+// Dialer returns a dialer that could be used to
+// reach the instances running in the server over SSH.
+func (s *Server) Dialer() *Dialer {
+	return &Dialer{
+		server: s,
+	}
+}
+
+// DialContext connects to the SSH server of the
+// instance reachable at the passed address.
+func (d *Dialer) DialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+
+	host, port, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d.server.mu.Lock()
+	instance := d.server.ec2.instanceByPublicIPAddress(host)
+	d.server.mu.Unlock()
+
+	if instance == nil {
+		return nil, &net.OpError{
+			Op:  "dial",
+			Net: network,
+			Err: errors.New("connection refused"),
+		}
+	}
+
+	return d.server.dialInstance(ctx, network, instance, port)
+}
+
+// PortForwarder represents a port forwarder that reaches the
+// instances running in the fake backend like SSM Session Manager:
+// by instance ID, without public IP address.
+//
+// Dialing an instance that has no instance profile or no route
+// to the SSM endpoints (NAT gateway, internet gateway with a
+// public IP or SSM VPC endpoints) fails with a "TargetNotConnected" error.
+type PortForwarder struct {
+	server *Server
+}
+
+// PortForwarder returns a port forwarder that could be
+// used to reach the instances running in the server via SSM.
+func (s *Server) PortForwarder() *PortForwarder {
+	return &PortForwarder{
+		server: s,
+	}
+}
+
+// DialInstancePort connects to the SSH server
+// of the instance with the passed ID.
+func (p *PortForwarder) DialInstancePort(
+	ctx context.Context,
+	instanceID string,
+	port string,
+) (net.Conn, error) {
+
+	p.server.mu.Lock()
+	instance, ok := p.server.ec2.instances[instanceID]
+	canReachSessionManager := ok && instance.state == instanceStateRunning &&
+		p.server.ec2.canReachSessionManager(instance)
+	p.server.mu.Unlock()
+
+	if !canReachSessionManager {
+		return nil, fmt.Errorf("TargetNotConnected: %s is not connected", instanceID)
+	}
+
+	return p.server.dialInstance(ctx, "tcp", instance, port)
+}
+
+// dialInstance connects to the SSH server
+// of the instance on the passed port.
+func (s *Server) dialInstance(
+	ctx context.Context,
+	network string,
+	instance *instance,
+	port string,
+) (net.Conn, error) {
+
+	if port != strconv.Itoa(instanceSSHPort) &&
+		port != agentConfig.SSHServerListenPort {
+
+		return nil, &net.OpError{
+			Op:  "dial",
+			Net: network,
+			Err: errors.New("connection refused"),
+		}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.sshListener.Addr().String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The SSH server needs to know which instance is dialed
+	_, err = fmt.Fprintf(conn, "%s\n", instance.id)
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// ImpersonateInstanceSSHServers makes the SSH server of the
+// instances present a host key that doesn't match the one printed
+// in their console output (like during a man-in-the-middle attack).
+func (s *Server) ImpersonateInstanceSSHServers() {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+
+	if err != nil {
+		panic(err)
+	}
+
+	impersonatorHostKeySigner, err := ssh.NewSignerFromKey(hostKey)
+
+	if err != nil {
+		panic(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.impersonatorHostKeySigner = impersonatorHostKeySigner
+}
+
+// InstanceSSHCommands returns the commands run on
+// the instance via its SSH server, in order.
+func (s *Server) InstanceSSHCommands(instanceID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok {
+		return nil
+	}
+
+	return append([]string{}, instance.sshCommands...)
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (b bufferedConn) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+func (s *Server) acceptSSHConns() {
+	for {
+		conn, err := s.sshListener.Accept()
+
+		if err != nil { // Listener closed
+			return
+		}
+
+		go s.serveSSHConn(conn)
+	}
+}
+
+func (s *Server) serveSSHConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	instanceID, err := reader.ReadString('\n')
+
+	if err != nil {
+		return
+	}
+
+	instanceID = strings.TrimSuffix(instanceID, "\n")
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(
+			connMetadata ssh.ConnMetadata,
+			publicKey ssh.PublicKey,
+		) (*ssh.Permissions, error) {
+
+			if connMetadata.User() != instanceLoginUser {
+				return nil, fmt.Errorf("unknown user %q", connMetadata.User())
+			}
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			instance, ok := s.ec2.instances[instanceID]
+
+			if !ok {
+				return nil, fmt.Errorf("unknown instance %q", instanceID)
+			}
+
+			for _, keyPair := range s.ec2.keyPairs {
+				if keyPair.name == instance.keyName &&
+					bytes.Equal(keyPair.publicKey.Marshal(), publicKey.Marshal()) {
+
+					return &ssh.Permissions{}, nil
+				}
+			}
+
+			return nil, errors.New("unauthorized public key")
+		},
+	}
+
+	s.mu.Lock()
+	hostKeySigner := s.hostKeySigner
+
+	if s.impersonatorHostKeySigner != nil {
+		hostKeySigner = s.impersonatorHostKeySigner
+	}
+	s.mu.Unlock()
+
+	config.AddHostKey(hostKeySigner)
+
+	_, chans, reqs, err := ssh.NewServerConn(
+		bufferedConn{
+			Conn:   conn,
+			reader: reader,
+		},
+		config,
+	)
+
+	if err != nil {
+		return
+	}
+
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+
+		if err != nil {
+			continue
+		}
+
+		go s.serveSSHSession(instanceID, channel, requests)
+	}
+}
+
+func (s *Server) serveSSHSession(
+	instanceID string,
+	channel ssh.Channel,
+	requests <-chan *ssh.Request,
+) {
+
+	defer channel.Close()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+
+			continue
+		}
+
+		var execPayload struct {
+			Command string
+		}
+
+		if err := ssh.Unmarshal(req.Payload, &execPayload); err != nil {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		_ = req.Reply(true, nil)
+
+		_, _ = channel.Write([]byte(s.runSSHCommand(instanceID, execPayload.Command)))
+
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct {
+			Status uint32
+		}{0}))
+
+		return
+	}
+}
+
+// runSSHCommand returns the output of the passed command.
+// Only the retrieval of the init script results is emulated.
+// Other commands succeed without output.
+func (s *Server) runSSHCommand(instanceID string, command string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, ok := s.ec2.instances[instanceID]
+
+	if !ok {
+		return ""
+	}
+
+	instance.sshCommands = append(instance.sshCommands, command)
+
+	if command != "cat "+instanceInitResultsPath {
+		return ""
+	}
+
+	initResults, _ := json.Marshal(map[string]string{
+		"exit_code":       "0",
+		"ssh_host_keys":   instance.agentHostKey,
+		"cloud_init_logs": "",
+	})
+
+	return string(initResults)
+}
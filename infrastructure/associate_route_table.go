@@ -6,14 +6,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type AssociateRouteTableAPIClient interface {
+	AssociateRouteTable(context.Context, *ec2.AssociateRouteTableInput, ...func(*ec2.Options)) (*ec2.AssociateRouteTableOutput, error)
+}
+
 func AssociateRouteTable(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client AssociateRouteTableAPIClient,
 	subnetID string,
 	routeTableID string,
 ) error {
 
 	_, err := ec2Client.AssociateRouteTable(
-		context.TODO(),
+		ctx,
 		&ec2.AssociateRouteTableInput{
 			RouteTableId: &routeTableID,
 			SubnetId:     &subnetID,
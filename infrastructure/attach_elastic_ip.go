@@ -6,14 +6,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type AttachElasticIPToInstanceAPIClient interface {
+	AssociateAddress(context.Context, *ec2.AssociateAddressInput, ...func(*ec2.Options)) (*ec2.AssociateAddressOutput, error)
+}
+
 func AttachElasticIPToInstance(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client AttachElasticIPToInstanceAPIClient,
 	elasticIPId string,
 	instanceId string,
 ) (string, error) {
 
 	attachElasticIPResp, err := ec2Client.AssociateAddress(
-		context.TODO(),
+		ctx,
 		&ec2.AssociateAddressInput{
 			AllocationId: &elasticIPId,
 			InstanceId:   &instanceId,
@@ -6,14 +6,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type AttachInternetGatewayToVPCAPIClient interface {
+	AttachInternetGateway(context.Context, *ec2.AttachInternetGatewayInput, ...func(*ec2.Options)) (*ec2.AttachInternetGatewayOutput, error)
+}
+
 func AttachInternetGatewayToVPC(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client AttachInternetGatewayToVPCAPIClient,
 	internetGatewayId string,
 	VPCID string,
 ) error {
 
 	_, err := ec2Client.AttachInternetGateway(
-		context.TODO(),
+		ctx,
 		&ec2.AttachInternetGatewayInput{
 			InternetGatewayId: &internetGatewayId,
 			VpcId:             &VPCID,
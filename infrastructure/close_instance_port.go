@@ -2,30 +2,41 @@ package infrastructure
 
 import (
 	"context"
-	"strconv"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
+type CloseInstancePortAPIClient interface {
+	RevokeSecurityGroupIngress(context.Context, *ec2.RevokeSecurityGroupIngressInput, ...func(*ec2.Options)) (*ec2.RevokeSecurityGroupIngressOutput, error)
+}
+
 func CloseInstancePort(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CloseInstancePortAPIClient,
 	securityGroupID string,
-	portToClose string,
+	portToClose PortSpec,
 ) error {
 
-	portToCloseAsInt, _ := strconv.Atoi(portToClose)
-
-	_, err := ec2Client.RevokeSecurityGroupIngress(
-		context.TODO(),
+	revokeSecurityGroupIngressResp, err := ec2Client.RevokeSecurityGroupIngress(
+		ctx,
 		&ec2.RevokeSecurityGroupIngressInput{
-			CidrIp:     aws.String("0.0.0.0/0"),
-			FromPort:   aws.Int32(int32(portToCloseAsInt)),
-			ToPort:     aws.Int32(int32(portToCloseAsInt)),
-			GroupId:    &securityGroupID,
-			IpProtocol: aws.String("tcp"),
+			GroupId: &securityGroupID,
+			IpPermissions: []types.IpPermission{
+				portToClose.IPPermission(),
+			},
 		},
 	)
 
-	return err
+	if err != nil {
+		return convertIngressRuleError(err)
+	}
+
+	// Unknown rules may be returned
+	// instead of an error
+	if len(revokeSecurityGroupIngressResp.UnknownIpPermissions) > 0 {
+		return ErrIngressRuleNotFound
+	}
+
+	return nil
 }
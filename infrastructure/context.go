@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"context"
+	"time"
+)
+
+// uncancelableContext is a context that
+// is never canceled but that keeps the
+// values of its parent.
+type uncancelableContext struct {
+	parent context.Context
+}
+
+func (uncancelableContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (uncancelableContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (uncancelableContext) Err() error {
+	return nil
+}
+
+func (u uncancelableContext) Value(key interface{}) interface{} {
+	return u.parent.Value(key)
+}
+
+// withoutCancel returns a context that is not canceled
+// when the passed one is. Used to roll back the resources
+// created before a cancellation that would otherwise be
+// left behind without being recorded anywhere.
+func withoutCancel(ctx context.Context) context.Context {
+	return uncancelableContext{
+		parent: ctx,
+	}
+}
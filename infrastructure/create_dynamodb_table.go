@@ -3,7 +3,6 @@ package infrastructure
 import (
 	"context"
 	"errors"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -16,12 +15,22 @@ var (
 	ErrElevenConfigTableAlreadyExists = errors.New("ErrElevenConfigTableAlreadyExists")
 )
 
+type CreateDynamoDBTableForElevenConfigAPIClient interface {
+	dynamodb.DescribeTableAPIClient
+
+	CreateTable(context.Context, *dynamodb.CreateTableInput, ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+}
+
 func CreateDynamoDBTableForElevenConfig(
-	dynamoDBClient *dynamodb.Client,
+	ctx context.Context,
+	dynamoDBClient CreateDynamoDBTableForElevenConfigAPIClient,
+	waitOpts WaitOpts,
 ) error {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	_, err := dynamoDBClient.CreateTable(
-		context.TODO(),
+		ctx,
 
 		&dynamodb.CreateTableInput{
 			AttributeDefinitions: []types.AttributeDefinition{
@@ -54,10 +63,13 @@ func CreateDynamoDBTableForElevenConfig(
 		return err
 	}
 
-	existsWaiter := dynamodb.NewTableExistsWaiter(dynamoDBClient)
-	maxWaitTime := 5 * time.Minute
+	existsWaiter := dynamodb.NewTableExistsWaiter(dynamoDBClient, func(o *dynamodb.TableExistsWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	return existsWaiter.Wait(context.TODO(), &dynamodb.DescribeTableInput{
+	return existsWaiter.Wait(ctx, &dynamodb.DescribeTableInput{
 		TableName: aws.String(DynamoDBElevenConfigTableName),
 	}, maxWaitTime)
 }
@@ -15,13 +15,18 @@ type ElasticIP struct {
 	AssociationID        string `json:"association_id"`
 }
 
+type CreateElasticIPAPIClient interface {
+	AllocateAddress(context.Context, *ec2.AllocateAddressInput, ...func(*ec2.Options)) (*ec2.AllocateAddressOutput, error)
+}
+
 func CreateElasticIP(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateElasticIPAPIClient,
 	name string,
 ) (returnedElasticIP *ElasticIP, returnedError error) {
 
 	createElasticIPResp, err := ec2Client.AllocateAddress(
-		context.TODO(),
+		ctx,
 		&ec2.AllocateAddressInput{
 			Domain: types.DomainTypeVpc,
 			TagSpecifications: []types.TagSpecification{{
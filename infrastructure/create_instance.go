@@ -6,7 +6,6 @@ import (
 	"encoding/base64"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -23,11 +22,38 @@ var (
 	instanceInitScript string
 )
 
+const (
+	InstancePowerStateRunning    = "running"
+	InstancePowerStateStopped    = "stopped"
+	InstancePowerStateHibernated = "hibernated"
+)
+
 type InstanceVolume struct {
 	ID           string `json:"id"`
 	DeviceName   string `json:"device_name"`
 	SnapshotID   string `json:"snapshot_id"`
 	IsRootVolume bool   `json:"is_root_volume"`
+
+	// Not set for the volumes created
+	// before the size was recorded
+	SizeGb int32 `json:"size_gb,omitempty"`
+}
+
+// InstanceRootVolumeOpts represents the options
+// used to create the root volume of an instance.
+type InstanceRootVolumeOpts struct {
+	SizeGb int32
+
+	// Type, IOPS and ThroughputMiBps are
+	// left to the AWS defaults if not set
+	Type            string
+	IOPS            int32
+	ThroughputMiBps int32
+
+	// The volume is encrypted with the default EBS key if
+	// KMSKeyID is not set and Encrypted is true
+	Encrypted bool
+	KMSKeyID  string
 }
 
 type Instance struct {
@@ -36,18 +62,74 @@ type Instance struct {
 	TmpPublicIPAddress string                     `json:"tmp_public_ip_address"`
 	Volumes            []InstanceVolume           `json:"volumes"`
 	InitScriptResults  *InitInstanceScriptResults `json:"init_script_results"`
+	SSHHostKeys        []string                   `json:"ssh_host_keys,omitempty"`
+
+	// Instances created before the power
+	// state was recorded are running
+	PowerState string `json:"power_state,omitempty"`
+
+	HibernationEnabled bool `json:"hibernation_enabled,omitempty"`
+
+	// Only set for the spot instances
+	SpotInstanceRequest *SpotInstanceRequest `json:"spot_instance_request,omitempty"`
+}
+
+// RootVolume returns the root volume of
+// the instance or nil if there is none.
+func (i *Instance) RootVolume() *InstanceVolume {
+	for volumeIndex := range i.Volumes {
+		if i.Volumes[volumeIndex].IsRootVolume {
+			return &i.Volumes[volumeIndex]
+		}
+	}
+
+	return nil
+}
+
+// InstanceSpotOpts represents the options used
+// to launch an instance as a persistent spot instance.
+type InstanceSpotOpts struct {
+	Enabled bool
+
+	// MaxPrice is the maximum hourly price in USD.
+	// Default to the on-demand price if not set.
+	MaxPrice string
+}
+
+// CreateInstanceOpts represents the options
+// used to create an instance.
+type CreateInstanceOpts struct {
+	// Only set for the instances reached via SSM
+	InstanceProfileName string
+
+	RootVolume        InstanceRootVolumeOpts
+	EnableHibernation bool
+	Spot              InstanceSpotOpts
+}
+
+type CreateInstanceAPIClient interface {
+	ec2.DescribeInstancesAPIClient
+	TerminateInstanceAPIClient
+	CancelSpotInstanceRequestAPIClient
+
+	RunInstances(context.Context, *ec2.RunInstancesInput, ...func(*ec2.Options)) (*ec2.RunInstancesOutput, error)
 }
 
 func CreateInstance(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateInstanceAPIClient,
+	waitOpts WaitOpts,
 	name string,
 	AMIID string,
 	rootDeviceName string,
 	instanceType string,
 	networkInterfaceID string,
 	keyName string,
+	opts CreateInstanceOpts,
 ) (returnedInstance *Instance, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	updatedInstanceInitScript := strings.ReplaceAll(
 		instanceInitScript,
 		"${ELEVEN_CONFIG_DIR}",
@@ -64,7 +146,79 @@ func CreateInstance(
 		[]byte(updatedInstanceInitScript),
 	)
 
-	runInstancesResp, err := ec2Client.RunInstances(context.TODO(), &ec2.RunInstancesInput{
+	// Used by the instances reached via SSM
+	var instanceProfile *types.IamInstanceProfileSpecification
+
+	if len(opts.InstanceProfileName) > 0 {
+		instanceProfile = &types.IamInstanceProfileSpecification{
+			Name: aws.String(opts.InstanceProfileName),
+		}
+	}
+
+	rootVolume := &types.EbsBlockDevice{
+		VolumeSize: aws.Int32(opts.RootVolume.SizeGb),
+	}
+
+	if len(opts.RootVolume.Type) > 0 {
+		rootVolume.VolumeType = types.VolumeType(opts.RootVolume.Type)
+	}
+
+	if opts.RootVolume.IOPS > 0 {
+		rootVolume.Iops = aws.Int32(opts.RootVolume.IOPS)
+	}
+
+	if opts.RootVolume.ThroughputMiBps > 0 {
+		rootVolume.Throughput = aws.Int32(opts.RootVolume.ThroughputMiBps)
+	}
+
+	if len(opts.RootVolume.KMSKeyID) > 0 {
+		rootVolume.KmsKeyId = aws.String(opts.RootVolume.KMSKeyID)
+	}
+
+	var hibernationOptions *types.HibernationOptionsRequest
+
+	// The content of the RAM is saved to the
+	// root volume that needs to be encrypted
+	if opts.EnableHibernation {
+		hibernationOptions = &types.HibernationOptionsRequest{
+			Configured: aws.Bool(true),
+		}
+	}
+
+	if opts.RootVolume.Encrypted || len(opts.RootVolume.KMSKeyID) > 0 ||
+		opts.EnableHibernation {
+
+		rootVolume.Encrypted = aws.Bool(true)
+	}
+
+	var instanceMarketOptions *types.InstanceMarketOptionsRequest
+
+	// Persistent spot instances are stopped (instead of terminated)
+	// when interrupted and started again once capacity is available
+	if opts.Spot.Enabled {
+		interruptionBehavior := types.InstanceInterruptionBehaviorStop
+
+		if opts.EnableHibernation {
+			interruptionBehavior = types.InstanceInterruptionBehaviorHibernate
+		}
+
+		var maxPrice *string
+
+		if len(opts.Spot.MaxPrice) > 0 {
+			maxPrice = aws.String(opts.Spot.MaxPrice)
+		}
+
+		instanceMarketOptions = &types.InstanceMarketOptionsRequest{
+			MarketType: types.MarketTypeSpot,
+			SpotOptions: &types.SpotMarketOptions{
+				SpotInstanceType:             types.SpotInstanceTypePersistent,
+				InstanceInterruptionBehavior: interruptionBehavior,
+				MaxPrice:                     maxPrice,
+			},
+		}
+	}
+
+	runInstancesResp, err := ec2Client.RunInstances(ctx, &ec2.RunInstancesInput{
 		ImageId:      &AMIID,
 		InstanceType: types.InstanceType(instanceType),
 		MinCount:     aws.Int32(1),
@@ -75,16 +229,17 @@ func CreateInstance(
 				NetworkInterfaceId: aws.String(networkInterfaceID),
 			},
 		},
-		KeyName:  &keyName,
-		UserData: &instanceInitScriptAsB64,
+		KeyName:            &keyName,
+		UserData:           &instanceInitScriptAsB64,
+		IamInstanceProfile: instanceProfile,
 		BlockDeviceMappings: []types.BlockDeviceMapping{
 			{
 				DeviceName: &rootDeviceName,
-				Ebs: &types.EbsBlockDevice{
-					VolumeSize: aws.Int32(InstanceRootDeviceSizeGb),
-				},
+				Ebs:        rootVolume,
 			},
 		},
+		HibernationOptions:    hibernationOptions,
+		InstanceMarketOptions: instanceMarketOptions,
 		TagSpecifications: []types.TagSpecification{{
 			ResourceType: types.ResourceTypeInstance,
 			Tags: []types.Tag{{
@@ -100,19 +255,31 @@ func CreateInstance(
 	}
 
 	instanceID := *runInstancesResp.Instances[0].InstanceId
+	spotInstanceRequestID := aws.ToString(
+		runInstancesResp.Instances[0].SpotInstanceRequestId,
+	)
 
 	defer func() {
 		if returnedError == nil {
 			return
 		}
 
-		_ = TerminateInstance(ec2Client, instanceID)
+		// The persistent spot request would
+		// launch a new instance otherwise
+		if len(spotInstanceRequestID) > 0 {
+			_ = CancelSpotInstanceRequest(withoutCancel(ctx), ec2Client, spotInstanceRequestID)
+		}
+
+		_ = TerminateInstance(withoutCancel(ctx), ec2Client, waitOpts, instanceID)
 	}()
 
-	runningWaiter := ec2.NewInstanceRunningWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	runningWaiter := ec2.NewInstanceRunningWaiter(ec2Client, func(o *ec2.InstanceRunningWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	err = runningWaiter.Wait(context.TODO(), &ec2.DescribeInstancesInput{
+	err = runningWaiter.Wait(ctx, &ec2.DescribeInstancesInput{
 		InstanceIds: []string{
 			instanceID,
 		},
@@ -124,9 +291,10 @@ func CreateInstance(
 	}
 
 	/* Public IP / DNS are only available
-	when instance is running */
+	when instance is running (instances in
+	private subnets have no public IP) */
 
-	createdInstance, err := lookupInstance(ec2Client, instanceID)
+	createdInstance, err := lookupInstance(ctx, ec2Client, instanceID)
 
 	if err != nil {
 		returnedError = err
@@ -135,8 +303,17 @@ func CreateInstance(
 
 	returnedInstance = &Instance{
 		ID:                 *createdInstance.InstanceId,
-		TmpPublicIPAddress: *createdInstance.PublicIpAddress,
+		TmpPublicIPAddress: aws.ToString(createdInstance.PublicIpAddress),
 		Type:               string(createdInstance.InstanceType),
+		PowerState:         InstancePowerStateRunning,
+		HibernationEnabled: opts.EnableHibernation,
+	}
+
+	if len(spotInstanceRequestID) > 0 {
+		returnedInstance.SpotInstanceRequest = &SpotInstanceRequest{
+			ID:       spotInstanceRequestID,
+			MaxPrice: opts.Spot.MaxPrice,
+		}
 	}
 
 	var volumes []InstanceVolume
@@ -145,6 +322,7 @@ func CreateInstance(
 			ID:           *blockDevice.Ebs.VolumeId,
 			DeviceName:   *blockDevice.DeviceName,
 			IsRootVolume: true,
+			SizeGb:       opts.RootVolume.SizeGb,
 		})
 	}
 
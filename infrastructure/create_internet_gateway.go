@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -14,13 +13,24 @@ type InternetGateway struct {
 	IsAttachedToVPC bool   `json:"is_attached_to_vpc"`
 }
 
+type CreateInternetGatewayAPIClient interface {
+	ec2.DescribeInternetGatewaysAPIClient
+	RemoveInternetGatewayAPIClient
+
+	CreateInternetGateway(context.Context, *ec2.CreateInternetGatewayInput, ...func(*ec2.Options)) (*ec2.CreateInternetGatewayOutput, error)
+}
+
 func CreateInternetGateway(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateInternetGatewayAPIClient,
+	waitOpts WaitOpts,
 	name string,
 ) (returnedIG *InternetGateway, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	createInternetGatewayResp, err := ec2Client.CreateInternetGateway(
-		context.TODO(),
+		ctx,
 		&ec2.CreateInternetGatewayInput{
 			TagSpecifications: []types.TagSpecification{{
 				ResourceType: types.ResourceTypeInternetGateway,
@@ -43,16 +53,20 @@ func CreateInternetGateway(
 		}
 
 		_ = RemoveInternetGateway(
+			withoutCancel(ctx),
 			ec2Client,
 			*createInternetGatewayResp.InternetGateway.InternetGatewayId,
 		)
 	}()
 
-	existsWaiter := ec2.NewInternetGatewayExistsWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	existsWaiter := ec2.NewInternetGatewayExistsWaiter(ec2Client, func(o *ec2.InternetGatewayExistsWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	err = existsWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeInternetGatewaysInput{
 			InternetGatewayIds: []string{
 				*createInternetGatewayResp.InternetGateway.InternetGatewayId,
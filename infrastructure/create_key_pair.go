@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -14,13 +13,24 @@ type KeyPair struct {
 	PEMContent string `json:"pem_content"`
 }
 
+type CreateKeyPairAPIClient interface {
+	ec2.DescribeKeyPairsAPIClient
+	RemoveKeyPairAPIClient
+
+	CreateKeyPair(context.Context, *ec2.CreateKeyPairInput, ...func(*ec2.Options)) (*ec2.CreateKeyPairOutput, error)
+}
+
 func CreateKeyPair(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateKeyPairAPIClient,
+	waitOpts WaitOpts,
 	keyPairName string,
 ) (returnedKeyPair *KeyPair, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	createKeyPairResp, err := ec2Client.CreateKeyPair(
-		context.TODO(),
+		ctx,
 		&ec2.CreateKeyPairInput{
 			KeyName: &keyPairName,
 			KeyType: types.KeyTypeEd25519,
@@ -37,13 +47,16 @@ func CreateKeyPair(
 			return
 		}
 
-		_ = RemoveKeyPair(ec2Client, *createKeyPairResp.KeyPairId)
+		_ = RemoveKeyPair(withoutCancel(ctx), ec2Client, *createKeyPairResp.KeyPairId)
 	}()
 
-	existsWaiter := ec2.NewKeyPairExistsWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	existsWaiter := ec2.NewKeyPairExistsWaiter(ec2Client, func(o *ec2.KeyPairExistsWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	err = existsWaiter.Wait(context.TODO(), &ec2.DescribeKeyPairsInput{
+	err = existsWaiter.Wait(ctx, &ec2.DescribeKeyPairsInput{
 		KeyPairIds: []string{
 			*createKeyPairResp.KeyPairId,
 		},
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type NATGateway struct {
+	ID       string `json:"id"`
+	SubnetID string `json:"subnet_id"`
+}
+
+type CreateNATGatewayAPIClient interface {
+	RemoveNATGatewayAPIClient
+
+	CreateNatGateway(context.Context, *ec2.CreateNatGatewayInput, ...func(*ec2.Options)) (*ec2.CreateNatGatewayOutput, error)
+}
+
+// CreateNATGateway creates a public NAT gateway in the passed
+// (public) subnet and waits for it to be available.
+func CreateNATGateway(
+	ctx context.Context,
+	ec2Client CreateNATGatewayAPIClient,
+	waitOpts WaitOpts,
+	name string,
+	subnetID string,
+	elasticIPID string,
+) (returnedNATGateway *NATGateway, returnedError error) {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	createNATGatewayResp, err := ec2Client.CreateNatGateway(
+		ctx,
+		&ec2.CreateNatGatewayInput{
+			SubnetId:         &subnetID,
+			AllocationId:     &elasticIPID,
+			ConnectivityType: types.ConnectivityTypePublic,
+			TagSpecifications: []types.TagSpecification{{
+				ResourceType: types.ResourceTypeNatgateway,
+				Tags: []types.Tag{{
+					Key:   aws.String("Name"),
+					Value: &name,
+				}},
+			}},
+		},
+	)
+
+	if err != nil {
+		returnedError = err
+		return
+	}
+
+	NATGatewayID := *createNATGatewayResp.NatGateway.NatGatewayId
+
+	defer func() {
+		if returnedError == nil {
+			return
+		}
+
+		_ = RemoveNATGateway(withoutCancel(ctx), ec2Client, waitOpts, NATGatewayID)
+	}()
+
+	availableWaiter := ec2.NewNatGatewayAvailableWaiter(ec2Client, func(o *ec2.NatGatewayAvailableWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
+
+	err = availableWaiter.Wait(ctx, &ec2.DescribeNatGatewaysInput{
+		NatGatewayIds: []string{
+			NATGatewayID,
+		},
+	}, maxWaitTime)
+
+	if err != nil {
+		returnedError = err
+		return
+	}
+
+	returnedNATGateway = &NATGateway{
+		ID:       NATGatewayID,
+		SubnetID: subnetID,
+	}
+	return
+}
@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -10,23 +9,43 @@ import (
 )
 
 type NetworkInterface struct {
-	ID string `json:"id"`
+	ID          string `json:"id"`
+	IPv6Address string `json:"ipv6_address,omitempty"`
+}
+
+type CreateNetworkInterfaceAPIClient interface {
+	ec2.DescribeNetworkInterfacesAPIClient
+	RemoveNetworkInterfaceAPIClient
+
+	CreateNetworkInterface(context.Context, *ec2.CreateNetworkInterfaceInput, ...func(*ec2.Options)) (*ec2.CreateNetworkInterfaceOutput, error)
 }
 
 func CreateNetworkInterface(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateNetworkInterfaceAPIClient,
+	waitOpts WaitOpts,
 	name string,
 	description string,
 	subnetID string,
 	securityGroupIDs []string,
+	withIPv6 bool,
 ) (returnedNetworkInterface *NetworkInterface, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
+	var IPv6AddressCount *int32
+
+	if withIPv6 {
+		IPv6AddressCount = aws.Int32(1)
+	}
+
 	createNetworkInterfaceResp, err := ec2Client.CreateNetworkInterface(
-		context.TODO(),
+		ctx,
 		&ec2.CreateNetworkInterfaceInput{
-			SubnetId:    &subnetID,
-			Groups:      securityGroupIDs,
-			Description: &description,
+			SubnetId:         &subnetID,
+			Ipv6AddressCount: IPv6AddressCount,
+			Groups:           securityGroupIDs,
+			Description:      &description,
 			TagSpecifications: []types.TagSpecification{{
 				ResourceType: types.ResourceTypeNetworkInterface,
 				Tags: []types.Tag{{
@@ -48,16 +67,20 @@ func CreateNetworkInterface(
 		}
 
 		_ = RemoveNetworkInterface(
+			withoutCancel(ctx),
 			ec2Client,
 			*createNetworkInterfaceResp.NetworkInterface.NetworkInterfaceId,
 		)
 	}()
 
-	availableWaiter := ec2.NewNetworkInterfaceAvailableWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	availableWaiter := ec2.NewNetworkInterfaceAvailableWaiter(ec2Client, func(o *ec2.NetworkInterfaceAvailableWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	err = availableWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeNetworkInterfacesInput{
 			NetworkInterfaceIds: []string{
 				*createNetworkInterfaceResp.NetworkInterface.NetworkInterfaceId,
@@ -74,5 +97,10 @@ func CreateNetworkInterface(
 	returnedNetworkInterface = &NetworkInterface{
 		ID: *createNetworkInterfaceResp.NetworkInterface.NetworkInterfaceId,
 	}
+
+	for _, IPv6Address := range createNetworkInterfaceResp.NetworkInterface.Ipv6Addresses {
+		returnedNetworkInterface.IPv6Address = aws.ToString(IPv6Address.Ipv6Address)
+	}
+
 	return
 }
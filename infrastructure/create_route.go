@@ -2,6 +2,8 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -9,17 +11,55 @@ import (
 
 type Route struct{}
 
+// RouteTargetKind represents the kind of
+// resource that a route sends traffic to.
+type RouteTargetKind string
+
+const (
+	RouteTargetKindInternetGateway RouteTargetKind = "internet_gateway"
+	RouteTargetKindNATGateway      RouteTargetKind = "nat_gateway"
+)
+
+var ErrInvalidRouteTargetKind = errors.New("ErrInvalidRouteTargetKind")
+
+type CreateRouteAPIClient interface {
+	CreateRoute(context.Context, *ec2.CreateRouteInput, ...func(*ec2.Options)) (*ec2.CreateRouteOutput, error)
+}
+
 func CreateRoute(
-	ec2Client *ec2.Client,
-	internetGatewayID string,
+	ctx context.Context,
+	ec2Client CreateRouteAPIClient,
+	targetKind RouteTargetKind,
+	targetID string,
 	routeTableID string,
+	destinationCIDRBlock string,
 ) (returnedRoute *Route, returnedError error) {
 
-	_, err := ec2Client.CreateRoute(context.TODO(), &ec2.CreateRouteInput{
-		RouteTableId:         &routeTableID,
-		DestinationCidrBlock: aws.String("0.0.0.0/0"),
-		GatewayId:            &internetGatewayID,
-	})
+	createRouteInput := &ec2.CreateRouteInput{
+		RouteTableId: &routeTableID,
+	}
+
+	switch targetKind {
+	case RouteTargetKindInternetGateway:
+		createRouteInput.GatewayId = aws.String(targetID)
+	case RouteTargetKindNATGateway:
+		createRouteInput.NatGatewayId = aws.String(targetID)
+	default:
+		returnedError = fmt.Errorf(
+			"%w (\"%s\")",
+			ErrInvalidRouteTargetKind,
+			targetKind,
+		)
+		return
+	}
+
+	if isIPv6CIDR(destinationCIDRBlock) {
+		createRouteInput.DestinationIpv6CidrBlock = aws.String(destinationCIDRBlock)
+	} else {
+		createRouteInput.DestinationCidrBlock = aws.String(destinationCIDRBlock)
+	}
+
+	_, err := ec2Client.CreateRoute(ctx, createRouteInput)
 
 	if err != nil {
 		returnedError = err
@@ -13,14 +13,19 @@ type RouteTable struct {
 	IsAssociatedToSubnet bool   `json:"is_associated_to_subnet"`
 }
 
+type CreateRouteTableAPIClient interface {
+	CreateRouteTable(context.Context, *ec2.CreateRouteTableInput, ...func(*ec2.Options)) (*ec2.CreateRouteTableOutput, error)
+}
+
 func CreateRouteTable(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateRouteTableAPIClient,
 	name string,
 	VPCID string,
 ) (returnedRouteTable *RouteTable, returnedError error) {
 
 	createRouteTableResp, err := ec2Client.CreateRouteTable(
-		context.TODO(),
+		ctx,
 		&ec2.CreateRouteTableInput{
 			VpcId: &VPCID,
 			TagSpecifications: []types.TagSpecification{{
@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -13,16 +12,28 @@ type SecurityGroup struct {
 	ID string `json:"id"`
 }
 
+type CreateSecurityGroupAPIClient interface {
+	ec2.DescribeSecurityGroupsAPIClient
+	RemoveSecurityGroupAPIClient
+
+	CreateSecurityGroup(context.Context, *ec2.CreateSecurityGroupInput, ...func(*ec2.Options)) (*ec2.CreateSecurityGroupOutput, error)
+	AuthorizeSecurityGroupIngress(context.Context, *ec2.AuthorizeSecurityGroupIngressInput, ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
+}
+
 func CreateSecurityGroup(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateSecurityGroupAPIClient,
+	waitOpts WaitOpts,
 	name string,
 	description string,
 	VPCID string,
 	ingressPorts []types.IpPermission,
 ) (returnedSecurityGroup *SecurityGroup, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	createSecurityGroupResp, err := ec2Client.CreateSecurityGroup(
-		context.TODO(),
+		ctx,
 		&ec2.CreateSecurityGroupInput{
 			GroupName:   &name,
 			Description: &description,
@@ -47,14 +58,17 @@ func CreateSecurityGroup(
 			return
 		}
 
-		_ = RemoveSecurityGroup(ec2Client, *createSecurityGroupResp.GroupId)
+		_ = RemoveSecurityGroup(withoutCancel(ctx), ec2Client, *createSecurityGroupResp.GroupId)
 	}()
 
-	existsWaiter := ec2.NewSecurityGroupExistsWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	existsWaiter := ec2.NewSecurityGroupExistsWaiter(ec2Client, func(o *ec2.SecurityGroupExistsWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	err = existsWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeSecurityGroupsInput{
 			GroupIds: []string{
 				*createSecurityGroupResp.GroupId,
@@ -69,7 +83,7 @@ func CreateSecurityGroup(
 	}
 
 	_, err = ec2Client.AuthorizeSecurityGroupIngress(
-		context.TODO(),
+		ctx,
 		&ec2.AuthorizeSecurityGroupIngressInput{
 			GroupId:       createSecurityGroupResp.GroupId,
 			IpPermissions: ingressPorts,
@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -10,22 +9,59 @@ import (
 )
 
 type Subnet struct {
-	ID               string `json:"id"`
-	AvailabilityZone string `json:"availability_zone"`
+	ID                       string `json:"id"`
+	VPCID                    string `json:"vpc_id,omitempty"`
+	CIDRBlock                string `json:"cidr_block,omitempty"`
+	AvailabilityZone         string `json:"availability_zone"`
+	IPv6CIDRBlock            string `json:"ipv6_cidr_block,omitempty"`
+	IsAssociatedToRouteTable bool   `json:"is_associated_to_route_table"`
+	// Externally owned subnets are
+	// never removed by Eleven
+	IsExternallyOwned bool `json:"is_externally_owned"`
+}
+
+type CreateSubnetAPIClient interface {
+	ec2.DescribeSubnetsAPIClient
+	RemoveSubnetAPIClient
+
+	CreateSubnet(context.Context, *ec2.CreateSubnetInput, ...func(*ec2.Options)) (*ec2.CreateSubnetOutput, error)
+	ModifySubnetAttribute(context.Context, *ec2.ModifySubnetAttributeInput, ...func(*ec2.Options)) (*ec2.ModifySubnetAttributeOutput, error)
 }
 
 func CreateSubnet(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateSubnetAPIClient,
+	waitOpts WaitOpts,
 	name string,
 	cidrBlock string,
+	IPv6CIDRBlock string,
+	availabilityZone string,
 	VPCID string,
+	mapPublicIPOnLaunch bool,
 ) (returnedSubnet *Subnet, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
+	// Let AWS choose the availability zone if not set
+	var subnetAvailabilityZone *string
+
+	if len(availabilityZone) > 0 {
+		subnetAvailabilityZone = aws.String(availabilityZone)
+	}
+
+	var subnetIPv6CIDRBlock *string
+
+	if len(IPv6CIDRBlock) > 0 {
+		subnetIPv6CIDRBlock = aws.String(IPv6CIDRBlock)
+	}
+
 	createSubnetResp, err := ec2Client.CreateSubnet(
-		context.TODO(),
+		ctx,
 		&ec2.CreateSubnetInput{
-			CidrBlock: &cidrBlock,
-			VpcId:     &VPCID,
+			CidrBlock:        &cidrBlock,
+			Ipv6CidrBlock:    subnetIPv6CIDRBlock,
+			AvailabilityZone: subnetAvailabilityZone,
+			VpcId:            &VPCID,
 			TagSpecifications: []types.TagSpecification{{
 				ResourceType: types.ResourceTypeSubnet,
 				Tags: []types.Tag{{
@@ -46,13 +82,16 @@ func CreateSubnet(
 			return
 		}
 
-		_ = RemoveSubnet(ec2Client, *createSubnetResp.Subnet.SubnetId)
+		_ = RemoveSubnet(withoutCancel(ctx), ec2Client, *createSubnetResp.Subnet.SubnetId)
 	}()
 
-	availableWaiter := ec2.NewSubnetAvailableWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	availableWaiter := ec2.NewSubnetAvailableWaiter(ec2Client, func(o *ec2.SubnetAvailableWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	err = availableWaiter.Wait(context.TODO(), &ec2.DescribeSubnetsInput{
+	err = availableWaiter.Wait(ctx, &ec2.DescribeSubnetsInput{
 		SubnetIds: []string{
 			*createSubnetResp.Subnet.SubnetId,
 		},
@@ -63,24 +102,50 @@ func CreateSubnet(
 		return
 	}
 
-	_, err = ec2Client.ModifySubnetAttribute(
-		context.TODO(),
-		&ec2.ModifySubnetAttributeInput{
-			SubnetId: createSubnetResp.Subnet.SubnetId,
-			MapPublicIpOnLaunch: &types.AttributeBooleanValue{
-				Value: aws.Bool(true),
+	// Private subnets don't assign public IPs
+	if mapPublicIPOnLaunch {
+		_, err = ec2Client.ModifySubnetAttribute(
+			ctx,
+			&ec2.ModifySubnetAttributeInput{
+				SubnetId: createSubnetResp.Subnet.SubnetId,
+				MapPublicIpOnLaunch: &types.AttributeBooleanValue{
+					Value: aws.Bool(true),
+				},
 			},
-		},
-	)
+		)
 
-	if err != nil {
-		returnedError = err
-		return
+		if err != nil {
+			returnedError = err
+			return
+		}
+	}
+
+	/* From AWS docs:
+	   You can only modify one attribute at a time. */
+
+	if len(IPv6CIDRBlock) > 0 {
+		_, err = ec2Client.ModifySubnetAttribute(
+			ctx,
+			&ec2.ModifySubnetAttributeInput{
+				SubnetId: createSubnetResp.Subnet.SubnetId,
+				AssignIpv6AddressOnCreation: &types.AttributeBooleanValue{
+					Value: aws.Bool(true),
+				},
+			},
+		)
+
+		if err != nil {
+			returnedError = err
+			return
+		}
 	}
 
 	returnedSubnet = &Subnet{
 		AvailabilityZone: *createSubnetResp.Subnet.AvailabilityZone,
 		ID:               *createSubnetResp.Subnet.SubnetId,
+		VPCID:            VPCID,
+		CIDRBlock:        cidrBlock,
+		IPv6CIDRBlock:    IPv6CIDRBlock,
 	}
 	return
 }
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -9,20 +10,41 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
+var ErrVPCIPv6CIDRBlockNotAssociated = errors.New("ErrVPCIPv6CIDRBlockNotAssociated")
+
 type VPC struct {
-	ID string `json:"id"`
+	ID            string `json:"id"`
+	CIDRBlock     string `json:"cidr_block,omitempty"`
+	IPv6CIDRBlock string `json:"ipv6_cidr_block,omitempty"`
+	// Externally owned VPCs are
+	// never removed by Eleven
+	IsExternallyOwned bool `json:"is_externally_owned"`
+}
+
+type CreateVPCAPIClient interface {
+	ec2.DescribeVpcsAPIClient
+	RemoveVPCAPIClient
+
+	CreateVpc(context.Context, *ec2.CreateVpcInput, ...func(*ec2.Options)) (*ec2.CreateVpcOutput, error)
+	ModifyVpcAttribute(context.Context, *ec2.ModifyVpcAttributeInput, ...func(*ec2.Options)) (*ec2.ModifyVpcAttributeOutput, error)
 }
 
 func CreateVPC(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateVPCAPIClient,
+	waitOpts WaitOpts,
 	VPCName string,
 	CIDRBlock string,
+	withIPv6 bool,
 ) (returnedVPC *VPC, returnedError error) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	createVPCResp, err := ec2Client.CreateVpc(
-		context.TODO(),
+		ctx,
 		&ec2.CreateVpcInput{
-			CidrBlock: &CIDRBlock,
+			CidrBlock:                   &CIDRBlock,
+			AmazonProvidedIpv6CidrBlock: aws.Bool(withIPv6),
 			TagSpecifications: []types.TagSpecification{{
 				ResourceType: types.ResourceTypeVpc,
 				Tags: []types.Tag{{
@@ -43,13 +65,16 @@ func CreateVPC(
 			return
 		}
 
-		_ = RemoveVPC(ec2Client, *createVPCResp.Vpc.VpcId)
+		_ = RemoveVPC(withoutCancel(ctx), ec2Client, *createVPCResp.Vpc.VpcId)
 	}()
 
-	availableWaiter := ec2.NewVpcAvailableWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	availableWaiter := ec2.NewVpcAvailableWaiter(ec2Client, func(o *ec2.VpcAvailableWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	err = availableWaiter.Wait(context.TODO(), &ec2.DescribeVpcsInput{
+	err = availableWaiter.Wait(ctx, &ec2.DescribeVpcsInput{
 		VpcIds: []string{
 			*createVPCResp.Vpc.VpcId,
 		},
@@ -70,7 +95,7 @@ func CreateVPC(
 
 	go func() {
 		_, err := ec2Client.ModifyVpcAttribute(
-			context.TODO(),
+			ctx,
 			&ec2.ModifyVpcAttributeInput{
 				EnableDnsSupport: &types.AttributeBooleanValue{
 					Value: aws.Bool(true),
@@ -84,7 +109,7 @@ func CreateVPC(
 
 	go func() {
 		_, err := ec2Client.ModifyVpcAttribute(
-			context.TODO(),
+			ctx,
 			&ec2.ModifyVpcAttributeInput{
 				EnableDnsHostnames: &types.AttributeBooleanValue{
 					Value: aws.Bool(true),
@@ -110,7 +135,80 @@ func CreateVPC(
 	}
 
 	returnedVPC = &VPC{
-		ID: *createVPCResp.Vpc.VpcId,
+		ID:        *createVPCResp.Vpc.VpcId,
+		CIDRBlock: CIDRBlock,
+	}
+
+	if !withIPv6 {
+		return
+	}
+
+	IPv6CIDRBlock, err := waitForVPCIPv6CIDRBlock(
+		ctx,
+		ec2Client,
+		waitOpts,
+		*createVPCResp.Vpc.VpcId,
+	)
+
+	if err != nil {
+		returnedVPC = nil
+		returnedError = err
+		return
 	}
+
+	returnedVPC.IPv6CIDRBlock = IPv6CIDRBlock
 	return
 }
+
+// waitForVPCIPv6CIDRBlock waits for the Amazon-provided
+// IPv6 CIDR block to be associated with the VPC.
+func waitForVPCIPv6CIDRBlock(
+	ctx context.Context,
+	ec2Client ec2.DescribeVpcsAPIClient,
+	waitOpts WaitOpts,
+	VPCID string,
+) (string, error) {
+
+	pollTimeoutChan := time.After(waitOpts.ResourceMaxWaitTime)
+	pollAttempt := 0
+
+	for {
+		describeVPCsResp, err := ec2Client.DescribeVpcs(
+			ctx,
+			&ec2.DescribeVpcsInput{
+				VpcIds: []string{
+					VPCID,
+				},
+			},
+		)
+
+		if err != nil {
+			return "", err
+		}
+
+		for _, VPC := range describeVPCsResp.Vpcs {
+			for _, association := range VPC.Ipv6CidrBlockAssociationSet {
+				if association.Ipv6CidrBlockState == nil {
+					continue
+				}
+
+				switch association.Ipv6CidrBlockState.State {
+				case types.VpcCidrBlockStateCodeAssociated:
+					return aws.ToString(association.Ipv6CidrBlock), nil
+				case types.VpcCidrBlockStateCodeFailed:
+					return "", ErrVPCIPv6CIDRBlockNotAssociated
+				}
+			}
+		}
+
+		pollAttempt++
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-pollTimeoutChan:
+			return "", ErrVPCIPv6CIDRBlockNotAssociated
+		case <-time.After(waitOpts.pollDelay(pollAttempt)):
+		}
+	}
+}
@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type DetachElasticIPFromInstanceAPIClient interface {
+	DisassociateAddress(context.Context, *ec2.DisassociateAddressInput, ...func(*ec2.Options)) (*ec2.DisassociateAddressOutput, error)
+}
+
 func DetachElasticIPFromInstance(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client DetachElasticIPFromInstanceAPIClient,
 	elasticIPAssociationId string,
 ) error {
 
 	_, err := ec2Client.DisassociateAddress(
-		context.TODO(),
+		ctx,
 		&ec2.DisassociateAddressInput{
 			AssociationId: &elasticIPAssociationId,
 		},
@@ -6,14 +6,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type DetachInternetGatewayFromVPCAPIClient interface {
+	DetachInternetGateway(context.Context, *ec2.DetachInternetGatewayInput, ...func(*ec2.Options)) (*ec2.DetachInternetGatewayOutput, error)
+}
+
 func DetachInternetGatewayFromVPC(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client DetachInternetGatewayFromVPCAPIClient,
 	internetGatewayId string,
 	VPCID string,
 ) error {
 
 	_, err := ec2Client.DetachInternetGateway(
-		context.TODO(),
+		ctx,
 		&ec2.DetachInternetGatewayInput{
 			InternetGatewayId: &internetGatewayId,
 			VpcId:             &VPCID,
@@ -0,0 +1,48 @@
+package infrastructure
+
+import (
+	"context"
+	"strings"
+)
+
+// instanceRootFilesystemExtendCmd grows the partition of the root
+// filesystem to the size of its volume then grows the filesystem.
+// "growpart" exits with the code 1 and prints "NOCHANGE" when the
+// partition already fills the volume so only this failure is ignored.
+var instanceRootFilesystemExtendCmd = strings.Join([]string{
+	`ROOT_SOURCE="$(findmnt -n -o SOURCE /)"`,
+	`ROOT_DISK="/dev/$(lsblk -n -o PKNAME "$ROOT_SOURCE")"`,
+	`ROOT_PARTITION="$(cat "/sys/class/block/$(basename "$ROOT_SOURCE")/partition")"`,
+	`{ GROWPART_OUTPUT="$(sudo growpart "$ROOT_DISK" "$ROOT_PARTITION" 2>&1)" || { [ "$?" -eq 1 ] && echo "$GROWPART_OUTPUT" | grep -q "^NOCHANGE:"; } || { echo "$GROWPART_OUTPUT" >&2; false; }; }`,
+	`sudo resize2fs "$ROOT_SOURCE"`,
+}, " && ")
+
+// ExtendInstanceRootFilesystem makes the root filesystem of the
+// instance use all the space of its (grown) root volume.
+func ExtendInstanceRootFilesystem(
+	ctx context.Context,
+	dialer Dialer,
+	waitOpts WaitOpts,
+	instancePublicIPAddress string,
+	instanceSSHPort string,
+	instanceSSHHostKeys []string,
+	instanceLoginUser string,
+	sshPrivateKeyContent string,
+) error {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := runCmdOnInstanceViaSSH(
+		ctx,
+		dialer,
+		waitOpts,
+		instancePublicIPAddress,
+		instanceSSHPort,
+		instanceSSHHostKeys,
+		instanceLoginUser,
+		sshPrivateKeyContent,
+		instanceRootFilesystemExtendCmd,
+	)
+
+	return err
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var (
+	ErrVolumeModificationFailed  = errors.New("ErrVolumeModificationFailed")
+	ErrVolumeModificationTimeout = errors.New("ErrVolumeModificationTimeout")
+)
+
+type GrowVolumeAPIClient interface {
+	ModifyVolume(context.Context, *ec2.ModifyVolumeInput, ...func(*ec2.Options)) (*ec2.ModifyVolumeOutput, error)
+	DescribeVolumesModifications(context.Context, *ec2.DescribeVolumesModificationsInput, ...func(*ec2.Options)) (*ec2.DescribeVolumesModificationsOutput, error)
+}
+
+// GrowVolume increases the size of the volume and waits for
+// the modification to reach the "optimizing" state, from which
+// the new size could be used by the filesystem.
+func GrowVolume(
+	ctx context.Context,
+	ec2Client GrowVolumeAPIClient,
+	waitOpts WaitOpts,
+	volumeID string,
+	sizeGb int32,
+) (returnedError error) {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := ec2Client.ModifyVolume(ctx, &ec2.ModifyVolumeInput{
+		VolumeId: aws.String(volumeID),
+		Size:     aws.Int32(sizeGb),
+	})
+
+	if err != nil {
+		returnedError = err
+		return
+	}
+
+	pollTimeoutChan := time.After(waitOpts.ResourceMaxWaitTime)
+	pollAttempt := 0
+
+	for {
+		select {
+		case <-pollTimeoutChan:
+			if returnedError == nil {
+				returnedError = ErrVolumeModificationTimeout
+			}
+			return
+		default:
+			describeModificationsResp, err := ec2Client.DescribeVolumesModifications(
+				ctx,
+				&ec2.DescribeVolumesModificationsInput{
+					VolumeIds: []string{volumeID},
+				},
+			)
+
+			// Make sure timeout returns last error
+			returnedError = err
+
+			if err != nil {
+				break // wait and retry until timeout
+			}
+
+			for _, modification := range describeModificationsResp.VolumesModifications {
+				if aws.ToInt32(modification.TargetSize) != sizeGb {
+					continue
+				}
+
+				switch modification.ModificationState {
+				case types.VolumeModificationStateOptimizing,
+					types.VolumeModificationStateCompleted:
+					return
+				case types.VolumeModificationStateFailed:
+					returnedError = fmt.Errorf(
+						"%w (\"%s\")",
+						ErrVolumeModificationFailed,
+						aws.ToString(modification.StatusMessage),
+					)
+					return
+				}
+			}
+		}
+
+		pollAttempt++
+
+		select {
+		case <-ctx.Done():
+			returnedError = ctx.Err()
+			return
+		case <-time.After(waitOpts.pollDelay(pollAttempt)):
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package infrastructure
+
+import (
+	"net"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// DefaultIngressCIDRs represents the CIDRs allowed
+// to reach the instances when none are passed.
+var DefaultIngressCIDRs = []string{
+	"0.0.0.0/0",
+}
+
+// DefaultIPv6IngressCIDRs represents the CIDRs allowed
+// to reach the dual-stack instances when none are passed.
+var DefaultIPv6IngressCIDRs = []string{
+	"::/0",
+}
+
+// BuildIngressIPPermission returns the permission that allows
+// the passed CIDRs (IPv4 or IPv6) to reach the passed port range.
+func BuildIngressIPPermission(
+	protocol string,
+	fromPort int32,
+	toPort int32,
+	CIDRs []string,
+) types.IpPermission {
+
+	IPPermission := types.IpPermission{
+		IpProtocol: aws.String(protocol),
+		FromPort:   aws.Int32(fromPort),
+		ToPort:     aws.Int32(toPort),
+	}
+
+	for _, CIDR := range CIDRs {
+		if isIPv6CIDR(CIDR) {
+			IPPermission.Ipv6Ranges = append(IPPermission.Ipv6Ranges, types.Ipv6Range{
+				CidrIpv6: aws.String(CIDR),
+			})
+			continue
+		}
+
+		IPPermission.IpRanges = append(IPPermission.IpRanges, types.IpRange{
+			CidrIp: aws.String(CIDR),
+		})
+	}
+
+	return IPPermission
+}
+
+func isIPv6CIDR(CIDR string) bool {
+	IP, _, err := net.ParseCIDR(CIDR)
+	return err == nil && IP.To4() == nil
+}
@@ -0,0 +1,300 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	// SessionManagerPortForwardingDocument represents the
+	// SSM document used to forward a port of an instance.
+	SessionManagerPortForwardingDocument = "AWS-StartPortForwardingSession"
+
+	// DefaultAWSCLIPath represents the AWS CLI used to start the
+	// Session Manager sessions (the Session Manager plugin must be installed).
+	DefaultAWSCLIPath = "aws"
+
+	sessionManagerMaxBindAttempts = 3
+)
+
+var (
+	ErrSessionManagerSessionExited = errors.New("ErrSessionManagerSessionExited")
+
+	errSessionManagerLocalPortInUse = errors.New("errSessionManagerLocalPortInUse")
+)
+
+// InstancePortForwarder represents a port forwarder
+// used to reach the ports of the instances that
+// have no public IP address.
+type InstancePortForwarder interface {
+	DialInstancePort(ctx context.Context, instanceID, port string) (net.Conn, error)
+}
+
+// PortForwardingDialer represents a dialer that reaches
+// the instances through an InstancePortForwarder.
+//
+// Dialed addresses are of the form "<instance_id>:<port>".
+type PortForwardingDialer struct {
+	PortForwarder InstancePortForwarder
+}
+
+func (p PortForwardingDialer) DialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+
+	instanceID, port, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.PortForwarder.DialInstancePort(ctx, instanceID, port)
+}
+
+// SessionManagerPortForwarder represents a port forwarder that starts
+// one SSM Session Manager port forwarding session per connection.
+//
+// The AWS CLI and the Session Manager plugin ("session-manager-plugin")
+// must be installed locally. The instances need the SSM agent, an instance
+// profile that allows SSM and a route to the SSM endpoints (NAT gateway).
+type SessionManagerPortForwarder struct {
+	Region      string
+	Credentials aws.CredentialsProvider
+
+	// AWSCLIPath default to DefaultAWSCLIPath if not set.
+	AWSCLIPath string
+}
+
+func (s SessionManagerPortForwarder) DialInstancePort(
+	ctx context.Context,
+	instanceID string,
+	port string,
+) (net.Conn, error) {
+
+	var err error
+
+	// The free local port could be taken by another process
+	// before the Session Manager plugin binds it
+	for attempt := 1; attempt <= sessionManagerMaxBindAttempts; attempt++ {
+		var conn net.Conn
+		conn, err = s.startSession(ctx, instanceID, port)
+
+		if err == nil || !errors.Is(err, errSessionManagerLocalPortInUse) {
+			return conn, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w (%v)", ErrSessionManagerSessionExited, err)
+}
+
+func (s SessionManagerPortForwarder) startSession(
+	ctx context.Context,
+	instanceID string,
+	port string,
+) (net.Conn, error) {
+
+	localPort, err := lookupFreeLocalPort()
+
+	if err != nil {
+		return nil, err
+	}
+
+	AWSCLIPath := s.AWSCLIPath
+
+	if len(AWSCLIPath) == 0 {
+		AWSCLIPath = DefaultAWSCLIPath
+	}
+
+	// The session outlives the passed context
+	// (it is closed with the returned connection)
+	sessionCtx, cancelSession := context.WithCancel(context.Background())
+
+	cmd := exec.CommandContext(
+		sessionCtx,
+		AWSCLIPath,
+		"ssm",
+		"start-session",
+		"--region", s.Region,
+		"--target", instanceID,
+		"--document-name", SessionManagerPortForwardingDocument,
+		"--parameters", fmt.Sprintf(
+			`{"portNumber":["%s"],"localPortNumber":["%d"]}`,
+			port,
+			localPort,
+		),
+	)
+
+	cmd.Env = os.Environ()
+
+	if s.Credentials != nil {
+		credentials, err := s.Credentials.Retrieve(ctx)
+
+		if err != nil {
+			cancelSession()
+			return nil, err
+		}
+
+		cmd.Env = append(
+			cmd.Env,
+			"AWS_ACCESS_KEY_ID="+credentials.AccessKeyID,
+			"AWS_SECRET_ACCESS_KEY="+credentials.SecretAccessKey,
+			"AWS_SESSION_TOKEN="+credentials.SessionToken,
+		)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+
+	err = cmd.Start()
+
+	if err != nil {
+		cancelSession()
+		return nil, err
+	}
+
+	// The plugin reports the local port once it is bound.
+	// Waiting for it ensures that the port is not
+	// owned by another process when dialed.
+	portOpenedMessage := fmt.Sprintf("Port %d opened", localPort)
+	portOpened := make(chan struct{})
+
+	var stdout bytes.Buffer
+	stdoutRead := make(chan struct{})
+
+	go func() {
+		defer close(stdoutRead)
+
+		scanner := bufio.NewScanner(stdoutReader)
+		portOpenedReported := false
+
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			if !portOpenedReported && strings.Contains(line, portOpenedMessage) {
+				portOpenedReported = true
+				close(portOpened)
+			}
+
+			if !portOpenedReported {
+				stdout.WriteString(line + "\n")
+			}
+		}
+
+		// Drain the plugin output until it exits
+		_, _ = io.Copy(io.Discard, stdoutReader)
+	}()
+
+	cmdExited := make(chan struct{})
+
+	go func() {
+		_ = cmd.Wait()
+		_ = stdoutWriter.Close()
+		<-stdoutRead
+		close(cmdExited)
+	}()
+
+	session := &sessionManagerSession{
+		cancel:    cancelSession,
+		cmdExited: cmdExited,
+	}
+
+	select {
+	case <-ctx.Done():
+		session.close()
+		return nil, ctx.Err()
+	case <-cmdExited:
+		session.close()
+
+		output := bytes.TrimSpace(
+			append(stdout.Bytes(), stderr.Bytes()...),
+		)
+
+		if bytes.Contains(output, []byte("address already in use")) {
+			return nil, fmt.Errorf(
+				"%w (\"%s\")",
+				errSessionManagerLocalPortInUse,
+				output,
+			)
+		}
+
+		return nil, fmt.Errorf(
+			"%w (\"%s\")",
+			ErrSessionManagerSessionExited,
+			output,
+		)
+	case <-portOpened:
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(
+		ctx,
+		"tcp",
+		net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort)),
+	)
+
+	if err != nil {
+		session.close()
+		return nil, err
+	}
+
+	return &sessionManagerConn{
+		Conn:    conn,
+		session: session,
+	}, nil
+}
+
+type sessionManagerSession struct {
+	cancel    context.CancelFunc
+	cmdExited chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *sessionManagerSession) close() {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		<-s.cmdExited
+	})
+}
+
+// sessionManagerConn closes the session
+// when the connection is closed.
+type sessionManagerConn struct {
+	net.Conn
+	session *sessionManagerSession
+}
+
+func (s *sessionManagerConn) Close() error {
+	err := s.Conn.Close()
+	s.session.close()
+
+	return err
+}
+
+func lookupFreeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,220 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSessionManagerPluginEnvVar   = "ELEVEN_TEST_SESSION_MANAGER_PLUGIN"
+	testSessionManagerAttemptsEnvVar = "ELEVEN_TEST_SESSION_MANAGER_ATTEMPTS_DIR"
+	testSessionManagerFailuresEnvVar = "ELEVEN_TEST_SESSION_MANAGER_FAILURES"
+)
+
+func TestSessionManagerPortForwarder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake AWS CLI is a shell script")
+	}
+
+	testCases := []struct {
+		test             string
+		bindFailures     int
+		expectedAttempts int
+		expectedError    error
+	}{
+		{
+			test:             "with free local port",
+			expectedAttempts: 1,
+		},
+
+		{
+			test:             "with local port taken before bind",
+			bindFailures:     1,
+			expectedAttempts: 2,
+		},
+
+		{
+			test:             "with local port always taken before bind",
+			bindFailures:     sessionManagerMaxBindAttempts,
+			expectedAttempts: sessionManagerMaxBindAttempts,
+			expectedError:    ErrSessionManagerSessionExited,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			attemptsDir := t.TempDir()
+
+			t.Setenv(testSessionManagerAttemptsEnvVar, attemptsDir)
+			t.Setenv(testSessionManagerFailuresEnvVar, fmt.Sprint(tc.bindFailures))
+
+			portForwarder := SessionManagerPortForwarder{
+				Region:     "us-east-1",
+				AWSCLIPath: writeFakeAWSCLI(t),
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			conn, err := portForwarder.DialInstancePort(ctx, "i-0123456789", "2200")
+
+			attempts, readDirErr := os.ReadDir(attemptsDir)
+
+			if readDirErr != nil {
+				t.Fatalf("expected no error, got '%+v'", readDirErr)
+			}
+
+			if len(attempts) != tc.expectedAttempts {
+				t.Fatalf(
+					"expected %d session(s) to be started, got %d",
+					tc.expectedAttempts,
+					len(attempts),
+				)
+			}
+
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			banner := make([]byte, len(testTargetBanner))
+			_, err = io.ReadFull(conn, banner)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if string(banner) != testTargetBanner {
+				t.Fatalf(
+					"expected banner to equal '%s', got '%s'",
+					testTargetBanner,
+					string(banner),
+				)
+			}
+
+			// Closing the connection stops the session
+			err = conn.Close()
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+		})
+	}
+}
+
+// TestSessionManagerPluginHelperProcess is not a real test.
+// It is run by the fake AWS CLI to act like the AWS CLI
+// and the Session Manager plugin.
+func TestSessionManagerPluginHelperProcess(t *testing.T) {
+	if os.Getenv(testSessionManagerPluginEnvVar) != "1" {
+		return
+	}
+
+	var parameters string
+
+	for argIndex, arg := range os.Args {
+		if arg == "--parameters" && argIndex+1 < len(os.Args) {
+			parameters = os.Args[argIndex+1]
+		}
+	}
+
+	var portForwardingParameters struct {
+		LocalPortNumber []string `json:"localPortNumber"`
+	}
+
+	err := json.Unmarshal([]byte(parameters), &portForwardingParameters)
+
+	if err != nil || len(portForwardingParameters.LocalPortNumber) != 1 {
+		fmt.Fprintf(os.Stderr, "invalid parameters: %s\n", parameters)
+		os.Exit(2)
+	}
+
+	localPort := portForwardingParameters.LocalPortNumber[0]
+
+	attemptsDir := os.Getenv(testSessionManagerAttemptsEnvVar)
+	previousAttempts, _ := os.ReadDir(attemptsDir)
+	_ = os.WriteFile(filepath.Join(attemptsDir, localPort), nil, 0600)
+
+	var bindFailures int
+	_, _ = fmt.Sscan(os.Getenv(testSessionManagerFailuresEnvVar), &bindFailures)
+
+	fmt.Println("Starting session with SessionId: eleven-0123456789")
+
+	if len(previousAttempts) < bindFailures {
+		fmt.Printf(
+			"listen tcp 127.0.0.1:%s: bind: address already in use\n",
+			localPort,
+		)
+		os.Exit(255)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", localPort))
+
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(255)
+	}
+
+	fmt.Printf("Port %s opened for sessionId eleven-0123456789.\n", localPort)
+	fmt.Println("Waiting for connections...")
+
+	for {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			os.Exit(255)
+		}
+
+		_, _ = conn.Write([]byte(testTargetBanner))
+	}
+}
+
+// writeFakeAWSCLI writes a script that runs the test binary as
+// the AWS CLI (see TestSessionManagerPluginHelperProcess).
+func writeFakeAWSCLI(t *testing.T) string {
+	testBinaryPath, err := os.Executable()
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	fakeAWSCLIPath := filepath.Join(t.TempDir(), "aws")
+	fakeAWSCLI := strings.Join([]string{
+		"#!/bin/sh",
+		fmt.Sprintf(
+			`%s=1 exec '%s' -test.run='^TestSessionManagerPluginHelperProcess$' -- "$@"`,
+			testSessionManagerPluginEnvVar,
+			testBinaryPath,
+		),
+		"",
+	}, "\n")
+
+	err = os.WriteFile(fakeAWSCLIPath, []byte(fakeAWSCLI), 0700)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	return fakeAWSCLIPath
+}
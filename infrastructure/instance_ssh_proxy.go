@@ -0,0 +1,403 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	socks5Version             = 0x05
+	socks5AuthMethodNone      = 0x00
+	socks5AuthMethodPassword  = 0x02
+	socks5AuthMethodNoneFound = 0xff
+	socks5PasswordAuthVersion = 0x01
+	socks5CmdConnect          = 0x01
+	socks5AddrTypeIPv4        = 0x01
+	socks5AddrTypeDomain      = 0x03
+	socks5AddrTypeIPv6        = 0x04
+	socks5ReplySucceeded      = 0x00
+)
+
+var (
+	ErrUnsupportedProxyScheme = errors.New("ErrUnsupportedProxyScheme")
+	ErrProxyConnectFailed     = errors.New("ErrProxyConnectFailed")
+)
+
+// ProxyDialer represents a dialer that reaches the passed
+// addresses through a SOCKS5 or an HTTP CONNECT proxy.
+type ProxyDialer struct {
+	// Dialer specifies the dialer used to reach
+	// the proxy. Default to net.Dialer if not set.
+	Dialer Dialer
+
+	// ProxyURL specifies the proxy. The scheme is either
+	// "socks5" (host names resolved locally), "socks5h" (host
+	// names resolved by the proxy) or "http". Credentials
+	// are taken from the user info, if any.
+	ProxyURL *url.URL
+}
+
+func (p ProxyDialer) DialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+
+	var defaultProxyPort string
+
+	switch p.ProxyURL.Scheme {
+	case "socks5", "socks5h":
+		defaultProxyPort = "1080"
+	case "http":
+		defaultProxyPort = "80"
+	default:
+		return nil, fmt.Errorf(
+			"%w (\"%s\")",
+			ErrUnsupportedProxyScheme,
+			p.ProxyURL.Scheme,
+		)
+	}
+
+	proxyAddress := p.ProxyURL.Host
+
+	if len(p.ProxyURL.Port()) == 0 {
+		proxyAddress = net.JoinHostPort(
+			p.ProxyURL.Hostname(),
+			defaultProxyPort,
+		)
+	}
+
+	if p.ProxyURL.Scheme == "socks5" {
+		resolvedAddress, err := resolveSOCKS5TargetAddress(ctx, address)
+
+		if err != nil {
+			return nil, err
+		}
+
+		address = resolvedAddress
+	}
+
+	var dialer Dialer = &net.Dialer{}
+
+	if p.Dialer != nil {
+		dialer = p.Dialer
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Bound the proxy handshake by the ctx deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if p.ProxyURL.Scheme == "http" {
+		conn, err = connectViaHTTPProxy(conn, p.ProxyURL, address)
+	} else {
+		err = connectViaSOCKS5Proxy(conn, p.ProxyURL, address)
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
+
+// resolveSOCKS5TargetAddress resolves the host name of the
+// address, if any, for the "socks5" proxies (the "socks5h"
+// proxies resolve it themselves). IPv4 addresses are preferred.
+func resolveSOCKS5TargetAddress(
+	ctx context.Context,
+	address string,
+) (string, error) {
+
+	host, port, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return "", err
+	}
+
+	if net.ParseIP(host) != nil {
+		return address, nil
+	}
+
+	IPs, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+
+	if err != nil {
+		return "", err
+	}
+
+	resolvedIP := IPs[0]
+
+	for _, IP := range IPs {
+		if IP.To4() != nil {
+			resolvedIP = IP
+			break
+		}
+	}
+
+	return net.JoinHostPort(resolvedIP.String(), port), nil
+}
+
+func connectViaHTTPProxy(
+	conn net.Conn,
+	proxyURL *url.URL,
+	address string,
+) (net.Conn, error) {
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL: &url.URL{
+			Opaque: address,
+		},
+		Host:   address,
+		Header: http.Header{},
+	}
+
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := proxyURL.User.Username() + ":" + password
+
+		req.Header.Set(
+			"Proxy-Authorization",
+			"Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)),
+		)
+	}
+
+	err := req.Write(conn)
+
+	if err != nil {
+		return conn, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+
+	if err != nil {
+		return conn, err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return conn, fmt.Errorf(
+			"%w (\"%s\")",
+			ErrProxyConnectFailed,
+			resp.Status,
+		)
+	}
+
+	// SSH servers speak first so the
+	// reader may have buffered some data
+	return bufferedProxyConn{
+		Conn:   conn,
+		reader: reader,
+	}, nil
+}
+
+type bufferedProxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (b bufferedProxyConn) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+// connectViaSOCKS5Proxy implements the CONNECT command of
+// the SOCKS5 protocol (RFC 1928) with the optional
+// username / password authentication (RFC 1929).
+func connectViaSOCKS5Proxy(
+	conn net.Conn,
+	proxyURL *url.URL,
+	address string,
+) error {
+
+	host, portAsString, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	port, err := strconv.ParseUint(portAsString, 10, 16)
+
+	if err != nil {
+		return err
+	}
+
+	authMethods := []byte{socks5AuthMethodNone}
+
+	if proxyURL.User != nil {
+		authMethods = append(authMethods, socks5AuthMethodPassword)
+	}
+
+	greeting := append(
+		[]byte{socks5Version, byte(len(authMethods))},
+		authMethods...,
+	)
+
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	greetingReply := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, greetingReply); err != nil {
+		return err
+	}
+
+	if greetingReply[0] != socks5Version {
+		return fmt.Errorf(
+			"%w (\"unexpected SOCKS version %d\")",
+			ErrProxyConnectFailed,
+			greetingReply[0],
+		)
+	}
+
+	switch greetingReply[1] {
+	case socks5AuthMethodNone:
+	case socks5AuthMethodPassword:
+		err = authenticateToSOCKS5Proxy(conn, proxyURL.User)
+
+		if err != nil {
+			return err
+		}
+	case socks5AuthMethodNoneFound:
+		return fmt.Errorf(
+			"%w (\"no acceptable SOCKS5 authentication method\")",
+			ErrProxyConnectFailed,
+		)
+	default:
+		return fmt.Errorf(
+			"%w (\"unexpected SOCKS5 authentication method %d\")",
+			ErrProxyConnectFailed,
+			greetingReply[1],
+		)
+	}
+
+	connectRequest := []byte{socks5Version, socks5CmdConnect, 0x00}
+
+	if IP := net.ParseIP(host); IP != nil {
+		if IPv4 := IP.To4(); IPv4 != nil {
+			connectRequest = append(connectRequest, socks5AddrTypeIPv4)
+			connectRequest = append(connectRequest, IPv4...)
+		} else {
+			connectRequest = append(connectRequest, socks5AddrTypeIPv6)
+			connectRequest = append(connectRequest, IP.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf(
+				"%w (\"host name too long\")",
+				ErrProxyConnectFailed,
+			)
+		}
+
+		connectRequest = append(connectRequest, socks5AddrTypeDomain, byte(len(host)))
+		connectRequest = append(connectRequest, host...)
+	}
+
+	connectRequest = append(connectRequest, byte(port>>8), byte(port))
+
+	if _, err := conn.Write(connectRequest); err != nil {
+		return err
+	}
+
+	connectReplyHeader := make([]byte, 4)
+
+	if _, err := io.ReadFull(conn, connectReplyHeader); err != nil {
+		return err
+	}
+
+	if connectReplyHeader[1] != socks5ReplySucceeded {
+		return fmt.Errorf(
+			"%w (\"SOCKS5 reply code %d\")",
+			ErrProxyConnectFailed,
+			connectReplyHeader[1],
+		)
+	}
+
+	// Discard the bound address and port
+	var boundAddressLength int
+
+	switch connectReplyHeader[3] {
+	case socks5AddrTypeIPv4:
+		boundAddressLength = net.IPv4len
+	case socks5AddrTypeIPv6:
+		boundAddressLength = net.IPv6len
+	case socks5AddrTypeDomain:
+		domainLength := make([]byte, 1)
+
+		if _, err := io.ReadFull(conn, domainLength); err != nil {
+			return err
+		}
+
+		boundAddressLength = int(domainLength[0])
+	default:
+		return fmt.Errorf(
+			"%w (\"unexpected SOCKS5 address type %d\")",
+			ErrProxyConnectFailed,
+			connectReplyHeader[3],
+		)
+	}
+
+	_, err = io.ReadFull(conn, make([]byte, boundAddressLength+2))
+
+	return err
+}
+
+func authenticateToSOCKS5Proxy(
+	conn net.Conn,
+	userInfo *url.Userinfo,
+) error {
+
+	username := userInfo.Username()
+	password, _ := userInfo.Password()
+
+	if len(username) > 255 || len(password) > 255 {
+		return fmt.Errorf(
+			"%w (\"SOCKS5 credentials too long\")",
+			ErrProxyConnectFailed,
+		)
+	}
+
+	authRequest := []byte{socks5PasswordAuthVersion, byte(len(username))}
+	authRequest = append(authRequest, username...)
+	authRequest = append(authRequest, byte(len(password)))
+	authRequest = append(authRequest, password...)
+
+	if _, err := conn.Write(authRequest); err != nil {
+		return err
+	}
+
+	authReply := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, authReply); err != nil {
+		return err
+	}
+
+	if authReply[1] != socks5ReplySucceeded {
+		return fmt.Errorf(
+			"%w (\"SOCKS5 authentication failed\")",
+			ErrProxyConnectFailed,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,360 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+const (
+	testProxyUsername = "eleven"
+	testProxyPassword = "secret"
+	testTargetBanner  = "SSH-2.0-Test\r\n"
+)
+
+func TestProxyDialer(t *testing.T) {
+	// Like SSH servers, the target speaks first
+	target := startTestListener(t, func(conn net.Conn) {
+		_, _ = conn.Write([]byte(testTargetBanner))
+	})
+	defer target.Close()
+
+	HTTPProxy := startTestListener(t, serveTestHTTPProxyConn)
+	defer HTTPProxy.Close()
+
+	SOCKS5ProxyRecorder := &testSOCKS5Proxy{}
+
+	SOCKS5Proxy := startTestListener(t, SOCKS5ProxyRecorder.serveConn)
+	defer SOCKS5Proxy.Close()
+
+	_, targetPort, err := net.SplitHostPort(target.Addr().String())
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	testCases := []struct {
+		test                  string
+		proxyURL              string
+		targetHost            string
+		expectedRequestedHost string
+		expectedError         error
+	}{
+		{
+			test:     "with HTTP CONNECT proxy",
+			proxyURL: "http://eleven:secret@" + HTTPProxy.Addr().String(),
+		},
+
+		{
+			test:          "with HTTP CONNECT proxy and invalid credentials",
+			proxyURL:      "http://eleven:invalid@" + HTTPProxy.Addr().String(),
+			expectedError: ErrProxyConnectFailed,
+		},
+
+		{
+			test:     "with SOCKS5 proxy",
+			proxyURL: "socks5://eleven:secret@" + SOCKS5Proxy.Addr().String(),
+		},
+
+		{
+			test:                  "with SOCKS5 proxy and host name",
+			proxyURL:              "socks5://eleven:secret@" + SOCKS5Proxy.Addr().String(),
+			targetHost:            "localhost",
+			expectedRequestedHost: "127.0.0.1",
+		},
+
+		{
+			test:                  "with SOCKS5h proxy and host name",
+			proxyURL:              "socks5h://eleven:secret@" + SOCKS5Proxy.Addr().String(),
+			targetHost:            "localhost",
+			expectedRequestedHost: "localhost",
+		},
+
+		{
+			test:          "with SOCKS5 proxy and invalid credentials",
+			proxyURL:      "socks5://eleven:invalid@" + SOCKS5Proxy.Addr().String(),
+			expectedError: ErrProxyConnectFailed,
+		},
+
+		{
+			test:          "with unsupported scheme",
+			proxyURL:      "ftp://" + HTTPProxy.Addr().String(),
+			expectedError: ErrUnsupportedProxyScheme,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			proxyURL, err := url.Parse(tc.proxyURL)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			dialer := ProxyDialer{
+				ProxyURL: proxyURL,
+			}
+
+			targetHost := tc.targetHost
+
+			if len(targetHost) == 0 {
+				targetHost = "127.0.0.1"
+			}
+
+			conn, err := dialer.DialContext(
+				context.Background(),
+				"tcp",
+				net.JoinHostPort(targetHost, targetPort),
+			)
+
+			if tc.expectedError != nil {
+				if !errors.Is(err, tc.expectedError) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			defer conn.Close()
+
+			banner := make([]byte, len(testTargetBanner))
+			_, err = io.ReadFull(conn, banner)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if string(banner) != testTargetBanner {
+				t.Fatalf(
+					"expected banner to equal '%s', got '%s'",
+					testTargetBanner,
+					string(banner),
+				)
+			}
+
+			if len(tc.expectedRequestedHost) > 0 &&
+				SOCKS5ProxyRecorder.lastRequestedHost() != tc.expectedRequestedHost {
+
+				t.Fatalf(
+					"expected proxy to be asked for '%s', got '%s'",
+					tc.expectedRequestedHost,
+					SOCKS5ProxyRecorder.lastRequestedHost(),
+				)
+			}
+		})
+	}
+}
+
+func startTestListener(t *testing.T, serveConn func(conn net.Conn)) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil { // Listener closed
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				serveConn(conn)
+			}()
+		}
+	}()
+
+	return listener
+}
+
+func serveTestHTTPProxyConn(conn net.Conn) {
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+
+	if err != nil || req.Method != http.MethodConnect {
+		return
+	}
+
+	expectedAuthorization := "Basic " + base64.StdEncoding.EncodeToString(
+		[]byte(testProxyUsername+":"+testProxyPassword),
+	)
+
+	if req.Header.Get("Proxy-Authorization") != expectedAuthorization {
+		_, _ = conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+		return
+	}
+
+	targetConn, err := net.Dial("tcp", req.Host)
+
+	if err != nil {
+		_, _ = conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+		return
+	}
+
+	defer targetConn.Close()
+
+	_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+	pipeTestConns(conn, targetConn)
+}
+
+// testSOCKS5Proxy only supports the username / password
+// authentication and the IPv4 and domain name addresses.
+// It records the hosts that it is asked to connect to.
+type testSOCKS5Proxy struct {
+	mu             sync.Mutex
+	requestedHosts []string
+}
+
+func (p *testSOCKS5Proxy) lastRequestedHost() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.requestedHosts) == 0 {
+		return ""
+	}
+
+	return p.requestedHosts[len(p.requestedHosts)-1]
+}
+
+func (p *testSOCKS5Proxy) serveConn(conn net.Conn) {
+	greeting := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, greeting); err != nil {
+		return
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, greeting[1])); err != nil {
+		return
+	}
+
+	_, _ = conn.Write([]byte{socks5Version, socks5AuthMethodPassword})
+
+	authHeader := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, authHeader); err != nil {
+		return
+	}
+
+	username := make([]byte, authHeader[1])
+
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return
+	}
+
+	passwordLength := make([]byte, 1)
+
+	if _, err := io.ReadFull(conn, passwordLength); err != nil {
+		return
+	}
+
+	password := make([]byte, passwordLength[0])
+
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return
+	}
+
+	if string(username) != testProxyUsername || string(password) != testProxyPassword {
+		_, _ = conn.Write([]byte{socks5PasswordAuthVersion, 0x01})
+		return
+	}
+
+	_, _ = conn.Write([]byte{socks5PasswordAuthVersion, socks5ReplySucceeded})
+
+	connectRequestHeader := make([]byte, 4)
+
+	if _, err := io.ReadFull(conn, connectRequestHeader); err != nil {
+		return
+	}
+
+	var targetHost string
+
+	switch connectRequestHeader[3] {
+	case socks5AddrTypeIPv4:
+		IPv4 := make([]byte, net.IPv4len)
+
+		if _, err := io.ReadFull(conn, IPv4); err != nil {
+			return
+		}
+
+		targetHost = net.IP(IPv4).String()
+	case socks5AddrTypeDomain:
+		domainLength := make([]byte, 1)
+
+		if _, err := io.ReadFull(conn, domainLength); err != nil {
+			return
+		}
+
+		domain := make([]byte, domainLength[0])
+
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return
+		}
+
+		targetHost = string(domain)
+	default:
+		return
+	}
+
+	targetPort := make([]byte, 2)
+
+	if _, err := io.ReadFull(conn, targetPort); err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.requestedHosts = append(p.requestedHosts, targetHost)
+	p.mu.Unlock()
+
+	targetAddress := net.JoinHostPort(
+		targetHost,
+		strconv.Itoa(int(targetPort[0])<<8|int(targetPort[1])),
+	)
+
+	targetConn, err := net.Dial("tcp", targetAddress)
+
+	if err != nil {
+		_, _ = conn.Write([]byte{socks5Version, 0x05, 0x00, socks5AddrTypeIPv4, 0, 0, 0, 0, 0, 0})
+		return
+	}
+
+	defer targetConn.Close()
+
+	_, _ = conn.Write([]byte{socks5Version, socks5ReplySucceeded, 0x00, socks5AddrTypeIPv4, 0, 0, 0, 0, 0, 0})
+
+	pipeTestConns(conn, targetConn)
+}
+
+func pipeTestConns(conn, targetConn net.Conn) {
+	copyDone := make(chan struct{}, 2)
+
+	go func() {
+		_, _ = io.Copy(targetConn, conn)
+		copyDone <- struct{}{}
+	}()
+
+	go func() {
+		_, _ = io.Copy(conn, targetConn)
+		copyDone <- struct{}{}
+	}()
+
+	<-copyDone
+}
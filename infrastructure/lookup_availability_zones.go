@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type LookupAvailabilityZonesAPIClient interface {
+	DescribeAvailabilityZones(context.Context, *ec2.DescribeAvailabilityZonesInput, ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error)
+}
+
+// LookupAvailabilityZones returns the sorted names of
+// the available zones of the region. Local and
+// Wavelength zones are not returned.
+func LookupAvailabilityZones(
+	ctx context.Context,
+	ec2Client LookupAvailabilityZonesAPIClient,
+) ([]string, error) {
+
+	describeAvailabilityZonesResp, err := ec2Client.DescribeAvailabilityZones(
+		ctx,
+		&ec2.DescribeAvailabilityZonesInput{
+			Filters: []types.Filter{{
+				Name:   aws.String("zone-type"),
+				Values: []string{"availability-zone"},
+			}, {
+				Name:   aws.String("state"),
+				Values: []string{string(types.AvailabilityZoneStateAvailable)},
+			}},
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	availabilityZones := []string{}
+
+	for _, availabilityZone := range describeAvailabilityZonesResp.AvailabilityZones {
+		availabilityZones = append(
+			availabilityZones,
+			aws.ToString(availabilityZone.ZoneName),
+		)
+	}
+
+	sort.Strings(availabilityZones)
+
+	return availabilityZones, nil
+}
@@ -20,11 +20,16 @@ type DynamoDBElevenConfigTableRecord struct {
 	ConfigJSON string
 }
 
+type LookupElevenConfigInDynamoDBTableAPIClient interface {
+	Scan(context.Context, *dynamodb.ScanInput, ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
 func LookupElevenConfigInDynamoDBTable(
-	dynamoDBClient *dynamodb.Client,
+	ctx context.Context,
+	dynamoDBClient LookupElevenConfigInDynamoDBTableAPIClient,
 ) (returnedConfigJSON string, returnedError error) {
 
-	scanResp, err := dynamoDBClient.Scan(context.TODO(), &dynamodb.ScanInput{
+	scanResp, err := dynamoDBClient.Scan(ctx, &dynamodb.ScanInput{
 		TableName: aws.String(DynamoDBElevenConfigTableName),
 	})
 
@@ -13,12 +13,13 @@ var (
 )
 
 func lookupInstance(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client ec2.DescribeInstancesAPIClient,
 	instanceID string,
 ) (*types.Instance, error) {
 
 	describeInstancesResp, err := ec2Client.DescribeInstances(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeInstancesInput{
 			InstanceIds: []string{instanceID},
 		},
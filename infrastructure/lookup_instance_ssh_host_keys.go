@@ -0,0 +1,145 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	consoleSSHHostKeysBeginMarker = "-----BEGIN SSH HOST KEY KEYS-----"
+	consoleSSHHostKeysEndMarker   = "-----END SSH HOST KEY KEYS-----"
+)
+
+var ErrInstanceSSHHostKeysNotFound = errors.New("ErrInstanceSSHHostKeysNotFound")
+
+type LookupInstanceSSHHostKeysAPIClient interface {
+	GetConsoleOutput(context.Context, *ec2.GetConsoleOutputInput, ...func(*ec2.Options)) (*ec2.GetConsoleOutputOutput, error)
+}
+
+// LookupInstanceSSHHostKeys retrieves the SSH host keys of the
+// instance (in authorized keys format) from its console output,
+// where cloud-init prints them on first boot.
+//
+// The keys are retrieved via the EC2 API, out of band of the
+// SSH connections, so they could be used to authenticate the instance.
+func LookupInstanceSSHHostKeys(
+	ctx context.Context,
+	ec2Client LookupInstanceSSHHostKeysAPIClient,
+	waitOpts WaitOpts,
+	instanceID string,
+) (returnedHostKeys []string, returnedError error) {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	// The console output is only
+	// available a few minutes after boot
+	pollTimeoutChan := time.After(waitOpts.SSHHostKeysMaxWaitTime)
+	pollAttempt := 0
+
+	for {
+		select {
+		case <-pollTimeoutChan:
+			if returnedError == nil {
+				returnedError = ErrInstanceSSHHostKeysNotFound
+			}
+			return
+		default:
+			getConsoleOutputResp, err := ec2Client.GetConsoleOutput(
+				ctx,
+				&ec2.GetConsoleOutputInput{
+					InstanceId: aws.String(instanceID),
+					// The buffered output could lag several
+					// minutes behind on Nitro instances
+					Latest: aws.Bool(true),
+				},
+			)
+
+			// Make sure timeout returns last error
+			returnedError = err
+
+			if err != nil {
+				break // wait and retry until timeout
+			}
+
+			consoleOutput, err := base64.StdEncoding.DecodeString(
+				aws.ToString(getConsoleOutputResp.Output),
+			)
+
+			if err != nil {
+				returnedError = err
+				return
+			}
+
+			hostKeys := parseConsoleSSHHostKeys(string(consoleOutput))
+
+			if len(hostKeys) > 0 {
+				returnedHostKeys = hostKeys
+				return
+			}
+		}
+
+		pollAttempt++
+
+		select {
+		case <-ctx.Done():
+			returnedError = ctx.Err()
+			return
+		case <-time.After(waitOpts.pollDelay(pollAttempt)):
+		}
+	}
+}
+
+// parseConsoleSSHHostKeys returns the keys printed between the
+// "BEGIN SSH HOST KEY KEYS" and "END SSH HOST KEY KEYS" markers.
+// The lines that are not valid keys are ignored.
+func parseConsoleSSHHostKeys(consoleOutput string) []string {
+	hostKeys := []string{}
+	inHostKeysBlock := false
+
+	scanner := bufio.NewScanner(strings.NewReader(consoleOutput))
+
+	for scanner.Scan() {
+		// Some images prefix the cloud-init messages with "ec2:"
+		line := strings.TrimSpace(
+			strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "ec2:"),
+		)
+
+		if strings.HasSuffix(line, consoleSSHHostKeysBeginMarker) {
+			// Only the last block is kept
+			// (the instance may have rebooted)
+			hostKeys = []string{}
+			inHostKeysBlock = true
+			continue
+		}
+
+		if strings.HasSuffix(line, consoleSSHHostKeysEndMarker) {
+			inHostKeysBlock = false
+			continue
+		}
+
+		if !inHostKeysBlock {
+			continue
+		}
+
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+
+		if err != nil {
+			continue
+		}
+
+		hostKeys = append(
+			hostKeys,
+			strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(hostKey)), "\n"),
+		)
+	}
+
+	return hostKeys
+}
@@ -0,0 +1,62 @@
+package infrastructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConsoleSSHHostKeys(t *testing.T) {
+	testCases := []struct {
+		test             string
+		consoleOutput    string
+		expectedHostKeys []string
+	}{
+		{
+			test:             "without host keys block",
+			consoleOutput:    "[    0.000000] Linux version 5.15.0-1019-aws\r\n",
+			expectedHostKeys: []string{},
+		},
+
+		{
+			test: "with host keys block",
+			consoleOutput: "[    0.000000] Linux version 5.15.0-1019-aws\r\n" +
+				"ec2: -----BEGIN SSH HOST KEY FINGERPRINTS-----\r\n" +
+				"ec2: 256 SHA256:Vg0S7mh8Ncx0z0l1CkSGxI6VbRcLXGKO7VxFa8ZyoqM root@ip-10-0-0-4 (ED25519)\r\n" +
+				"ec2: -----END SSH HOST KEY FINGERPRINTS-----\r\n" +
+				"-----BEGIN SSH HOST KEY KEYS-----\r\n" +
+				"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl root@ip-10-0-0-4\r\n" +
+				"not a key\r\n" +
+				"-----END SSH HOST KEY KEYS-----\r\n",
+			expectedHostKeys: []string{
+				"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+			},
+		},
+
+		{
+			test: "with prefixed host keys block after reboot",
+			consoleOutput: "ec2: -----BEGIN SSH HOST KEY KEYS-----\n" +
+				"ec2: ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl root@ip-10-0-0-4\n" +
+				"ec2: -----END SSH HOST KEY KEYS-----\n" +
+				"ec2: -----BEGIN SSH HOST KEY KEYS-----\n" +
+				"ec2: ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILM+rvN+ot98qgEN796jTiQfZfG1KaT0PtFDJ/XFSqti root@ip-10-0-0-4\n" +
+				"ec2: -----END SSH HOST KEY KEYS-----\n",
+			expectedHostKeys: []string{
+				"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAILM+rvN+ot98qgEN796jTiQfZfG1KaT0PtFDJ/XFSqti",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			hostKeys := parseConsoleSSHHostKeys(tc.consoleOutput)
+
+			if !reflect.DeepEqual(hostKeys, tc.expectedHostKeys) {
+				t.Fatalf(
+					"expected host keys to equal '%+v', got '%+v'",
+					tc.expectedHostKeys,
+					hostKeys,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type LookupInstanceTypeAvailabilityZonesAPIClient interface {
+	DescribeInstanceTypeOfferings(context.Context, *ec2.DescribeInstanceTypeOfferingsInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceTypeOfferingsOutput, error)
+}
+
+// LookupInstanceTypeAvailabilityZones returns the sorted
+// names of the availability zones where the passed
+// instance type is offered.
+func LookupInstanceTypeAvailabilityZones(
+	ctx context.Context,
+	ec2Client LookupInstanceTypeAvailabilityZonesAPIClient,
+	instanceType string,
+) ([]string, error) {
+
+	describeInstanceTypeOfferingsResp, err := ec2Client.DescribeInstanceTypeOfferings(
+		ctx,
+		&ec2.DescribeInstanceTypeOfferingsInput{
+			LocationType: types.LocationTypeAvailabilityZone,
+			Filters: []types.Filter{{
+				Name:   aws.String("instance-type"),
+				Values: []string{instanceType},
+			}},
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	availabilityZones := []string{}
+
+	for _, offering := range describeInstanceTypeOfferingsResp.InstanceTypeOfferings {
+		availabilityZones = append(
+			availabilityZones,
+			aws.ToString(offering.Location),
+		)
+	}
+
+	sort.Strings(availabilityZones)
+
+	return availabilityZones, nil
+}
@@ -15,12 +15,23 @@ type InstanceTypeArch string
 const (
 	InstanceTypeArchArm64 = "arm64"
 	InstanceTypeArchX8664 = "x86_64"
+
+	InstanceUsageClassOnDemand = "on-demand"
+	InstanceUsageClassSpot     = "spot"
+
+	// HibernationMaxMemorySizeInMiB represents the maximum
+	// amount of RAM of the instances that could hibernate.
+	HibernationMaxMemorySizeInMiB = 150 * 1024
 )
 
 var (
 	ErrInvalidInstanceType     = errors.New("ErrInvalidInstanceType")
 	ErrInvalidInstanceTypeArch = errors.New("ErrInvalidInstanceTypeArch")
 
+	ErrInstanceTypeUsageClassNotSupported = errors.New("ErrInstanceTypeUsageClassNotSupported")
+
+	ErrInstanceTypeCannotHibernate = errors.New("ErrInstanceTypeCannotHibernate")
+
 	SupportedInstanceTypeArchs = []string{
 		string(InstanceTypeArchArm64),
 		string(InstanceTypeArchX8664),
@@ -28,17 +39,44 @@ var (
 )
 
 type InstanceTypeInfos struct {
-	Type string           `json:"type"`
-	Arch InstanceTypeArch `json:"arch"`
+	Type                 string           `json:"type"`
+	Arch                 InstanceTypeArch `json:"arch"`
+	MemorySizeInMiB      int64            `json:"memory_size_in_mib"`
+	HibernationSupported bool             `json:"hibernation_supported"`
+}
+
+// CheckHibernationSupport returns ErrInstanceTypeCannotHibernate
+// if the instance type cannot hibernate or has too much RAM.
+func (i *InstanceTypeInfos) CheckHibernationSupport() error {
+	if !i.HibernationSupported ||
+		i.MemorySizeInMiB > HibernationMaxMemorySizeInMiB {
+
+		return ErrInstanceTypeCannotHibernate
+	}
+
+	return nil
+}
+
+// HibernationRootVolumeSizeGb returns the size of a root volume
+// large enough to store the OS and the content of the RAM.
+func (i *InstanceTypeInfos) HibernationRootVolumeSizeGb(rootVolumeSizeGb int32) int32 {
+	memorySizeInGb := (i.MemorySizeInMiB + 1023) / 1024
+	return rootVolumeSizeGb + int32(memorySizeInGb)
+}
+
+type LookupInstanceTypeInfosAPIClient interface {
+	DescribeInstanceTypes(context.Context, *ec2.DescribeInstanceTypesInput, ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error)
 }
 
 func LookupInstanceTypeInfos(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client LookupInstanceTypeInfosAPIClient,
 	instanceType string,
+	usageClass string,
 ) (returnedInstanceTypeInfos *InstanceTypeInfos, returnedError error) {
 
 	describeInstanceTypesResp, err := ec2Client.DescribeInstanceTypes(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeInstanceTypesInput{
 			InstanceTypes: []types.InstanceType{
 				types.InstanceType(instanceType),
@@ -49,9 +87,6 @@ func LookupInstanceTypeInfos(
 			}, {
 				Name:   aws.String("supported-root-device-type"),
 				Values: []string{"ebs"},
-			}, {
-				Name:   aws.String("supported-usage-class"),
-				Values: []string{"on-demand"},
 			}},
 		},
 	)
@@ -78,10 +113,31 @@ func LookupInstanceTypeInfos(
 		return
 	}
 
+	// Checked after the lookup (instead of via the "supported-usage-class"
+	// filter) to distinguish this error from ErrInvalidInstanceTypeArch
+	usageClassSupported := false
+
+	for _, supportedUsageClass := range instanceTypes[0].SupportedUsageClasses {
+		usageClassSupported = usageClassSupported ||
+			string(supportedUsageClass) == usageClass
+	}
+
+	if !usageClassSupported {
+		returnedError = ErrInstanceTypeUsageClassNotSupported
+		return
+	}
+
 	supportedArchs := instanceTypes[0].ProcessorInfo.SupportedArchitectures
 
 	returnedInstanceTypeInfos = &InstanceTypeInfos{
-		Type: instanceType,
+		Type:                 instanceType,
+		HibernationSupported: aws.ToBool(instanceTypes[0].HibernationSupported),
+	}
+
+	if instanceTypes[0].MemoryInfo != nil {
+		returnedInstanceTypeInfos.MemorySizeInMiB = aws.ToInt64(
+			instanceTypes[0].MemoryInfo.SizeInMiB,
+		)
 	}
 
 	for _, supportedArch := range supportedArchs {
@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultPublicIPLookupURL represents the URL used
+// to look up the public IP address of the caller.
+const DefaultPublicIPLookupURL = "https://checkip.amazonaws.com"
+
+var ErrInvalidPublicIP = errors.New("ErrInvalidPublicIP")
+
+type PublicIPResolver interface {
+	LookupPublicIP(ctx context.Context) (net.IP, error)
+}
+
+// HTTPPublicIPResolver looks up the public IP address
+// of the caller using a service that returns it
+// as plain text (like checkip.amazonaws.com).
+type HTTPPublicIPResolver struct {
+	HTTPClient *http.Client
+	URL        string
+}
+
+func (h HTTPPublicIPResolver) LookupPublicIP(ctx context.Context) (net.IP, error) {
+	HTTPClient := h.HTTPClient
+
+	if HTTPClient == nil {
+		HTTPClient = http.DefaultClient
+	}
+
+	lookupURL := h.URL
+
+	if len(lookupURL) == 0 {
+		lookupURL = DefaultPublicIPLookupURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lookupURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := HTTPClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrInvalidPublicIP
+	}
+
+	// An IP address is at most 45 bytes long
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+
+	if err != nil {
+		return nil, err
+	}
+
+	publicIP := net.ParseIP(strings.TrimSpace(string(body)))
+
+	if publicIP == nil {
+		return nil, ErrInvalidPublicIP
+	}
+
+	return publicIP, nil
+}
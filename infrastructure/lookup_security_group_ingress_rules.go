@@ -0,0 +1,116 @@
+package infrastructure
+
+import (
+	"context"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type LookupSecurityGroupIngressRulesAPIClient interface {
+	ec2.DescribeSecurityGroupsAPIClient
+}
+
+// LookupSecurityGroupIngressRules returns the ingress rules of the
+// security group as port specs sorted by protocol, ports and description.
+// The IP ranges returned by AWS for the same port range
+// and description are merged.
+func LookupSecurityGroupIngressRules(
+	ctx context.Context,
+	ec2Client LookupSecurityGroupIngressRulesAPIClient,
+	securityGroupID string,
+) ([]PortSpec, error) {
+
+	describeSecurityGroupsResp, err := ec2Client.DescribeSecurityGroups(
+		ctx,
+		&ec2.DescribeSecurityGroupsInput{
+			GroupIds: []string{
+				securityGroupID,
+			},
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	type ruleKey struct {
+		protocol    string
+		fromPort    int32
+		toPort      int32
+		description string
+	}
+
+	portSpecs := []PortSpec{}
+	portSpecIndexes := map[ruleKey]int{}
+
+	addCIDR := func(IPPermission types.IpPermission, CIDR, description string) {
+		key := ruleKey{
+			protocol:    aws.ToString(IPPermission.IpProtocol),
+			fromPort:    aws.ToInt32(IPPermission.FromPort),
+			toPort:      aws.ToInt32(IPPermission.ToPort),
+			description: description,
+		}
+
+		portSpecIndex, ok := portSpecIndexes[key]
+
+		if !ok {
+			portSpecIndex = len(portSpecs)
+			portSpecIndexes[key] = portSpecIndex
+
+			portSpecs = append(portSpecs, PortSpec{
+				Protocol:    key.protocol,
+				FromPort:    key.fromPort,
+				ToPort:      key.toPort,
+				CIDRs:       []string{},
+				Description: key.description,
+			})
+		}
+
+		portSpecs[portSpecIndex].CIDRs = append(portSpecs[portSpecIndex].CIDRs, CIDR)
+	}
+
+	for _, securityGroup := range describeSecurityGroupsResp.SecurityGroups {
+		for _, IPPermission := range securityGroup.IpPermissions {
+			for _, IPRange := range IPPermission.IpRanges {
+				addCIDR(
+					IPPermission,
+					aws.ToString(IPRange.CidrIp),
+					aws.ToString(IPRange.Description),
+				)
+			}
+
+			for _, IPv6Range := range IPPermission.Ipv6Ranges {
+				addCIDR(
+					IPPermission,
+					aws.ToString(IPv6Range.CidrIpv6),
+					aws.ToString(IPv6Range.Description),
+				)
+			}
+		}
+	}
+
+	for _, portSpec := range portSpecs {
+		sort.Strings(portSpec.CIDRs)
+	}
+
+	sort.SliceStable(portSpecs, func(i, j int) bool {
+		if portSpecs[i].Protocol != portSpecs[j].Protocol {
+			return portSpecs[i].Protocol < portSpecs[j].Protocol
+		}
+
+		if portSpecs[i].FromPort != portSpecs[j].FromPort {
+			return portSpecs[i].FromPort < portSpecs[j].FromPort
+		}
+
+		if portSpecs[i].ToPort != portSpecs[j].ToPort {
+			return portSpecs[i].ToPort < portSpecs[j].ToPort
+		}
+
+		return portSpecs[i].Description < portSpecs[j].Description
+	})
+
+	return portSpecs, nil
+}
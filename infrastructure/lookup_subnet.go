@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+var ErrSubnetNotFound = errors.New("ErrSubnetNotFound")
+
+type LookupSubnetAPIClient interface {
+	DescribeSubnets(context.Context, *ec2.DescribeSubnetsInput, ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+}
+
+func LookupSubnet(
+	ctx context.Context,
+	ec2Client LookupSubnetAPIClient,
+	subnetID string,
+) (*Subnet, error) {
+
+	describeSubnetsResp, err := ec2Client.DescribeSubnets(
+		ctx,
+		&ec2.DescribeSubnetsInput{
+			SubnetIds: []string{subnetID},
+		},
+	)
+
+	if err != nil {
+		var APIErr smithy.APIError
+
+		if errors.As(err, &APIErr) && APIErr.ErrorCode() == "InvalidSubnetID.NotFound" {
+			return nil, ErrSubnetNotFound
+		}
+
+		return nil, err
+	}
+
+	if len(describeSubnetsResp.Subnets) == 0 {
+		return nil, ErrSubnetNotFound
+	}
+
+	subnet := describeSubnetsResp.Subnets[0]
+
+	returnedSubnet := &Subnet{
+		ID:               subnetID,
+		VPCID:            aws.ToString(subnet.VpcId),
+		CIDRBlock:        aws.ToString(subnet.CidrBlock),
+		AvailabilityZone: aws.ToString(subnet.AvailabilityZone),
+	}
+
+	for _, association := range subnet.Ipv6CidrBlockAssociationSet {
+		if association.Ipv6CidrBlockState != nil &&
+			association.Ipv6CidrBlockState.State == types.SubnetCidrBlockStateCodeAssociated {
+
+			returnedSubnet.IPv6CIDRBlock = aws.ToString(association.Ipv6CidrBlock)
+		}
+	}
+
+	return returnedSubnet, nil
+}
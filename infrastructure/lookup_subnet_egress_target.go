@@ -0,0 +1,89 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var ErrSubnetEgressRouteNotFound = errors.New("ErrSubnetEgressRouteNotFound")
+
+type LookupSubnetEgressTargetAPIClient interface {
+	DescribeRouteTables(context.Context, *ec2.DescribeRouteTablesInput, ...func(*ec2.Options)) (*ec2.DescribeRouteTablesOutput, error)
+}
+
+// LookupSubnetEgressTarget returns the ID of the target (internet
+// gateway, NAT gateway, transit gateway...) of the active IPv4
+// default route of the subnet. Subnets without an explicit route
+// table association use the main route table of the VPC.
+func LookupSubnetEgressTarget(
+	ctx context.Context,
+	ec2Client LookupSubnetEgressTargetAPIClient,
+	VPCID string,
+	subnetID string,
+) (string, error) {
+
+	describeRouteTablesResp, err := ec2Client.DescribeRouteTables(
+		ctx,
+		&ec2.DescribeRouteTablesInput{
+			Filters: []types.Filter{{
+				Name:   aws.String("association.subnet-id"),
+				Values: []string{subnetID},
+			}},
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	routeTables := describeRouteTablesResp.RouteTables
+
+	if len(routeTables) == 0 {
+		describeMainRouteTableResp, err := ec2Client.DescribeRouteTables(
+			ctx,
+			&ec2.DescribeRouteTablesInput{
+				Filters: []types.Filter{{
+					Name:   aws.String("vpc-id"),
+					Values: []string{VPCID},
+				}, {
+					Name:   aws.String("association.main"),
+					Values: []string{"true"},
+				}},
+			},
+		)
+
+		if err != nil {
+			return "", err
+		}
+
+		routeTables = describeMainRouteTableResp.RouteTables
+	}
+
+	for _, routeTable := range routeTables {
+		for _, route := range routeTable.Routes {
+			if aws.ToString(route.DestinationCidrBlock) != "0.0.0.0/0" ||
+				route.State != types.RouteStateActive {
+
+				continue
+			}
+
+			for _, targetID := range []*string{
+				route.GatewayId,
+				route.NatGatewayId,
+				route.TransitGatewayId,
+				route.NetworkInterfaceId,
+				route.VpcPeeringConnectionId,
+			} {
+				if len(aws.ToString(targetID)) > 0 {
+					return aws.ToString(targetID), nil
+				}
+			}
+		}
+	}
+
+	return "", ErrSubnetEgressRouteNotFound
+}
@@ -15,17 +15,49 @@ const (
 	UbuntuAMINamePatternArm64 = "ubuntu/images/hvm-ssd/ubuntu-jammy-22.04-arm64-server-*"
 )
 
+var (
+	ErrUnsupportedPartition = errors.New("ErrUnsupportedPartition")
+
+	// UbuntuAMIOwnerIDs maps the AWS partitions to the ID of the
+	// account used by Canonical to publish the Ubuntu AMIs.
+	UbuntuAMIOwnerIDs = map[string]string{
+		"aws":        "099720109477",
+		"aws-cn":     "837727238323",
+		"aws-us-gov": "513442679011",
+	}
+)
+
 type AMI struct {
 	ID             string `json:"id"`
 	RootUser       string `json:"root_user"`
 	RootDeviceName string `json:"root_device_name"`
+
+	// Arch is empty for the AMIs looked up before it was
+	// recorded (the AMI matches the instance type arch)
+	Arch InstanceTypeArch `json:"arch,omitempty"`
+
+	// Only the EBS-backed HVM AMIs support hibernation
+	HibernationSupported bool `json:"hibernation_supported"`
+}
+
+type LookupUbuntuAMIForArchAPIClient interface {
+	DescribeImages(context.Context, *ec2.DescribeImagesInput, ...func(*ec2.Options)) (*ec2.DescribeImagesOutput, error)
 }
 
 func LookupUbuntuAMIForArch(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client LookupUbuntuAMIForArchAPIClient,
+	partition string,
 	arch InstanceTypeArch,
 ) (returnedAMI *AMI, returnedError error) {
 
+	AMIOwnerID, ok := UbuntuAMIOwnerIDs[partition]
+
+	if !ok {
+		returnedError = ErrUnsupportedPartition
+		return
+	}
+
 	AMINamePattern := UbuntuAMINamePatternAmd64
 
 	if arch == InstanceTypeArchArm64 {
@@ -33,7 +65,7 @@ func LookupUbuntuAMIForArch(
 	}
 
 	describeImagesResp, err := ec2Client.DescribeImages(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeImagesInput{
 			Filters: []types.Filter{{
 				Name: aws.String("name"),
@@ -57,7 +89,7 @@ func LookupUbuntuAMIForArch(
 				},
 			}},
 			Owners: []string{
-				"099720109477",
+				AMIOwnerID,
 			},
 		},
 	)
@@ -85,6 +117,9 @@ func LookupUbuntuAMIForArch(
 		ID:             *mostRecentAMI.ImageId,
 		RootUser:       UbuntuAMIRootUser,
 		RootDeviceName: *mostRecentAMI.RootDeviceName,
+		Arch:           arch,
+		HibernationSupported: mostRecentAMI.RootDeviceType == types.DeviceTypeEbs &&
+			mostRecentAMI.VirtualizationType == types.VirtualizationTypeHvm,
 	}
 	return
 }
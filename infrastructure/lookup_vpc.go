@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+)
+
+var ErrVPCNotFound = errors.New("ErrVPCNotFound")
+
+type LookupVPCAPIClient interface {
+	DescribeVpcs(context.Context, *ec2.DescribeVpcsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
+}
+
+func LookupVPC(
+	ctx context.Context,
+	ec2Client LookupVPCAPIClient,
+	VPCID string,
+) (*VPC, error) {
+
+	describeVPCsResp, err := ec2Client.DescribeVpcs(
+		ctx,
+		&ec2.DescribeVpcsInput{
+			VpcIds: []string{VPCID},
+		},
+	)
+
+	if err != nil {
+		var APIErr smithy.APIError
+
+		if errors.As(err, &APIErr) && APIErr.ErrorCode() == "InvalidVpcID.NotFound" {
+			return nil, ErrVPCNotFound
+		}
+
+		return nil, err
+	}
+
+	if len(describeVPCsResp.Vpcs) == 0 {
+		return nil, ErrVPCNotFound
+	}
+
+	return &VPC{
+		ID:        VPCID,
+		CIDRBlock: aws.ToString(describeVPCsResp.Vpcs[0].CidrBlock),
+	}, nil
+}
@@ -2,30 +2,35 @@ package infrastructure
 
 import (
 	"context"
-	"strconv"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
+type OpenInstancePortAPIClient interface {
+	AuthorizeSecurityGroupIngress(context.Context, *ec2.AuthorizeSecurityGroupIngressInput, ...func(*ec2.Options)) (*ec2.AuthorizeSecurityGroupIngressOutput, error)
+}
+
 func OpenInstancePort(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client OpenInstancePortAPIClient,
 	securityGroupID string,
-	portToOpen string,
+	portToOpen PortSpec,
 ) error {
 
-	portToOpenAsInt, _ := strconv.Atoi(portToOpen)
-
 	_, err := ec2Client.AuthorizeSecurityGroupIngress(
-		context.TODO(),
+		ctx,
 		&ec2.AuthorizeSecurityGroupIngressInput{
-			CidrIp:     aws.String("0.0.0.0/0"),
-			FromPort:   aws.Int32(int32(portToOpenAsInt)),
-			ToPort:     aws.Int32(int32(portToOpenAsInt)),
-			GroupId:    &securityGroupID,
-			IpProtocol: aws.String("tcp"),
+			GroupId: &securityGroupID,
+			IpPermissions: []types.IpPermission{
+				portToOpen.IPPermission(),
+			},
 		},
 	)
 
-	return err
+	if err != nil {
+		return convertIngressRuleError(err)
+	}
+
+	return nil
 }
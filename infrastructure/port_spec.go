@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	PortSpecProtocolTCP = "tcp"
+	PortSpecProtocolUDP = "udp"
+)
+
+var (
+	ErrDuplicateIngressRule = errors.New("ErrDuplicateIngressRule")
+	ErrIngressRuleNotFound  = errors.New("ErrIngressRuleNotFound")
+)
+
+// PortSpec represents a range of ports (FromPort and ToPort
+// are equal for single ports) reachable from CIDRs.
+//
+// Description is set on each IP range of the rule.
+type PortSpec struct {
+	Protocol    string   `json:"protocol"`
+	FromPort    int32    `json:"from_port"`
+	ToPort      int32    `json:"to_port"`
+	CIDRs       []string `json:"cidrs"`
+	Description string   `json:"description,omitempty"`
+}
+
+func (p PortSpec) IPPermission() types.IpPermission {
+	IPPermission := BuildIngressIPPermission(
+		p.Protocol,
+		p.FromPort,
+		p.ToPort,
+		p.CIDRs,
+	)
+
+	if len(p.Description) == 0 {
+		return IPPermission
+	}
+
+	for IPRangeIndex := range IPPermission.IpRanges {
+		IPPermission.IpRanges[IPRangeIndex].Description = aws.String(p.Description)
+	}
+
+	for IPv6RangeIndex := range IPPermission.Ipv6Ranges {
+		IPPermission.Ipv6Ranges[IPv6RangeIndex].Description = aws.String(p.Description)
+	}
+
+	return IPPermission
+}
+
+// ReferencesAnyCIDR returns true if one of the
+// CIDRs to look up is present in the passed ones.
+func ReferencesAnyCIDR(CIDRs []string, CIDRsToLookup []string) bool {
+	for _, CIDR := range CIDRs {
+		for _, CIDRToLookup := range CIDRsToLookup {
+			if CIDR == CIDRToLookup {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// convertIngressRuleError converts the errors returned by
+// AWS when authorizing an existing rule or revoking an unknown one.
+func convertIngressRuleError(err error) error {
+	var APIErr smithy.APIError
+
+	if !errors.As(err, &APIErr) {
+		return err
+	}
+
+	switch APIErr.ErrorCode() {
+	case "InvalidPermission.Duplicate":
+		return ErrDuplicateIngressRule
+	case "InvalidPermission.NotFound":
+		return ErrIngressRuleNotFound
+	}
+
+	return err
+}
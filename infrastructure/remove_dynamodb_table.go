@@ -2,17 +2,26 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
+type RemoveDynamoDBTableForElevenConfigAPIClient interface {
+	dynamodb.DescribeTableAPIClient
+
+	DeleteTable(context.Context, *dynamodb.DeleteTableInput, ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+}
+
 func RemoveDynamoDBTableForElevenConfig(
-	dynamoDBClient *dynamodb.Client,
+	ctx context.Context,
+	dynamoDBClient RemoveDynamoDBTableForElevenConfigAPIClient,
+	waitOpts WaitOpts,
 ) error {
 
-	_, err := dynamoDBClient.DeleteTable(context.TODO(), &dynamodb.DeleteTableInput{
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := dynamoDBClient.DeleteTable(ctx, &dynamodb.DeleteTableInput{
 		TableName: aws.String(DynamoDBElevenConfigTableName),
 	})
 
@@ -20,10 +29,13 @@ func RemoveDynamoDBTableForElevenConfig(
 		return err
 	}
 
-	waiter := dynamodb.NewTableNotExistsWaiter(dynamoDBClient)
-	maxWaitTime := 5 * time.Minute
+	waiter := dynamodb.NewTableNotExistsWaiter(dynamoDBClient, func(o *dynamodb.TableNotExistsWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	return waiter.Wait(context.TODO(), &dynamodb.DescribeTableInput{
+	return waiter.Wait(ctx, &dynamodb.DescribeTableInput{
 		TableName: aws.String(DynamoDBElevenConfigTableName),
 	}, maxWaitTime)
 }
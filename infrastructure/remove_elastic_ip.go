@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveElasticIPAPIClient interface {
+	ReleaseAddress(context.Context, *ec2.ReleaseAddressInput, ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error)
+}
+
 func RemoveElasticIP(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveElasticIPAPIClient,
 	elasticIPId string,
 ) error {
 
 	_, err := ec2Client.ReleaseAddress(
-		context.TODO(),
+		ctx,
 		&ec2.ReleaseAddressInput{
 			AllocationId: &elasticIPId,
 		},
@@ -2,17 +2,26 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type TerminateInstanceAPIClient interface {
+	ec2.DescribeInstancesAPIClient
+
+	TerminateInstances(context.Context, *ec2.TerminateInstancesInput, ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error)
+}
+
 func TerminateInstance(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client TerminateInstanceAPIClient,
+	waitOpts WaitOpts,
 	instanceID string,
 ) error {
 
-	_, err := ec2Client.TerminateInstances(context.TODO(), &ec2.TerminateInstancesInput{
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := ec2Client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{
 		InstanceIds: []string{instanceID},
 	})
 
@@ -20,11 +29,14 @@ func TerminateInstance(
 		return err
 	}
 
-	terminatedWaiter := ec2.NewInstanceTerminatedWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	terminatedWaiter := ec2.NewInstanceTerminatedWaiter(ec2Client, func(o *ec2.InstanceTerminatedWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	return terminatedWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeInstancesInput{
 			InstanceIds: []string{
 				instanceID,
@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveInternetGatewayAPIClient interface {
+	DeleteInternetGateway(context.Context, *ec2.DeleteInternetGatewayInput, ...func(*ec2.Options)) (*ec2.DeleteInternetGatewayOutput, error)
+}
+
 func RemoveInternetGateway(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveInternetGatewayAPIClient,
 	internetGatewayId string,
 ) error {
 
 	_, err := ec2Client.DeleteInternetGateway(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteInternetGatewayInput{
 			InternetGatewayId: &internetGatewayId,
 		},
@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveKeyPairAPIClient interface {
+	DeleteKeyPair(context.Context, *ec2.DeleteKeyPairInput, ...func(*ec2.Options)) (*ec2.DeleteKeyPairOutput, error)
+}
+
 func RemoveKeyPair(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveKeyPairAPIClient,
 	keyPairID string,
 ) error {
 
 	_, err := ec2Client.DeleteKeyPair(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteKeyPairInput{
 			KeyPairId: &keyPairID,
 		},
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+var ErrNATGatewayNotDeleted = errors.New("ErrNATGatewayNotDeleted")
+
+type RemoveNATGatewayAPIClient interface {
+	ec2.DescribeNatGatewaysAPIClient
+
+	DeleteNatGateway(context.Context, *ec2.DeleteNatGatewayInput, ...func(*ec2.Options)) (*ec2.DeleteNatGatewayOutput, error)
+}
+
+// RemoveNATGateway removes the NAT gateway and waits for it
+// to be deleted (its elastic IP cannot be released before).
+func RemoveNATGateway(
+	ctx context.Context,
+	ec2Client RemoveNATGatewayAPIClient,
+	waitOpts WaitOpts,
+	NATGatewayID string,
+) error {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := ec2Client.DeleteNatGateway(
+		ctx,
+		&ec2.DeleteNatGatewayInput{
+			NatGatewayId: &NATGatewayID,
+		},
+	)
+
+	if err != nil {
+		var APIErr smithy.APIError
+
+		if errors.As(err, &APIErr) && APIErr.ErrorCode() == "NatGatewayNotFound" {
+			return nil
+		}
+
+		return err
+	}
+
+	pollTimeoutChan := time.After(waitOpts.ResourceMaxWaitTime)
+	pollAttempt := 0
+
+	for {
+		describeNATGatewaysResp, err := ec2Client.DescribeNatGateways(
+			ctx,
+			&ec2.DescribeNatGatewaysInput{
+				NatGatewayIds: []string{
+					NATGatewayID,
+				},
+			},
+		)
+
+		if err != nil {
+			return err
+		}
+
+		isDeleted := true
+
+		for _, gateway := range describeNATGatewaysResp.NatGateways {
+			if gateway.State != types.NatGatewayStateDeleted {
+				isDeleted = false
+			}
+		}
+
+		if isDeleted {
+			return nil
+		}
+
+		pollAttempt++
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-pollTimeoutChan:
+			return ErrNATGatewayNotDeleted
+		case <-time.After(waitOpts.pollDelay(pollAttempt)):
+		}
+	}
+}
@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveNetworkInterfaceAPIClient interface {
+	DeleteNetworkInterface(context.Context, *ec2.DeleteNetworkInterfaceInput, ...func(*ec2.Options)) (*ec2.DeleteNetworkInterfaceOutput, error)
+}
+
 func RemoveNetworkInterface(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveNetworkInterfaceAPIClient,
 	networkInterfaceID string,
 ) error {
 
 	_, err := ec2Client.DeleteNetworkInterface(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteNetworkInterfaceInput{
 			NetworkInterfaceId: &networkInterfaceID,
 		},
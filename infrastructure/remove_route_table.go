@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveRouteTableAPIClient interface {
+	DeleteRouteTable(context.Context, *ec2.DeleteRouteTableInput, ...func(*ec2.Options)) (*ec2.DeleteRouteTableOutput, error)
+}
+
 func RemoveRouteTable(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveRouteTableAPIClient,
 	routeTableID string,
 ) error {
 
 	_, err := ec2Client.DeleteRouteTable(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteRouteTableInput{
 			RouteTableId: &routeTableID,
 		},
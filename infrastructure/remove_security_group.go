@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveSecurityGroupAPIClient interface {
+	DeleteSecurityGroup(context.Context, *ec2.DeleteSecurityGroupInput, ...func(*ec2.Options)) (*ec2.DeleteSecurityGroupOutput, error)
+}
+
 func RemoveSecurityGroup(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveSecurityGroupAPIClient,
 	securityGroupID string,
 ) error {
 
 	_, err := ec2Client.DeleteSecurityGroup(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteSecurityGroupInput{
 			GroupId: &securityGroupID,
 		},
@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveSubnetAPIClient interface {
+	DeleteSubnet(context.Context, *ec2.DeleteSubnetInput, ...func(*ec2.Options)) (*ec2.DeleteSubnetOutput, error)
+}
+
 func RemoveSubnet(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveSubnetAPIClient,
 	subnetID string,
 ) error {
 
 	_, err := ec2Client.DeleteSubnet(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteSubnetInput{
 			SubnetId: &subnetID,
 		},
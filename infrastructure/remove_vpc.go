@@ -6,13 +6,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 )
 
+type RemoveVPCAPIClient interface {
+	DeleteVpc(context.Context, *ec2.DeleteVpcInput, ...func(*ec2.Options)) (*ec2.DeleteVpcOutput, error)
+}
+
 func RemoveVPC(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveVPCAPIClient,
 	VPCID string,
 ) error {
 
 	_, err := ec2Client.DeleteVpc(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteVpcInput{
 			VpcId: &VPCID,
 		},
@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+)
+
+var ErrSpotInstanceRequestNotFound = errors.New("ErrSpotInstanceRequestNotFound")
+
+// SpotInstanceRequest represents the persistent
+// spot request used to launch an instance.
+type SpotInstanceRequest struct {
+	ID string `json:"id"`
+
+	// Empty when the max price
+	// is the on-demand price
+	MaxPrice string `json:"max_price,omitempty"`
+}
+
+// SpotInstanceRequestStatus represents the status of a spot request
+// (see https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/spot-request-status.html).
+type SpotInstanceRequestStatus struct {
+	State      string
+	StatusCode string
+	Message    string
+	UpdateTime time.Time
+}
+
+// IsInterruptionNotice returns true if the instance is about
+// to be interrupted (the notice is sent two minutes before).
+func (s *SpotInstanceRequestStatus) IsInterruptionNotice() bool {
+	return strings.HasPrefix(s.StatusCode, "marked-for-")
+}
+
+// spotInterruptionStatusCodes lists the status codes set when
+// EC2 interrupts the instance. The "instance-stopped-by-user"
+// and "instance-terminated-by-user" codes are not interruptions.
+var spotInterruptionStatusCodes = []string{
+	"instance-stopped-by-price",
+	"instance-stopped-no-capacity",
+	"instance-stopped-capacity-oversubscribed",
+	"instance-terminated-by-price",
+	"instance-terminated-no-capacity",
+	"instance-terminated-capacity-oversubscribed",
+	"instance-terminated-launch-group-constraint",
+	"instance-terminated-by-service",
+}
+
+// IsInterrupted returns true if the instance was
+// interrupted by EC2 (for price, capacity...).
+func (s *SpotInstanceRequestStatus) IsInterrupted() bool {
+	for _, statusCode := range spotInterruptionStatusCodes {
+		if s.StatusCode == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+type LookupSpotInstanceRequestStatusAPIClient interface {
+	DescribeSpotInstanceRequests(context.Context, *ec2.DescribeSpotInstanceRequestsInput, ...func(*ec2.Options)) (*ec2.DescribeSpotInstanceRequestsOutput, error)
+}
+
+func LookupSpotInstanceRequestStatus(
+	ctx context.Context,
+	ec2Client LookupSpotInstanceRequestStatusAPIClient,
+	spotInstanceRequestID string,
+) (*SpotInstanceRequestStatus, error) {
+
+	describeSpotRequestsResp, err := ec2Client.DescribeSpotInstanceRequests(
+		ctx,
+		&ec2.DescribeSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []string{spotInstanceRequestID},
+		},
+	)
+
+	if err != nil {
+		if isSpotInstanceRequestNotFoundError(err) {
+			return nil, ErrSpotInstanceRequestNotFound
+		}
+
+		return nil, err
+	}
+
+	if len(describeSpotRequestsResp.SpotInstanceRequests) == 0 {
+		return nil, ErrSpotInstanceRequestNotFound
+	}
+
+	spotRequest := describeSpotRequestsResp.SpotInstanceRequests[0]
+	spotRequestStatus := &SpotInstanceRequestStatus{
+		State: string(spotRequest.State),
+	}
+
+	if spotRequest.Status != nil {
+		spotRequestStatus.StatusCode = aws.ToString(spotRequest.Status.Code)
+		spotRequestStatus.Message = aws.ToString(spotRequest.Status.Message)
+		spotRequestStatus.UpdateTime = aws.ToTime(spotRequest.Status.UpdateTime)
+	}
+
+	return spotRequestStatus, nil
+}
+
+type CancelSpotInstanceRequestAPIClient interface {
+	CancelSpotInstanceRequests(context.Context, *ec2.CancelSpotInstanceRequestsInput, ...func(*ec2.Options)) (*ec2.CancelSpotInstanceRequestsOutput, error)
+}
+
+// CancelSpotInstanceRequest cancels the spot request so that
+// no instance is launched once its instance is terminated.
+// The instance is not terminated.
+func CancelSpotInstanceRequest(
+	ctx context.Context,
+	ec2Client CancelSpotInstanceRequestAPIClient,
+	spotInstanceRequestID string,
+) error {
+
+	_, err := ec2Client.CancelSpotInstanceRequests(
+		ctx,
+		&ec2.CancelSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []string{spotInstanceRequestID},
+		},
+	)
+
+	if err != nil && isSpotInstanceRequestNotFoundError(err) {
+		return nil
+	}
+
+	return err
+}
+
+func isSpotInstanceRequestNotFoundError(err error) bool {
+	var APIErr smithy.APIError
+
+	return errors.As(err, &APIErr) &&
+		APIErr.ErrorCode() == "InvalidSpotInstanceRequestID.NotFound"
+}
@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+type StartInstanceAPIClient interface {
+	ec2.DescribeInstancesAPIClient
+
+	StartInstances(context.Context, *ec2.StartInstancesInput, ...func(*ec2.Options)) (*ec2.StartInstancesOutput, error)
+}
+
+// StartInstance starts the stopped
+// instance and waits for the running state.
+func StartInstance(
+	ctx context.Context,
+	ec2Client StartInstanceAPIClient,
+	waitOpts WaitOpts,
+	instanceID string,
+) error {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := ec2Client.StartInstances(ctx, &ec2.StartInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	runningWaiter := ec2.NewInstanceRunningWaiter(ec2Client, func(o *ec2.InstanceRunningWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
+
+	return runningWaiter.Wait(
+		ctx,
+		&ec2.DescribeInstancesInput{
+			InstanceIds: []string{
+				instanceID,
+			},
+		},
+		maxWaitTime,
+	)
+}
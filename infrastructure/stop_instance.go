@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+type StopInstanceAPIClient interface {
+	ec2.DescribeInstancesAPIClient
+
+	StopInstances(context.Context, *ec2.StopInstancesInput, ...func(*ec2.Options)) (*ec2.StopInstancesOutput, error)
+}
+
+// StopInstance stops the instance and waits for the stopped state.
+// The root volume and the elastic IP association are kept.
+//
+// When hibernate is set, the content of the RAM is saved to the
+// root volume (the instance must have been created with hibernation).
+func StopInstance(
+	ctx context.Context,
+	ec2Client StopInstanceAPIClient,
+	waitOpts WaitOpts,
+	instanceID string,
+	hibernate bool,
+) error {
+
+	waitOpts = waitOpts.WithDefaults()
+
+	_, err := ec2Client.StopInstances(ctx, &ec2.StopInstancesInput{
+		InstanceIds: []string{instanceID},
+		Hibernate:   aws.Bool(hibernate),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	stoppedWaiter := ec2.NewInstanceStoppedWaiter(ec2Client, func(o *ec2.InstanceStoppedWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
+
+	return stoppedWaiter.Wait(
+		ctx,
+		&ec2.DescribeInstancesInput{
+			InstanceIds: []string{
+				instanceID,
+			},
+		},
+		maxWaitTime,
+	)
+}
@@ -8,8 +8,13 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
+type UpdateElevenConfigInDynamoDBTableAPIClient interface {
+	PutItem(context.Context, *dynamodb.PutItemInput, ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
 func UpdateElevenConfigInDynamoDBTable(
-	dynamoDBClient *dynamodb.Client,
+	ctx context.Context,
+	dynamoDBClient UpdateElevenConfigInDynamoDBTableAPIClient,
 	configID string,
 	configJSON string,
 ) error {
@@ -25,7 +30,7 @@ func UpdateElevenConfigInDynamoDBTable(
 		return err
 	}
 
-	_, err = dynamoDBClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+	_, err = dynamoDBClient.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(DynamoDBElevenConfigTableName),
 		Item:      marshaledConfigRecord,
 	})
@@ -0,0 +1,32 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type UpdateInstanceTypeAPIClient interface {
+	ModifyInstanceAttribute(context.Context, *ec2.ModifyInstanceAttributeInput, ...func(*ec2.Options)) (*ec2.ModifyInstanceAttributeOutput, error)
+}
+
+// UpdateInstanceType changes the type of the instance.
+// The instance must be stopped.
+func UpdateInstanceType(
+	ctx context.Context,
+	ec2Client UpdateInstanceTypeAPIClient,
+	instanceID string,
+	instanceType string,
+) error {
+
+	_, err := ec2Client.ModifyInstanceAttribute(ctx, &ec2.ModifyInstanceAttributeInput{
+		InstanceId: aws.String(instanceID),
+		InstanceType: &types.AttributeValue{
+			Value: aws.String(instanceType),
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,119 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type UpdateSecurityGroupIngressCIDRsAPIClient interface {
+	LookupSecurityGroupIngressRulesAPIClient
+	OpenInstancePortAPIClient
+	CloseInstancePortAPIClient
+}
+
+// UpdateSecurityGroupIngressCIDRs replaces the old CIDRs
+// with the new ones in all the ingress rules of the
+// security group that reference them.
+func UpdateSecurityGroupIngressCIDRs(
+	ctx context.Context,
+	ec2Client UpdateSecurityGroupIngressCIDRsAPIClient,
+	securityGroupID string,
+	oldCIDRs []string,
+	newCIDRs []string,
+) error {
+
+	ingressRules, err := LookupSecurityGroupIngressRules(
+		ctx,
+		ec2Client,
+		securityGroupID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	isNewCIDR := map[string]bool{}
+	for _, CIDR := range newCIDRs {
+		isNewCIDR[CIDR] = true
+	}
+
+	for _, ingressRule := range ingressRules {
+		CIDRs := map[string]bool{}
+		for _, CIDR := range ingressRule.CIDRs {
+			CIDRs[CIDR] = true
+		}
+
+		// Rules that don't reference the old
+		// CIDRs were not added by Eleven
+		if !ReferencesAnyCIDR(ingressRule.CIDRs, oldCIDRs) {
+			continue
+		}
+
+		CIDRsToRevoke := []string{}
+
+		for _, CIDR := range oldCIDRs {
+			if CIDRs[CIDR] && !isNewCIDR[CIDR] {
+				CIDRsToRevoke = append(CIDRsToRevoke, CIDR)
+			}
+		}
+
+		CIDRsToAuthorize := []string{}
+
+		for _, CIDR := range newCIDRs {
+			if !CIDRs[CIDR] {
+				CIDRsToAuthorize = append(CIDRsToAuthorize, CIDR)
+			}
+		}
+
+		// New CIDRs are authorized first to
+		// never leave the port unreachable
+		if len(CIDRsToAuthorize) > 0 {
+			_, err := ec2Client.AuthorizeSecurityGroupIngress(
+				ctx,
+				&ec2.AuthorizeSecurityGroupIngressInput{
+					GroupId: aws.String(securityGroupID),
+					IpPermissions: []types.IpPermission{
+						PortSpec{
+							Protocol:    ingressRule.Protocol,
+							FromPort:    ingressRule.FromPort,
+							ToPort:      ingressRule.ToPort,
+							CIDRs:       CIDRsToAuthorize,
+							Description: ingressRule.Description,
+						}.IPPermission(),
+					},
+				},
+			)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(CIDRsToRevoke) > 0 {
+			_, err := ec2Client.RevokeSecurityGroupIngress(
+				ctx,
+				&ec2.RevokeSecurityGroupIngressInput{
+					GroupId: aws.String(securityGroupID),
+					IpPermissions: []types.IpPermission{
+						PortSpec{
+							Protocol:    ingressRule.Protocol,
+							FromPort:    ingressRule.FromPort,
+							ToPort:      ingressRule.ToPort,
+							CIDRs:       CIDRsToRevoke,
+							Description: ingressRule.Description,
+						}.IPPermission(),
+					},
+				},
+			)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
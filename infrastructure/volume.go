@@ -2,7 +2,6 @@ package infrastructure
 
 import (
 	"context"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -14,15 +13,25 @@ type CreateVolumeFromSnapshotResp struct {
 	VolumeID string
 }
 
+type CreateVolumeFromSnapshotAPIClient interface {
+	ec2.DescribeVolumesAPIClient
+
+	CreateVolume(context.Context, *ec2.CreateVolumeInput, ...func(*ec2.Options)) (*ec2.CreateVolumeOutput, error)
+}
+
 func CreateVolumeFromSnapshot(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateVolumeFromSnapshotAPIClient,
+	waitOpts WaitOpts,
 	name string,
 	availabilityZone string,
 	snapshotID string,
 ) (resp CreateVolumeFromSnapshotResp) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	createVolumeResp, err := ec2Client.CreateVolume(
-		context.TODO(),
+		ctx,
 		&ec2.CreateVolumeInput{
 			AvailabilityZone: &availabilityZone,
 			SnapshotId:       &snapshotID,
@@ -42,10 +51,13 @@ func CreateVolumeFromSnapshot(
 		return
 	}
 
-	availableWaiter := ec2.NewVolumeAvailableWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	availableWaiter := ec2.NewVolumeAvailableWaiter(ec2Client, func(o *ec2.VolumeAvailableWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
-	err = availableWaiter.Wait(context.TODO(), &ec2.DescribeVolumesInput{
+	err = availableWaiter.Wait(ctx, &ec2.DescribeVolumesInput{
 		VolumeIds: []string{
 			*createVolumeResp.VolumeId,
 		},
@@ -64,13 +76,23 @@ type RemoveVolumeResp struct {
 	Err error
 }
 
+type RemoveVolumeAPIClient interface {
+	ec2.DescribeVolumesAPIClient
+
+	DeleteVolume(context.Context, *ec2.DeleteVolumeInput, ...func(*ec2.Options)) (*ec2.DeleteVolumeOutput, error)
+}
+
 func RemoveVolume(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveVolumeAPIClient,
+	waitOpts WaitOpts,
 	volumeID string,
 ) (resp RemoveVolumeResp) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	_, err := ec2Client.DeleteVolume(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteVolumeInput{
 			VolumeId: &volumeID,
 		},
@@ -81,11 +103,14 @@ func RemoveVolume(
 		return
 	}
 
-	deletedWaiter := ec2.NewVolumeDeletedWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	deletedWaiter := ec2.NewVolumeDeletedWaiter(ec2Client, func(o *ec2.VolumeDeletedWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	resp.Err = deletedWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeVolumesInput{
 			VolumeIds: []string{
 				volumeID,
@@ -101,15 +126,25 @@ type DetachVolumeResp struct {
 	Err error
 }
 
+type DetachVolumeAPIClient interface {
+	ec2.DescribeVolumesAPIClient
+
+	DetachVolume(context.Context, *ec2.DetachVolumeInput, ...func(*ec2.Options)) (*ec2.DetachVolumeOutput, error)
+}
+
 func DetachVolume(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client DetachVolumeAPIClient,
+	waitOpts WaitOpts,
 	instanceID string,
 	volumeID string,
 	deviceName string,
 ) (resp DetachVolumeResp) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	_, err := ec2Client.DetachVolume(
-		context.TODO(),
+		ctx,
 		&ec2.DetachVolumeInput{
 			InstanceId: &instanceID,
 			VolumeId:   &volumeID,
@@ -122,11 +157,14 @@ func DetachVolume(
 		return
 	}
 
-	availableWaiter := ec2.NewVolumeAvailableWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	availableWaiter := ec2.NewVolumeAvailableWaiter(ec2Client, func(o *ec2.VolumeAvailableWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	resp.Err = availableWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeVolumesInput{
 			VolumeIds: []string{
 				volumeID,
@@ -142,15 +180,25 @@ type AttachVolumeResp struct {
 	Err error
 }
 
+type AttachVolumeAPIClient interface {
+	ec2.DescribeVolumesAPIClient
+
+	AttachVolume(context.Context, *ec2.AttachVolumeInput, ...func(*ec2.Options)) (*ec2.AttachVolumeOutput, error)
+}
+
 func AttachVolume(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client AttachVolumeAPIClient,
+	waitOpts WaitOpts,
 	instanceID string,
 	volumeID string,
 	deviceName string,
 ) (resp AttachVolumeResp) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	_, err := ec2Client.AttachVolume(
-		context.TODO(),
+		ctx,
 		&ec2.AttachVolumeInput{
 			InstanceId: &instanceID,
 			VolumeId:   &volumeID,
@@ -163,11 +211,14 @@ func AttachVolume(
 		return
 	}
 
-	inUseWaiter := ec2.NewVolumeInUseWaiter(ec2Client)
-	maxWaitTime := 5 * time.Minute
+	inUseWaiter := ec2.NewVolumeInUseWaiter(ec2Client, func(o *ec2.VolumeInUseWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.ResourceMaxWaitTime
 
 	resp.Err = inUseWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeVolumesInput{
 			VolumeIds: []string{
 				volumeID,
@@ -184,14 +235,24 @@ type CreateSnapshotForVolumeResp struct {
 	SnapshotID string
 }
 
+type CreateSnapshotForVolumeAPIClient interface {
+	ec2.DescribeSnapshotsAPIClient
+
+	CreateSnapshot(context.Context, *ec2.CreateSnapshotInput, ...func(*ec2.Options)) (*ec2.CreateSnapshotOutput, error)
+}
+
 func CreateSnapshotForVolume(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client CreateSnapshotForVolumeAPIClient,
+	waitOpts WaitOpts,
 	name string,
 	volumeID string,
 ) (resp CreateSnapshotForVolumeResp) {
 
+	waitOpts = waitOpts.WithDefaults()
+
 	createSnapshotResp, err := ec2Client.CreateSnapshot(
-		context.TODO(),
+		ctx,
 		&ec2.CreateSnapshotInput{
 			VolumeId: &volumeID,
 			TagSpecifications: []types.TagSpecification{{
@@ -211,11 +272,14 @@ func CreateSnapshotForVolume(
 
 	snapshotID := *createSnapshotResp.SnapshotId
 
-	completedWaiter := ec2.NewSnapshotCompletedWaiter(ec2Client)
-	maxWaitTime := 24 * time.Hour
+	completedWaiter := ec2.NewSnapshotCompletedWaiter(ec2Client, func(o *ec2.SnapshotCompletedWaiterOptions) {
+		o.MinDelay = waitOpts.MinPollInterval
+		o.MaxDelay = waitOpts.MaxPollInterval
+	})
+	maxWaitTime := waitOpts.SnapshotMaxWaitTime
 
 	err = completedWaiter.Wait(
-		context.TODO(),
+		ctx,
 		&ec2.DescribeSnapshotsInput{
 			SnapshotIds: []string{
 				snapshotID,
@@ -237,13 +301,18 @@ type RemoveVolumeSnapshotResp struct {
 	Err error
 }
 
+type RemoveVolumeSnapshotAPIClient interface {
+	DeleteSnapshot(context.Context, *ec2.DeleteSnapshotInput, ...func(*ec2.Options)) (*ec2.DeleteSnapshotOutput, error)
+}
+
 func RemoveVolumeSnapshot(
-	ec2Client *ec2.Client,
+	ctx context.Context,
+	ec2Client RemoveVolumeSnapshotAPIClient,
 	snapshotID string,
 ) (resp RemoveVolumeSnapshotResp) {
 
 	_, err := ec2Client.DeleteSnapshot(
-		context.TODO(),
+		ctx,
 		&ec2.DeleteSnapshotInput{
 			SnapshotId: &snapshotID,
 		},
@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	DefaultResourceMaxWaitTime    = 5 * time.Minute
+	DefaultSnapshotMaxWaitTime    = 24 * time.Hour
+	DefaultInitScriptMaxWaitTime  = 4 * time.Minute
+	DefaultSSHHostKeysMaxWaitTime = 10 * time.Minute
+	DefaultSSHMaxWaitTime         = 4 * time.Minute
+	DefaultSSHDialTimeout         = 8 * time.Second
+	DefaultMinPollInterval        = 4 * time.Second
+	DefaultMaxPollInterval        = 30 * time.Second
+)
+
+// WaitOpts represents the options used to configure
+// how long and how often the infrastructure helpers
+// poll resources until they reach the expected state.
+// The helpers apply WithDefaults themselves, so the zero
+// value may be passed to use the default options.
+type WaitOpts struct {
+	// ResourceMaxWaitTime specifies how long to wait for resources
+	// (VPCs, instances, volumes...) to reach the expected state.
+	// Default to DefaultResourceMaxWaitTime if not set.
+	ResourceMaxWaitTime time.Duration
+
+	// SnapshotMaxWaitTime specifies how long to wait for volume snapshots to complete.
+	// Default to DefaultSnapshotMaxWaitTime if not set.
+	SnapshotMaxWaitTime time.Duration
+
+	// InitScriptMaxWaitTime specifies how long to wait for the instance init script to end.
+	// Default to DefaultInitScriptMaxWaitTime if not set.
+	InitScriptMaxWaitTime time.Duration
+
+	// SSHHostKeysMaxWaitTime specifies how long to wait for the SSH host
+	// keys to be printed in the console output of the instance.
+	// Default to DefaultSSHHostKeysMaxWaitTime if not set.
+	SSHHostKeysMaxWaitTime time.Duration
+
+	// SSHMaxWaitTime specifies how long to wait for the instance to be reachable over SSH.
+	// Default to DefaultSSHMaxWaitTime if not set.
+	SSHMaxWaitTime time.Duration
+
+	// SSHDialTimeout specifies the timeout of each SSH connection attempt.
+	// Default to DefaultSSHDialTimeout if not set.
+	SSHDialTimeout time.Duration
+
+	// MinPollInterval specifies the delay between the first two polls.
+	// The delay is then doubled after each poll, with jitter, up to MaxPollInterval.
+	// Default to DefaultMinPollInterval if not set.
+	MinPollInterval time.Duration
+
+	// MaxPollInterval specifies the maximum delay between two polls.
+	// Default to DefaultMaxPollInterval (or MinPollInterval if greater) if not set.
+	MaxPollInterval time.Duration
+}
+
+// WithDefaults returns a copy of the options
+// where unset values are set to their default.
+func (w WaitOpts) WithDefaults() WaitOpts {
+	if w.ResourceMaxWaitTime <= 0 {
+		w.ResourceMaxWaitTime = DefaultResourceMaxWaitTime
+	}
+
+	if w.SnapshotMaxWaitTime <= 0 {
+		w.SnapshotMaxWaitTime = DefaultSnapshotMaxWaitTime
+	}
+
+	if w.InitScriptMaxWaitTime <= 0 {
+		w.InitScriptMaxWaitTime = DefaultInitScriptMaxWaitTime
+	}
+
+	if w.SSHHostKeysMaxWaitTime <= 0 {
+		w.SSHHostKeysMaxWaitTime = DefaultSSHHostKeysMaxWaitTime
+	}
+
+	if w.SSHMaxWaitTime <= 0 {
+		w.SSHMaxWaitTime = DefaultSSHMaxWaitTime
+	}
+
+	if w.SSHDialTimeout <= 0 {
+		w.SSHDialTimeout = DefaultSSHDialTimeout
+	}
+
+	if w.MinPollInterval <= 0 {
+		w.MinPollInterval = DefaultMinPollInterval
+	}
+
+	if w.MaxPollInterval <= 0 {
+		w.MaxPollInterval = DefaultMaxPollInterval
+	}
+
+	// The AWS SDK waiters fail if
+	// min delay is greater than max delay
+	if w.MaxPollInterval < w.MinPollInterval {
+		w.MaxPollInterval = w.MinPollInterval
+	}
+
+	return w
+}
+
+// pollDelay returns the delay to wait after the passed
+// poll attempt (starting at 1) using an exponential backoff
+// with "equal jitter" (half fixed, half random).
+func (w WaitOpts) pollDelay(attempt int) time.Duration {
+	delay := w.MinPollInterval
+
+	for i := 1; i < attempt && delay < w.MaxPollInterval; i++ {
+		delay *= 2
+	}
+
+	if delay > w.MaxPollInterval {
+		delay = w.MaxPollInterval
+	}
+
+	halfDelay := delay / 2
+	return halfDelay + time.Duration(rand.Int63n(int64(halfDelay)+1))
+}
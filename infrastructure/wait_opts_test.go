@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitOptsWithDefaults(t *testing.T) {
+	testCases := []struct {
+		test             string
+		waitOpts         WaitOpts
+		expectedWaitOpts WaitOpts
+	}{
+		{
+			test:     "unset values",
+			waitOpts: WaitOpts{},
+			expectedWaitOpts: WaitOpts{
+				ResourceMaxWaitTime:    DefaultResourceMaxWaitTime,
+				SnapshotMaxWaitTime:    DefaultSnapshotMaxWaitTime,
+				InitScriptMaxWaitTime:  DefaultInitScriptMaxWaitTime,
+				SSHHostKeysMaxWaitTime: DefaultSSHHostKeysMaxWaitTime,
+				SSHMaxWaitTime:         DefaultSSHMaxWaitTime,
+				SSHDialTimeout:         DefaultSSHDialTimeout,
+				MinPollInterval:        DefaultMinPollInterval,
+				MaxPollInterval:        DefaultMaxPollInterval,
+			},
+		},
+
+		{
+			test: "set values",
+			waitOpts: WaitOpts{
+				ResourceMaxWaitTime: 20 * time.Minute,
+				SSHMaxWaitTime:      10 * time.Minute,
+				MinPollInterval:     time.Second,
+				MaxPollInterval:     time.Minute,
+			},
+			expectedWaitOpts: WaitOpts{
+				ResourceMaxWaitTime:    20 * time.Minute,
+				SnapshotMaxWaitTime:    DefaultSnapshotMaxWaitTime,
+				InitScriptMaxWaitTime:  DefaultInitScriptMaxWaitTime,
+				SSHHostKeysMaxWaitTime: DefaultSSHHostKeysMaxWaitTime,
+				SSHMaxWaitTime:         10 * time.Minute,
+				SSHDialTimeout:         DefaultSSHDialTimeout,
+				MinPollInterval:        time.Second,
+				MaxPollInterval:        time.Minute,
+			},
+		},
+
+		{
+			test: "min poll interval greater than default max",
+			waitOpts: WaitOpts{
+				MinPollInterval: time.Minute,
+			},
+			expectedWaitOpts: WaitOpts{
+				ResourceMaxWaitTime:    DefaultResourceMaxWaitTime,
+				SnapshotMaxWaitTime:    DefaultSnapshotMaxWaitTime,
+				InitScriptMaxWaitTime:  DefaultInitScriptMaxWaitTime,
+				SSHHostKeysMaxWaitTime: DefaultSSHHostKeysMaxWaitTime,
+				SSHMaxWaitTime:         DefaultSSHMaxWaitTime,
+				SSHDialTimeout:         DefaultSSHDialTimeout,
+				MinPollInterval:        time.Minute,
+				MaxPollInterval:        time.Minute,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			waitOpts := tc.waitOpts.WithDefaults()
+
+			if waitOpts != tc.expectedWaitOpts {
+				t.Fatalf(
+					"expected wait opts to equal '%+v', got '%+v'",
+					tc.expectedWaitOpts,
+					waitOpts,
+				)
+			}
+		})
+	}
+}
+
+func TestWaitOptsPollDelay(t *testing.T) {
+	waitOpts := WaitOpts{
+		MinPollInterval: 4 * time.Second,
+		MaxPollInterval: 30 * time.Second,
+	}
+
+	testCases := []struct {
+		attempt          int
+		expectedMaxDelay time.Duration
+	}{
+		{attempt: 1, expectedMaxDelay: 4 * time.Second},
+		{attempt: 2, expectedMaxDelay: 8 * time.Second},
+		{attempt: 3, expectedMaxDelay: 16 * time.Second},
+		{attempt: 4, expectedMaxDelay: 30 * time.Second},
+		{attempt: 10, expectedMaxDelay: 30 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		delay := waitOpts.pollDelay(tc.attempt)
+
+		if delay < tc.expectedMaxDelay/2 || delay > tc.expectedMaxDelay {
+			t.Fatalf(
+				"expected delay for attempt %d to be between '%v' and '%v', got '%v'",
+				tc.attempt,
+				tc.expectedMaxDelay/2,
+				tc.expectedMaxDelay,
+				delay,
+			)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/eleven-sh/aws-cloud-provider/userconfig (interfaces: InstanceMetadataClient)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	imds "github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// UserConfigInstanceMetadataClient is a mock of InstanceMetadataClient interface.
+type UserConfigInstanceMetadataClient struct {
+	ctrl     *gomock.Controller
+	recorder *UserConfigInstanceMetadataClientMockRecorder
+}
+
+// UserConfigInstanceMetadataClientMockRecorder is the mock recorder for UserConfigInstanceMetadataClient.
+type UserConfigInstanceMetadataClientMockRecorder struct {
+	mock *UserConfigInstanceMetadataClient
+}
+
+// NewUserConfigInstanceMetadataClient creates a new mock instance.
+func NewUserConfigInstanceMetadataClient(ctrl *gomock.Controller) *UserConfigInstanceMetadataClient {
+	mock := &UserConfigInstanceMetadataClient{ctrl: ctrl}
+	mock.recorder = &UserConfigInstanceMetadataClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *UserConfigInstanceMetadataClient) EXPECT() *UserConfigInstanceMetadataClientMockRecorder {
+	return m.recorder
+}
+
+// GetMetadata mocks base method.
+func (m *UserConfigInstanceMetadataClient) GetMetadata(arg0 context.Context, arg1 *imds.GetMetadataInput, arg2 ...func(*imds.Options)) (*imds.GetMetadataOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetMetadata", varargs...)
+	ret0, _ := ret[0].(*imds.GetMetadataOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetadata indicates an expected call of GetMetadata.
+func (mr *UserConfigInstanceMetadataClientMockRecorder) GetMetadata(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetadata", reflect.TypeOf((*UserConfigInstanceMetadataClient)(nil).GetMetadata), varargs...)
+}
+
+// GetRegion mocks base method.
+func (m *UserConfigInstanceMetadataClient) GetRegion(arg0 context.Context, arg1 *imds.GetRegionInput, arg2 ...func(*imds.Options)) (*imds.GetRegionOutput, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetRegion", varargs...)
+	ret0, _ := ret[0].(*imds.GetRegionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRegion indicates an expected call of GetRegion.
+func (mr *UserConfigInstanceMetadataClientMockRecorder) GetRegion(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegion", reflect.TypeOf((*UserConfigInstanceMetadataClient)(nil).GetRegion), varargs...)
+}
@@ -1,10 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"strings"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/stepper"
 )
@@ -12,6 +12,7 @@ import (
 type ErrInvalidInstanceType struct {
 	InstanceType string
 	Region       string
+	Partition    string
 }
 
 func (ErrInvalidInstanceType) Error() string {
@@ -27,36 +28,70 @@ func (ErrInvalidInstanceTypeArch) Error() string {
 	return "ErrInvalidInstanceTypeArch"
 }
 
+// ErrInstanceTypeUsageClassNotSupported represents the error
+// returned when the instance type cannot be launched as an
+// on-demand (or spot when the Spot option is set) instance.
+type ErrInstanceTypeUsageClassNotSupported struct {
+	InstanceType string
+	UsageClass   string
+}
+
+func (ErrInstanceTypeUsageClassNotSupported) Error() string {
+	return "ErrInstanceTypeUsageClassNotSupported"
+}
+
 func (a *AWS) CheckInstanceTypeValidity(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	instanceType string,
 ) error {
 
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	_, err := a.lookupInstanceTypeInfos(ctx, instanceType)
+	return err
+}
 
-	_, err := infrastructure.LookupInstanceTypeInfos(
+// lookupInstanceTypeInfos looks up the instance type and converts
+// the infrastructure errors to the ones returned by the service.
+func (a *AWS) lookupInstanceTypeInfos(
+	ctx context.Context,
+	instanceType string,
+) (*infrastructure.InstanceTypeInfos, error) {
+
+	ec2Client := a.ec2Client
+
+	instanceTypeInfos, err := infrastructure.LookupInstanceTypeInfos(
+		ctx,
 		ec2Client,
 		instanceType,
+		a.opts.Spot.instanceUsageClass(),
 	)
 
 	if err != nil {
 
 		if errors.Is(err, infrastructure.ErrInvalidInstanceType) {
-			return ErrInvalidInstanceType{
+			return nil, ErrInvalidInstanceType{
 				InstanceType: instanceType,
 				Region:       a.sdkConfig.Region,
+				Partition:    a.partition,
 			}
 		}
 
 		if errors.Is(err, infrastructure.ErrInvalidInstanceTypeArch) {
-			return ErrInvalidInstanceTypeArch{
+			return nil, ErrInvalidInstanceTypeArch{
 				InstanceType:   instanceType,
 				SupportedArchs: strings.Join(infrastructure.SupportedInstanceTypeArchs, ", "),
 			}
 		}
 
-		return err
+		if errors.Is(err, infrastructure.ErrInstanceTypeUsageClassNotSupported) {
+			return nil, ErrInstanceTypeUsageClassNotSupported{
+				InstanceType: instanceType,
+				UsageClass:   a.opts.Spot.instanceUsageClass(),
+			}
+		}
+
+		return nil, wrapCanceledError(ctx, err)
 	}
 
-	return nil
+	return instanceTypeInfos, nil
 }
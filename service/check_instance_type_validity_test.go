@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+)
+
+func TestCheckInstanceTypeValidityWithInvalidInstanceType(t *testing.T) {
+	testCases := []struct {
+		test              string
+		region            string
+		expectedPartition string
+	}{
+		{
+			test:              "in standard region",
+			region:            "eu-west-3",
+			expectedPartition: "aws",
+		},
+
+		{
+			test:              "in China region",
+			region:            "cn-north-1",
+			expectedPartition: "aws-cn",
+		},
+
+		{
+			test:              "in GovCloud region",
+			region:            "us-gov-west-1",
+			expectedPartition: "aws-us-gov",
+		},
+	}
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			SDKConfig := fakeAWS.Config()
+			SDKConfig.Region = tc.region
+
+			AWSService := service.NewAWS(SDKConfig, service.AWSOpts{})
+
+			err := AWSService.CheckInstanceTypeValidity(
+				context.Background(),
+				noopStepper{},
+				"invalid.type",
+			)
+
+			expectedError := service.ErrInvalidInstanceType{
+				InstanceType: "invalid.type",
+				Region:       tc.region,
+				Partition:    tc.expectedPartition,
+			}
+
+			var invalidInstanceTypeErr service.ErrInvalidInstanceType
+			if !errors.As(err, &invalidInstanceTypeErr) ||
+				invalidInstanceTypeErr != expectedError {
+
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
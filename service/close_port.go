@@ -1,34 +1,168 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/entities"
 	"github.com/eleven-sh/eleven/stepper"
 )
 
+// ClosePortOpts represents the options
+// used to configure ClosePortWithOpts.
+type ClosePortOpts struct {
+	// CIDRs specifies the IPv4 and IPv6 CIDRs whose access
+	// to the port is revoked (like the ones passed to OpenPortWithOpts).
+	// Default to the ingress CIDRs of the env if not set.
+	// Cannot be combined with the source CIDR of the port spec.
+	CIDRs []string
+}
+
+// ClosePort revokes the traffic allowed by OpenPort
+// for the passed port spec (see ParsePortSpec).
 func (a *AWS) ClosePort(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+	portToClose string,
+) error {
+
+	return a.ClosePortWithOpts(
+		ctx,
+		stepper,
+		config,
+		cluster,
+		env,
+		portToClose,
+		ClosePortOpts{},
+	)
+}
+
+// ClosePortWithOpts works like ClosePort.
+func (a *AWS) ClosePortWithOpts(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 	cluster *entities.Cluster,
 	env *entities.Env,
 	portToClose string,
+	opts ClosePortOpts,
 ) error {
 
+	portSpec, err := ParsePortSpec(portToClose)
+
+	if err != nil {
+		return err
+	}
+
 	var envInfra *EnvInfrastructure
-	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+	err = json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	portSpec.CIDRs, err = resolvePortCIDRs(envInfra, portToClose, portSpec, opts.CIDRs)
 
 	if err != nil {
 		return err
 	}
 
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	ec2Client := a.ec2Client
 
-	return infrastructure.CloseInstancePort(
+	ingressRules, err := infrastructure.LookupSecurityGroupIngressRules(
+		ctx,
 		ec2Client,
 		envInfra.SecurityGroup.ID,
-		portToClose,
 	)
+
+	if err != nil {
+		return wrapCanceledError(ctx, err)
+	}
+
+	// Closing the rules added during env creation would make
+	// the env unreachable (same check as ListOpenPorts)
+	for _, ingressRule := range ingressRules {
+		if ingressRule.Protocol == portSpec.Protocol &&
+			ingressRule.FromPort == portSpec.FromPort &&
+			ingressRule.ToPort == portSpec.ToPort &&
+			infrastructure.ReferencesAnyCIDR(ingressRule.CIDRs, portSpec.CIDRs) &&
+			envInfra.isElevenManagedPortRule(ingressRule) {
+
+			return ErrManagedPort{
+				PortSpec: portToClose,
+			}
+		}
+	}
+
+	err = infrastructure.CloseInstancePort(
+		ctx,
+		ec2Client,
+		envInfra.SecurityGroup.ID,
+		portSpec,
+	)
+
+	if errors.Is(err, infrastructure.ErrIngressRuleNotFound) {
+		return ErrPortRuleNotFound{
+			PortSpec: portToClose,
+		}
+	}
+
+	if err != nil {
+		return wrapCanceledError(ctx, err)
+	}
+
+	closedCIDRs := map[string]bool{}
+
+	for _, CIDR := range portSpec.CIDRs {
+		closedCIDRs[CIDR] = true
+	}
+
+	remainingPortExpirations := []PortExpiration{}
+	portExpirationsUpdated := false
+
+	for _, portExpiration := range envInfra.PortExpirations {
+		expiringPortSpec := portExpiration.PortSpec
+
+		if expiringPortSpec.Protocol != portSpec.Protocol ||
+			expiringPortSpec.FromPort != portSpec.FromPort ||
+			expiringPortSpec.ToPort != portSpec.ToPort {
+
+			remainingPortExpirations = append(remainingPortExpirations, portExpiration)
+			continue
+		}
+
+		// Only the rules of the closed CIDRs were revoked,
+		// the other ones must still be reaped once expired
+		remainingCIDRs := []string{}
+
+		for _, CIDR := range expiringPortSpec.CIDRs {
+			if !closedCIDRs[CIDR] {
+				remainingCIDRs = append(remainingCIDRs, CIDR)
+			}
+		}
+
+		if len(remainingCIDRs) == len(expiringPortSpec.CIDRs) {
+			remainingPortExpirations = append(remainingPortExpirations, portExpiration)
+			continue
+		}
+
+		portExpirationsUpdated = true
+
+		if len(remainingCIDRs) > 0 {
+			portExpiration.PortSpec.CIDRs = remainingCIDRs
+			remainingPortExpirations = append(remainingPortExpirations, portExpiration)
+		}
+	}
+
+	if portExpirationsUpdated {
+		envInfra.PortExpirations = remainingPortExpirations
+		env.SetInfrastructureJSON(envInfra)
+	}
+
+	return nil
 }
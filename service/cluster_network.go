@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+)
+
+const (
+	// DefaultClusterVPCCIDRBlock represents the CIDR
+	// block of the VPC when none is passed.
+	DefaultClusterVPCCIDRBlock = "10.0.0.0/16"
+
+	// From AWS docs: the allowed block size
+	// is between a /16 and a /28 netmask.
+	minVPCCIDRBlockMaskSize = 16
+	maxVPCCIDRBlockMaskSize = 28
+
+	minSubnetCIDRBlockMaskSize = 24
+	maxSubnetCIDRBlockMaskSize = 28
+)
+
+// ClusterNetworkOpts represents the options used
+// to configure the network of the clusters.
+type ClusterNetworkOpts struct {
+	// VPCCIDRBlock specifies the IPv4 CIDR block of the VPC.
+	// Default to DefaultClusterVPCCIDRBlock if not set.
+	VPCCIDRBlock string
+
+	// SubnetCount specifies the number of subnets (one per availability zone).
+	// Default to the number of AvailabilityZones or to 1 if not set.
+	SubnetCount int
+
+	// AvailabilityZones specifies the availability zones of the subnets.
+	// Default to the first SubnetCount available zones of the region if not set
+	// (or to the one chosen by AWS when only one subnet is created).
+	AvailabilityZones []string
+
+	// SubnetIDs specifies the IDs of existing subnets to deploy the clusters into.
+	// The subnets must belong to the VPC and have an IPv4 default route to an
+	// internet gateway (or to any egress target when private networking is enabled).
+	// When set, no network resources are created (and the other options are ignored).
+	SubnetIDs []string
+
+	// VPCID specifies the ID of the VPC containing the SubnetIDs.
+	// Default to the VPC of the first subnet if not set.
+	VPCID string
+}
+
+// usesExistingNetwork returns true if the clusters
+// are deployed into an existing VPC.
+func (c ClusterNetworkOpts) usesExistingNetwork() bool {
+	return len(c.VPCID) > 0 || len(c.SubnetIDs) > 0
+}
+
+// ErrInvalidVPCCIDRBlock represents the error returned
+// when the VPC CIDR block is not a valid IPv4 CIDR
+// between a /16 and a /28 netmask.
+type ErrInvalidVPCCIDRBlock struct {
+	CIDRBlock string
+}
+
+func (ErrInvalidVPCCIDRBlock) Error() string {
+	return "ErrInvalidVPCCIDRBlock"
+}
+
+// ErrInvalidSubnetCount represents the error returned
+// when the subnet count doesn't match the availability
+// zones or cannot be carved out of the VPC CIDR block.
+type ErrInvalidSubnetCount struct {
+	SubnetCount  int
+	VPCCIDRBlock string
+}
+
+func (ErrInvalidSubnetCount) Error() string {
+	return "ErrInvalidSubnetCount"
+}
+
+// ErrInvalidAvailabilityZone represents the error returned
+// when an availability zone is unknown, unavailable or
+// passed multiple times.
+type ErrInvalidAvailabilityZone struct {
+	AvailabilityZone string
+	Region           string
+}
+
+func (ErrInvalidAvailabilityZone) Error() string {
+	return "ErrInvalidAvailabilityZone"
+}
+
+// ErrExistingVPCWithoutSubnets represents the error returned
+// when an existing VPC is passed without the IDs of the subnets
+// to deploy the clusters into.
+type ErrExistingVPCWithoutSubnets struct {
+	VPCID string
+}
+
+func (ErrExistingVPCWithoutSubnets) Error() string {
+	return "ErrExistingVPCWithoutSubnets"
+}
+
+// ErrVPCNotFound represents the error returned
+// when the existing VPC doesn't exist.
+type ErrVPCNotFound struct {
+	VPCID string
+}
+
+func (ErrVPCNotFound) Error() string {
+	return "ErrVPCNotFound"
+}
+
+// ErrSubnetNotFound represents the error returned
+// when an existing subnet doesn't exist.
+type ErrSubnetNotFound struct {
+	SubnetID string
+}
+
+func (ErrSubnetNotFound) Error() string {
+	return "ErrSubnetNotFound"
+}
+
+// ErrSubnetNotInVPC represents the error returned
+// when an existing subnet belongs to another VPC.
+type ErrSubnetNotInVPC struct {
+	SubnetID string
+	VPCID    string
+}
+
+func (ErrSubnetNotInVPC) Error() string {
+	return "ErrSubnetNotInVPC"
+}
+
+// ErrSubnetWithoutEgressRoute represents the error returned
+// when an existing subnet has no IPv4 default route (to an
+// internet gateway or to another egress path like a NAT gateway).
+type ErrSubnetWithoutEgressRoute struct {
+	SubnetID string
+}
+
+func (ErrSubnetWithoutEgressRoute) Error() string {
+	return "ErrSubnetWithoutEgressRoute"
+}
+
+// ErrSubnetWithoutInternetGatewayRoute represents the error returned
+// when an existing subnet has an IPv4 default route to another target
+// than an internet gateway (like a NAT gateway) while private networking
+// is disabled. The envs would not be reachable via their public IP.
+type ErrSubnetWithoutInternetGatewayRoute struct {
+	SubnetID       string
+	EgressTargetID string
+}
+
+func (ErrSubnetWithoutInternetGatewayRoute) Error() string {
+	return "ErrSubnetWithoutInternetGatewayRoute"
+}
+
+// clusterNetwork represents the resolved network of a cluster.
+type clusterNetwork struct {
+	VPCCIDRBlock     string
+	SubnetCIDRBlocks []string
+	// Only set when private networking
+	// is enabled (one per public subnet)
+	PrivateSubnetCIDRBlocks []string
+	// Empty when AWS chooses
+	// the availability zone
+	AvailabilityZones []string
+}
+
+// resolveClusterNetwork validates the ClusterNetworkOpts
+// passed in AWSOpts and carves the subnet CIDR blocks
+// (public and private ones) out of the VPC CIDR block.
+func (a *AWS) resolveClusterNetwork(
+	ctx context.Context,
+	withPrivateSubnets bool,
+) (*clusterNetwork, error) {
+
+	networkOpts := a.opts.ClusterNetwork
+
+	VPCCIDRBlock := networkOpts.VPCCIDRBlock
+
+	if len(VPCCIDRBlock) == 0 {
+		VPCCIDRBlock = DefaultClusterVPCCIDRBlock
+	}
+
+	VPCIP, VPCNetwork, err := net.ParseCIDR(VPCCIDRBlock)
+
+	if err != nil || VPCIP.To4() == nil {
+		return nil, ErrInvalidVPCCIDRBlock{
+			CIDRBlock: VPCCIDRBlock,
+		}
+	}
+
+	VPCMaskSize, _ := VPCNetwork.Mask.Size()
+
+	if VPCMaskSize < minVPCCIDRBlockMaskSize || VPCMaskSize > maxVPCCIDRBlockMaskSize {
+		return nil, ErrInvalidVPCCIDRBlock{
+			CIDRBlock: VPCCIDRBlock,
+		}
+	}
+
+	// Normalized to match the
+	// CIDR block returned by AWS
+	VPCCIDRBlock = VPCNetwork.String()
+
+	subnetCount := networkOpts.SubnetCount
+	availabilityZones := networkOpts.AvailabilityZones
+
+	if subnetCount == 0 {
+		subnetCount = len(availabilityZones)
+	}
+
+	if subnetCount == 0 {
+		subnetCount = 1
+	}
+
+	if subnetCount < 0 ||
+		(len(availabilityZones) > 0 && len(availabilityZones) != subnetCount) {
+
+		return nil, ErrInvalidSubnetCount{
+			SubnetCount:  subnetCount,
+			VPCCIDRBlock: VPCCIDRBlock,
+		}
+	}
+
+	// Private subnets are carved after the public ones
+	carvedSubnetCount := subnetCount
+
+	if withPrivateSubnets {
+		carvedSubnetCount = subnetCount * 2
+	}
+
+	carvedSubnetCIDRBlocks, err := carveSubnetCIDRBlocks(VPCNetwork, carvedSubnetCount)
+
+	if err != nil {
+		return nil, ErrInvalidSubnetCount{
+			SubnetCount:  subnetCount,
+			VPCCIDRBlock: VPCCIDRBlock,
+		}
+	}
+
+	subnetCIDRBlocks := carvedSubnetCIDRBlocks[:subnetCount]
+	privateSubnetCIDRBlocks := carvedSubnetCIDRBlocks[subnetCount:]
+
+	// AWS chooses the availability zone
+	if subnetCount == 1 && len(availabilityZones) == 0 {
+		return &clusterNetwork{
+			VPCCIDRBlock:            VPCCIDRBlock,
+			SubnetCIDRBlocks:        subnetCIDRBlocks,
+			PrivateSubnetCIDRBlocks: privateSubnetCIDRBlocks,
+		}, nil
+	}
+
+	regionAvailabilityZones, err := infrastructure.LookupAvailabilityZones(
+		ctx,
+		a.ec2Client,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(availabilityZones) == 0 {
+		if subnetCount > len(regionAvailabilityZones) {
+			return nil, ErrInvalidSubnetCount{
+				SubnetCount:  subnetCount,
+				VPCCIDRBlock: VPCCIDRBlock,
+			}
+		}
+
+		availabilityZones = regionAvailabilityZones[:subnetCount]
+	}
+
+	isRegionAvailabilityZone := map[string]bool{}
+
+	for _, availabilityZone := range regionAvailabilityZones {
+		isRegionAvailabilityZone[availabilityZone] = true
+	}
+
+	isResolvedAvailabilityZone := map[string]bool{}
+
+	for _, availabilityZone := range availabilityZones {
+		if !isRegionAvailabilityZone[availabilityZone] ||
+			isResolvedAvailabilityZone[availabilityZone] {
+
+			return nil, ErrInvalidAvailabilityZone{
+				AvailabilityZone: availabilityZone,
+				Region:           a.sdkConfig.Region,
+			}
+		}
+
+		isResolvedAvailabilityZone[availabilityZone] = true
+	}
+
+	return &clusterNetwork{
+		VPCCIDRBlock:            VPCCIDRBlock,
+		SubnetCIDRBlocks:        subnetCIDRBlocks,
+		PrivateSubnetCIDRBlocks: privateSubnetCIDRBlocks,
+		AvailabilityZones:       availabilityZones,
+	}, nil
+}
+
+// lookupExistingClusterNetwork validates the existing VPC and subnets
+// passed in AWSOpts. They are returned as externally owned.
+func (a *AWS) lookupExistingClusterNetwork(
+	ctx context.Context,
+) (*infrastructure.VPC, []*infrastructure.Subnet, error) {
+
+	networkOpts := a.opts.ClusterNetwork
+
+	if len(networkOpts.SubnetIDs) == 0 {
+		return nil, nil, ErrExistingVPCWithoutSubnets{
+			VPCID: networkOpts.VPCID,
+		}
+	}
+
+	subnets := []*infrastructure.Subnet{}
+	VPCID := networkOpts.VPCID
+
+	for _, subnetID := range networkOpts.SubnetIDs {
+		subnet, err := infrastructure.LookupSubnet(ctx, a.ec2Client, subnetID)
+
+		if err != nil {
+			if errors.Is(err, infrastructure.ErrSubnetNotFound) {
+				return nil, nil, ErrSubnetNotFound{
+					SubnetID: subnetID,
+				}
+			}
+
+			return nil, nil, err
+		}
+
+		if len(VPCID) == 0 {
+			VPCID = subnet.VPCID
+		}
+
+		if subnet.VPCID != VPCID {
+			return nil, nil, ErrSubnetNotInVPC{
+				SubnetID: subnetID,
+				VPCID:    VPCID,
+			}
+		}
+
+		egressTargetID, err := infrastructure.LookupSubnetEgressTarget(
+			ctx,
+			a.ec2Client,
+			VPCID,
+			subnetID,
+		)
+
+		if err != nil {
+			if errors.Is(err, infrastructure.ErrSubnetEgressRouteNotFound) {
+				return nil, nil, ErrSubnetWithoutEgressRoute{
+					SubnetID: subnetID,
+				}
+			}
+
+			return nil, nil, err
+		}
+
+		// Public envs need an internet gateway
+		// to be reachable via their public IP
+		if !a.opts.PrivateNetworking.Enabled &&
+			!strings.HasPrefix(egressTargetID, "igw-") {
+
+			return nil, nil, ErrSubnetWithoutInternetGatewayRoute{
+				SubnetID:       subnetID,
+				EgressTargetID: egressTargetID,
+			}
+		}
+
+		// Existing route tables are left untouched
+		subnet.IsAssociatedToRouteTable = true
+		subnet.IsExternallyOwned = true
+
+		subnets = append(subnets, subnet)
+	}
+
+	VPC, err := infrastructure.LookupVPC(ctx, a.ec2Client, VPCID)
+
+	if err != nil {
+		if errors.Is(err, infrastructure.ErrVPCNotFound) {
+			return nil, nil, ErrVPCNotFound{
+				VPCID: VPCID,
+			}
+		}
+
+		return nil, nil, err
+	}
+
+	VPC.IsExternallyOwned = true
+
+	return VPC, subnets, nil
+}
+
+// carveSubnetCIDRBlocks splits the VPC network into equally sized
+// subnets and returns the first count ones. Subnets are /24 when
+// the VPC is large enough (like "10.0.0.0/24" in "10.0.0.0/16").
+func carveSubnetCIDRBlocks(VPCNetwork *net.IPNet, count int) ([]string, error) {
+	VPCMaskSize, _ := VPCNetwork.Mask.Size()
+
+	subnetMaskSize := VPCMaskSize
+
+	for subnetMaskSize <= maxSubnetCIDRBlockMaskSize &&
+		(1<<(subnetMaskSize-VPCMaskSize)) < count {
+
+		subnetMaskSize++
+	}
+
+	if subnetMaskSize < minSubnetCIDRBlockMaskSize {
+		subnetMaskSize = minSubnetCIDRBlockMaskSize
+	}
+
+	if subnetMaskSize > maxSubnetCIDRBlockMaskSize {
+		return nil, errors.New("ErrSubnetCIDRBlockTooSmall")
+	}
+
+	VPCIP := binary.BigEndian.Uint32(VPCNetwork.IP.To4())
+	subnetSize := uint32(1) << (32 - subnetMaskSize)
+
+	subnetCIDRBlocks := []string{}
+
+	for i := 0; i < count; i++ {
+		subnetIP := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(subnetIP, VPCIP+uint32(i)*subnetSize)
+
+		subnetCIDRBlocks = append(subnetCIDRBlocks, (&net.IPNet{
+			IP:   subnetIP,
+			Mask: net.CIDRMask(subnetMaskSize, net.IPv4len*8),
+		}).String())
+	}
+
+	return subnetCIDRBlocks, nil
+}
+
+// subnetIPv6CIDRBlock returns the n-th /64 block
+// of the /56 block provided by Amazon to the VPC.
+func subnetIPv6CIDRBlock(VPCIPv6CIDRBlock string, n int) (string, error) {
+	_, VPCIPv6Network, err := net.ParseCIDR(VPCIPv6CIDRBlock)
+
+	if err != nil {
+		return "", err
+	}
+
+	maskSize, bits := VPCIPv6Network.Mask.Size()
+
+	if bits != net.IPv6len*8 || maskSize != 56 || n < 0 || n > 255 {
+		return "", errors.New("ErrInvalidVPCIPv6CIDRBlock")
+	}
+
+	subnetIP := make(net.IP, net.IPv6len)
+	copy(subnetIP, VPCIPv6Network.IP)
+	subnetIP[7] = byte(n)
+
+	return (&net.IPNet{
+		IP:   subnetIP,
+		Mask: net.CIDRMask(64, net.IPv6len*8),
+	}).String(), nil
+}
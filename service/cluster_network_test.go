@@ -0,0 +1,245 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestMultiAZClusterNetwork(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+		ClusterNetwork: service.ClusterNetworkOpts{
+			VPCCIDRBlock: "172.20.0.0/20",
+			SubnetCount:  3,
+		},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	var clusterInfra *service.ClusterInfrastructure
+	err := json.Unmarshal([]byte(cluster.InfrastructureJSON), &clusterInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if clusterInfra.VPC.CIDRBlock != "172.20.0.0/20" {
+		t.Fatalf(
+			"expected VPC CIDR block to equal '172.20.0.0/20', got '%+v'",
+			clusterInfra.VPC.CIDRBlock,
+		)
+	}
+
+	expectedSubnets := [][2]string{
+		{"172.20.0.0/24", "us-east-1a"},
+		{"172.20.1.0/24", "us-east-1b"},
+		{"172.20.2.0/24", "us-east-1c"},
+	}
+
+	subnets := [][2]string{}
+
+	for _, subnet := range clusterInfra.Subnets {
+		subnets = append(subnets, [2]string{subnet.CIDRBlock, subnet.AvailabilityZone})
+
+		if !subnet.IsAssociatedToRouteTable {
+			t.Fatalf("expected subnet '%+v' to be associated to the route table", subnet)
+		}
+	}
+
+	if !reflect.DeepEqual(subnets, expectedSubnets) {
+		t.Fatalf(
+			"expected subnets to equal '%+v', got '%+v'",
+			expectedSubnets,
+			subnets,
+		)
+	}
+
+	// Not offered in us-east-1a
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "m6g.large",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if envInfra.Subnet.ID != clusterInfra.Subnets[1].ID {
+		t.Fatalf(
+			"expected env subnet to equal '%+v', got '%+v'",
+			clusterInfra.Subnets[1],
+			envInfra.Subnet,
+		)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestCreateEnvWithInstanceTypeNotOffered(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+		ClusterNetwork: service.ClusterNetworkOpts{
+			AvailabilityZones: []string{"us-east-1a"},
+		},
+	})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "m6g.large",
+	}
+
+	err := AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	var notOfferedErr service.ErrInstanceTypeNotOffered
+	if !errors.As(err, &notOfferedErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInstanceTypeNotOffered{},
+			err,
+		)
+	}
+
+	expectedAvailabilityZones := []string{"us-east-1a"}
+
+	if !reflect.DeepEqual(notOfferedErr.AvailabilityZones, expectedAvailabilityZones) {
+		t.Fatalf(
+			"expected availability zones to equal '%+v', got '%+v'",
+			expectedAvailabilityZones,
+			notOfferedErr.AvailabilityZones,
+		)
+	}
+}
+
+func TestCreateClusterWithInvalidNetwork(t *testing.T) {
+	testCases := []struct {
+		test          string
+		networkOpts   service.ClusterNetworkOpts
+		expectedError error
+	}{
+		{
+			test: "with too large VPC CIDR block",
+			networkOpts: service.ClusterNetworkOpts{
+				VPCCIDRBlock: "10.0.0.0/8",
+			},
+			expectedError: service.ErrInvalidVPCCIDRBlock{},
+		},
+
+		{
+			test: "with IPv6 VPC CIDR block",
+			networkOpts: service.ClusterNetworkOpts{
+				VPCCIDRBlock: "2001:db8::/56",
+			},
+			expectedError: service.ErrInvalidVPCCIDRBlock{},
+		},
+
+		{
+			test: "with more subnets than availability zones",
+			networkOpts: service.ClusterNetworkOpts{
+				SubnetCount: 4,
+			},
+			expectedError: service.ErrInvalidSubnetCount{},
+		},
+
+		{
+			test: "with subnet count not matching availability zones",
+			networkOpts: service.ClusterNetworkOpts{
+				SubnetCount:       2,
+				AvailabilityZones: []string{"us-east-1a"},
+			},
+			expectedError: service.ErrInvalidSubnetCount{},
+		},
+
+		{
+			test: "with too small VPC for subnet count",
+			networkOpts: service.ClusterNetworkOpts{
+				VPCCIDRBlock: "10.0.0.0/28",
+				SubnetCount:  2,
+			},
+			expectedError: service.ErrInvalidSubnetCount{},
+		},
+
+		{
+			test: "with unknown availability zone",
+			networkOpts: service.ClusterNetworkOpts{
+				AvailabilityZones: []string{"us-east-1z"},
+			},
+			expectedError: service.ErrInvalidAvailabilityZone{},
+		},
+
+		{
+			test: "with duplicated availability zone",
+			networkOpts: service.ClusterNetworkOpts{
+				AvailabilityZones: []string{"us-east-1a", "us-east-1a"},
+			},
+			expectedError: service.ErrInvalidAvailabilityZone{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			fakeAWS := fakeaws.NewServer()
+			defer fakeAWS.Close()
+
+			AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+				InstanceDialer: fakeAWS.Dialer(),
+				ClusterNetwork: tc.networkOpts,
+			})
+
+			err := AWSService.CreateCluster(
+				context.Background(),
+				noopStepper{},
+				&entities.Config{},
+				&entities.Cluster{
+					Name: entities.DefaultClusterName,
+				},
+			)
+
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					tc.expectedError,
+					err,
+				)
+			}
+
+			resourceCounts := fakeAWS.ResourceCounts()
+
+			if resourceCounts != (fakeaws.ResourceCounts{}) {
+				t.Fatalf("expected no created resources, got '%+v'", resourceCounts)
+			}
+		})
+	}
+}
@@ -1,9 +1,10 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/entities"
 	"github.com/eleven-sh/eleven/queues"
@@ -13,12 +14,60 @@ import (
 type ClusterInfrastructure struct {
 	VPC             *infrastructure.VPC             `json:"vpc"`
 	InternetGateway *infrastructure.InternetGateway `json:"internet_gateway"`
-	Subnet          *infrastructure.Subnet          `json:"subnet"`
+	Subnets         []*infrastructure.Subnet        `json:"subnets"`
 	RouteTable      *infrastructure.RouteTable      `json:"route_table"`
 	Route           *infrastructure.Route           `json:"route"`
+	IPv6Route       *infrastructure.Route           `json:"ipv6_route"`
+
+	// Only set for the clusters created
+	// with private networking enabled
+	PrivateEgress       string                     `json:"private_egress,omitempty"`
+	PrivateSubnets      []*infrastructure.Subnet   `json:"private_subnets,omitempty"`
+	PrivateRouteTable   *infrastructure.RouteTable `json:"private_route_table,omitempty"`
+	NATGatewayElasticIP *infrastructure.ElasticIP  `json:"nat_gateway_elastic_ip,omitempty"`
+	NATGateway          *infrastructure.NATGateway `json:"nat_gateway,omitempty"`
+	NATRoute            *infrastructure.Route      `json:"nat_route,omitempty"`
+
+	// Subnet is only set for the clusters
+	// created before multi-AZ subnets
+	Subnet *infrastructure.Subnet `json:"subnet,omitempty"`
+}
+
+// subnets returns the subnets of the cluster
+// (one per availability zone).
+func (c *ClusterInfrastructure) subnets() []*infrastructure.Subnet {
+	if c.Subnet != nil {
+		return append([]*infrastructure.Subnet{c.Subnet}, c.Subnets...)
+	}
+
+	return c.Subnets
+}
+
+// isDualStack returns true if the VPC
+// was assigned an IPv6 CIDR block.
+func (c *ClusterInfrastructure) isDualStack() bool {
+	return c.VPC != nil && len(c.VPC.IPv6CIDRBlock) > 0
+}
+
+// isPrivate returns true if the cluster
+// was created with private subnets.
+func (c *ClusterInfrastructure) isPrivate() bool {
+	return len(c.PrivateEgress) > 0
+}
+
+// needsNetwork returns true if some subnets
+// are not created or not associated yet.
+func (c *ClusterInfrastructure) needsNetwork() bool {
+	if c.RouteTable == nil || !c.RouteTable.IsAssociatedToSubnet {
+		return true
+	}
+
+	return c.isPrivate() &&
+		(c.PrivateRouteTable == nil || !c.PrivateRouteTable.IsAssociatedToSubnet)
 }
 
 func (a *AWS) CreateCluster(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 	cluster *entities.Cluster,
@@ -33,8 +82,53 @@ func (a *AWS) CreateCluster(
 		}
 	}
 
+	// Clusters deployed into an existing network only
+	// record the VPC and the subnets once validated
+	if clusterInfra.VPC == nil && a.opts.ClusterNetwork.usesExistingNetwork() {
+		stepper.StartTemporaryStep("Validating the existing VPC and subnets")
+
+		VPC, subnets, err := a.lookupExistingClusterNetwork(ctx)
+
+		if err != nil {
+			return wrapCanceledError(ctx, err)
+		}
+
+		clusterInfra.VPC = VPC
+		clusterInfra.Subnets = subnets
+
+		cluster.SetInfrastructureJSON(clusterInfra)
+	}
+
+	if clusterInfra.VPC != nil && clusterInfra.VPC.IsExternallyOwned {
+		return nil
+	}
+
+	// Resolved before any resource is created
+	// to return invalid options early
+	if clusterInfra.VPC == nil && a.opts.PrivateNetworking.Enabled {
+		privateEgress, err := a.resolvePrivateEgress()
+
+		if err != nil {
+			return err
+		}
+
+		clusterInfra.PrivateEgress = privateEgress
+	}
+
+	var network *clusterNetwork
+
+	if clusterInfra.needsNetwork() {
+		resolvedNetwork, err := a.resolveClusterNetwork(ctx, clusterInfra.isPrivate())
+
+		if err != nil {
+			return wrapCanceledError(ctx, err)
+		}
+
+		network = resolvedNetwork
+	}
+
 	prefixResource := prefixClusterResource(cluster.GetNameSlug())
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	ec2Client := a.ec2Client
 
 	clusterInfraQueue := queues.InfrastructureQueue[*ClusterInfrastructure]{}
 
@@ -44,9 +138,12 @@ func (a *AWS) CreateCluster(
 		}
 
 		vpc, err := infrastructure.CreateVPC(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			prefixResource("vpc"),
-			"10.0.0.0/16",
+			network.VPCCIDRBlock,
+			a.opts.EnableIPv6,
 		)
 
 		if err != nil {
@@ -63,7 +160,9 @@ func (a *AWS) CreateCluster(
 		}
 
 		internetGateway, err := infrastructure.CreateInternetGateway(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			prefixResource("internet-gateway"),
 		)
 
@@ -93,6 +192,7 @@ func (a *AWS) CreateCluster(
 		}
 
 		err := infrastructure.AttachInternetGatewayToVPC(
+			ctx,
 			ec2Client,
 			infra.InternetGateway.ID,
 			infra.VPC.ID,
@@ -106,23 +206,53 @@ func (a *AWS) CreateCluster(
 		return nil
 	}
 
-	createSubnet := func(infra *ClusterInfrastructure) error {
-		if infra.Subnet != nil {
+	createSubnets := func(infra *ClusterInfrastructure) error {
+		if network == nil || len(infra.subnets()) >= len(network.SubnetCIDRBlocks) {
 			return nil
 		}
 
-		subnet, err := infrastructure.CreateSubnet(
-			ec2Client,
-			prefixResource("public-subnet"),
-			"10.0.0.0/24",
-			infra.VPC.ID,
-		)
-
-		if err != nil {
-			return err
+		// Subnets are created one by one and recorded
+		// as soon as they are created (partial infrastructure)
+		for subnetIndex := len(infra.subnets()); subnetIndex < len(network.SubnetCIDRBlocks); subnetIndex++ {
+			subnetName := "public-subnet"
+			availabilityZone := ""
+
+			if len(network.AvailabilityZones) > 0 {
+				availabilityZone = network.AvailabilityZones[subnetIndex]
+				subnetName = fmt.Sprintf("public-subnet-%s", availabilityZone)
+			}
+
+			IPv6CIDRBlock := ""
+
+			if infra.isDualStack() {
+				subnetIPv6Block, err := subnetIPv6CIDRBlock(infra.VPC.IPv6CIDRBlock, subnetIndex)
+
+				if err != nil {
+					return err
+				}
+
+				IPv6CIDRBlock = subnetIPv6Block
+			}
+
+			subnet, err := infrastructure.CreateSubnet(
+				ctx,
+				ec2Client,
+				a.opts.WaitOpts,
+				prefixResource(subnetName),
+				network.SubnetCIDRBlocks[subnetIndex],
+				IPv6CIDRBlock,
+				availabilityZone,
+				infra.VPC.ID,
+				true,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			infra.Subnets = append(infra.Subnets, subnet)
 		}
 
-		infra.Subnet = subnet
 		return nil
 	}
 
@@ -132,6 +262,7 @@ func (a *AWS) CreateCluster(
 		}
 
 		routeTable, err := infrastructure.CreateRouteTable(
+			ctx,
 			ec2Client,
 			prefixResource("route-table"),
 			infra.VPC.ID,
@@ -149,11 +280,11 @@ func (a *AWS) CreateCluster(
 		clusterInfraQueue,
 		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
 			func(*ClusterInfrastructure) error {
-				stepper.StartTemporaryStep("Creating a subnet and a route table")
+				stepper.StartTemporaryStep("Creating the subnets and a route table")
 				return nil
 			},
 			attachInternetGatewayToVPC,
-			createSubnet,
+			createSubnets,
 			createRouteTable,
 		},
 	)
@@ -164,9 +295,12 @@ func (a *AWS) CreateCluster(
 		}
 
 		route, err := infrastructure.CreateRoute(
+			ctx,
 			ec2Client,
+			infrastructure.RouteTargetKindInternetGateway,
 			infra.InternetGateway.ID,
 			infra.RouteTable.ID,
+			"0.0.0.0/0",
 		)
 
 		if err != nil {
@@ -177,21 +311,54 @@ func (a *AWS) CreateCluster(
 		return nil
 	}
 
-	associateRouteTable := func(infra *ClusterInfrastructure) error {
-		if infra.RouteTable.IsAssociatedToSubnet {
+	createIPv6Route := func(infra *ClusterInfrastructure) error {
+		if infra.IPv6Route != nil || !infra.isDualStack() {
 			return nil
 		}
 
-		err := infrastructure.AssociateRouteTable(
+		route, err := infrastructure.CreateRoute(
+			ctx,
 			ec2Client,
-			infra.Subnet.ID,
+			infrastructure.RouteTargetKindInternetGateway,
+			infra.InternetGateway.ID,
 			infra.RouteTable.ID,
+			"::/0",
 		)
 
 		if err != nil {
 			return err
 		}
 
+		infra.IPv6Route = route
+		return nil
+	}
+
+	// RouteTable.IsAssociatedToSubnet is set
+	// once all the subnets are associated
+	associateRouteTable := func(infra *ClusterInfrastructure) error {
+		if infra.RouteTable.IsAssociatedToSubnet {
+			return nil
+		}
+
+		for _, subnet := range infra.subnets() {
+			if subnet.IsAssociatedToRouteTable {
+				continue
+			}
+
+			err := infrastructure.AssociateRouteTable(
+				ctx,
+				ec2Client,
+				subnet.ID,
+				infra.RouteTable.ID,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			subnet.IsAssociatedToRouteTable = true
+		}
+
 		infra.RouteTable.IsAssociatedToSubnet = true
 		return nil
 	}
@@ -204,10 +371,205 @@ func (a *AWS) CreateCluster(
 				return nil
 			},
 			createRoute,
+			createIPv6Route,
 			associateRouteTable,
 		},
 	)
 
+	// Private subnets are created in the
+	// availability zones of the public ones
+	createPrivateSubnets := func(infra *ClusterInfrastructure) error {
+		if !infra.isPrivate() || network == nil ||
+			len(infra.PrivateSubnets) >= len(network.PrivateSubnetCIDRBlocks) {
+
+			return nil
+		}
+
+		for subnetIndex := len(infra.PrivateSubnets); subnetIndex < len(network.PrivateSubnetCIDRBlocks); subnetIndex++ {
+			subnetName := "private-subnet"
+			availabilityZone := infra.subnets()[subnetIndex].AvailabilityZone
+
+			if len(network.AvailabilityZones) > 0 {
+				subnetName = fmt.Sprintf("private-subnet-%s", availabilityZone)
+			}
+
+			subnet, err := infrastructure.CreateSubnet(
+				ctx,
+				ec2Client,
+				a.opts.WaitOpts,
+				prefixResource(subnetName),
+				network.PrivateSubnetCIDRBlocks[subnetIndex],
+				"",
+				availabilityZone,
+				infra.VPC.ID,
+				false,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			infra.PrivateSubnets = append(infra.PrivateSubnets, subnet)
+		}
+
+		return nil
+	}
+
+	createPrivateRouteTable := func(infra *ClusterInfrastructure) error {
+		if !infra.isPrivate() || infra.PrivateRouteTable != nil {
+			return nil
+		}
+
+		routeTable, err := infrastructure.CreateRouteTable(
+			ctx,
+			ec2Client,
+			prefixResource("private-route-table"),
+			infra.VPC.ID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.PrivateRouteTable = routeTable
+		return nil
+	}
+
+	createNATGatewayElasticIP := func(infra *ClusterInfrastructure) error {
+		if infra.PrivateEgress != PrivateEgressNATGateway ||
+			infra.NATGatewayElasticIP != nil {
+
+			return nil
+		}
+
+		elasticIP, err := infrastructure.CreateElasticIP(
+			ctx,
+			ec2Client,
+			prefixResource("nat-gateway-elastic-ip"),
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.NATGatewayElasticIP = elasticIP
+		return nil
+	}
+
+	clusterInfraQueue = append(
+		clusterInfraQueue,
+		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
+			func(infra *ClusterInfrastructure) error {
+				if infra.isPrivate() {
+					stepper.StartTemporaryStep("Creating the private subnets and a route table")
+				}
+				return nil
+			},
+			createPrivateSubnets,
+			createPrivateRouteTable,
+			createNATGatewayElasticIP,
+		},
+	)
+
+	createNATGateway := func(infra *ClusterInfrastructure) error {
+		if infra.PrivateEgress != PrivateEgressNATGateway || infra.NATGateway != nil {
+			return nil
+		}
+
+		NATGateway, err := infrastructure.CreateNATGateway(
+			ctx,
+			ec2Client,
+			a.opts.WaitOpts,
+			prefixResource("nat-gateway"),
+			infra.subnets()[0].ID,
+			infra.NATGatewayElasticIP.ID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.NATGateway = NATGateway
+		return nil
+	}
+
+	associatePrivateRouteTable := func(infra *ClusterInfrastructure) error {
+		if !infra.isPrivate() || infra.PrivateRouteTable.IsAssociatedToSubnet {
+			return nil
+		}
+
+		for _, subnet := range infra.PrivateSubnets {
+			if subnet.IsAssociatedToRouteTable {
+				continue
+			}
+
+			err := infrastructure.AssociateRouteTable(
+				ctx,
+				ec2Client,
+				subnet.ID,
+				infra.PrivateRouteTable.ID,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			subnet.IsAssociatedToRouteTable = true
+		}
+
+		infra.PrivateRouteTable.IsAssociatedToSubnet = true
+		return nil
+	}
+
+	clusterInfraQueue = append(
+		clusterInfraQueue,
+		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
+			func(infra *ClusterInfrastructure) error {
+				if infra.PrivateEgress == PrivateEgressNATGateway {
+					stepper.StartTemporaryStep("Creating a NAT gateway")
+				}
+				return nil
+			},
+			createNATGateway,
+			associatePrivateRouteTable,
+		},
+	)
+
+	createNATRoute := func(infra *ClusterInfrastructure) error {
+		if infra.PrivateEgress != PrivateEgressNATGateway || infra.NATRoute != nil {
+			return nil
+		}
+
+		route, err := infrastructure.CreateRoute(
+			ctx,
+			ec2Client,
+			infrastructure.RouteTargetKindNATGateway,
+			infra.NATGateway.ID,
+			infra.PrivateRouteTable.ID,
+			"0.0.0.0/0",
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.NATRoute = route
+		return nil
+	}
+
+	clusterInfraQueue = append(
+		clusterInfraQueue,
+		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
+			func(infra *ClusterInfrastructure) error {
+				if infra.PrivateEgress == PrivateEgressNATGateway {
+					stepper.StartTemporaryStep("Adding a route to the NAT gateway")
+				}
+				return nil
+			},
+			createNATRoute,
+		},
+	)
+
 	err := clusterInfraQueue.Run(
 		clusterInfra,
 	)
@@ -216,5 +578,5 @@ func (a *AWS) CreateCluster(
 	// in case of error (partial infrastructure)
 	cluster.SetInfrastructureJSON(clusterInfra)
 
-	return err
+	return wrapCanceledError(ctx, err)
 }
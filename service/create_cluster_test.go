@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/eleven-sh/aws-cloud-provider/mocks"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/stepper"
+	"github.com/golang/mock/gomock"
+)
+
+type noopStepper struct {
+	stepper.Stepper
+}
+
+func (noopStepper) StartTemporaryStep(string) {}
+
+func TestCreateClusterWithVPCCreationError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	createVPCErr := errors.New("CreateVPCError")
+	internetGatewayID := "igw-1"
+
+	ec2Client := mocks.NewEC2Client(mockCtrl)
+	ec2Client.EXPECT().
+		CreateVpc(gomock.Any(), gomock.Any()).
+		Return(nil, createVPCErr).
+		Times(1)
+
+	ec2Client.EXPECT().
+		CreateInternetGateway(gomock.Any(), gomock.Any()).
+		Return(&ec2.CreateInternetGatewayOutput{
+			InternetGateway: &types.InternetGateway{
+				InternetGatewayId: aws.String(internetGatewayID),
+			},
+		}, nil).
+		Times(1)
+
+	ec2Client.EXPECT().
+		DescribeInternetGateways(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&ec2.DescribeInternetGatewaysOutput{
+			InternetGateways: []types.InternetGateway{{
+				InternetGatewayId: aws.String(internetGatewayID),
+			}},
+		}, nil).
+		Times(1)
+
+	dynamoDBClient := mocks.NewDynamoDBClient(mockCtrl)
+
+	AWSService := service.NewAWSWithClients(
+		aws.Config{},
+		ec2Client,
+		dynamoDBClient,
+		service.AWSOpts{},
+	)
+
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	err := AWSService.CreateCluster(
+		context.Background(),
+		noopStepper{},
+		&entities.Config{},
+		cluster,
+	)
+
+	if !errors.Is(err, createVPCErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			createVPCErr,
+			err,
+		)
+	}
+
+	var clusterInfra *service.ClusterInfrastructure
+	err = json.Unmarshal([]byte(cluster.InfrastructureJSON), &clusterInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if clusterInfra.VPC != nil {
+		t.Fatalf("expected no VPC, got '%+v'", clusterInfra.VPC)
+	}
+
+	if clusterInfra.InternetGateway == nil ||
+		clusterInfra.InternetGateway.ID != internetGatewayID {
+
+		t.Fatalf(
+			"expected internet gateway '%s' to be recorded, got '%+v'",
+			internetGatewayID,
+			clusterInfra.InternetGateway,
+		)
+	}
+}
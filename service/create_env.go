@@ -1,12 +1,10 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	agentConfig "github.com/eleven-sh/agent/config"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
@@ -15,7 +13,20 @@ import (
 	"github.com/eleven-sh/eleven/stepper"
 )
 
+// ErrInstanceTypeNotOffered represents the error returned
+// when the instance type is not offered in the availability
+// zones of the cluster subnets.
+type ErrInstanceTypeNotOffered struct {
+	InstanceType      string
+	AvailabilityZones []string
+}
+
+func (ErrInstanceTypeNotOffered) Error() string {
+	return "ErrInstanceTypeNotOffered"
+}
+
 type EnvInfrastructure struct {
+	Subnet            *infrastructure.Subnet            `json:"subnet"`
 	SecurityGroup     *infrastructure.SecurityGroup     `json:"security_group"`
 	KeyPair           *infrastructure.KeyPair           `json:"key_pair"`
 	NetworkInterface  *infrastructure.NetworkInterface  `json:"network_interface"`
@@ -23,13 +34,85 @@ type EnvInfrastructure struct {
 	InstanceAMI       *infrastructure.AMI               `json:"instance_ami"`
 	Instance          *infrastructure.Instance          `json:"instance"`
 	ElasticIP         *infrastructure.ElasticIP         `json:"elastic_ip"`
+	IngressCIDRs      []string                          `json:"ingress_cidrs"`
+	PortExpirations   []PortExpiration                  `json:"port_expirations"`
+	RootVolume        *RootVolumeOpts                   `json:"root_volume"`
+
+	// Private instances have no elastic IP
+	// and are reached via SSM Session Manager
+	IsPrivate bool `json:"is_private"`
+
+	// Set while a running env is resized so that a retried
+	// resize starts the instance even if it was left stopped
+	StartInstanceOnceResized bool `json:"start_instance_once_resized"`
+}
+
+// isDualStack returns true if the instance
+// was assigned an IPv6 address.
+func (e *EnvInfrastructure) isDualStack() bool {
+	return e.NetworkInterface != nil && len(e.NetworkInterface.IPv6Address) > 0
+}
+
+// isInstanceStopped returns true if the instance
+// was stopped via StopEnv or HibernateEnv.
+func (e *EnvInfrastructure) isInstanceStopped() bool {
+	return e.Instance.PowerState == infrastructure.InstancePowerStateStopped ||
+		e.Instance.PowerState == infrastructure.InstancePowerStateHibernated
+}
+
+// ingressCIDRs returns the CIDRs allowed to reach the instance.
+// Envs created before the CIDRs were recorded allow all IPv4 addresses.
+func (e *EnvInfrastructure) ingressCIDRs() []string {
+	if len(e.IngressCIDRs) == 0 {
+		return infrastructure.DefaultIngressCIDRs
+	}
+
+	return e.IngressCIDRs
+}
+
+// CreateEnvOpts represents the options
+// used to create an env.
+type CreateEnvOpts struct {
+	// RootVolume specifies the size, the type, the performance
+	// and the encryption of the root volume of the env instance.
+	// Default to AWSOpts.RootVolume if not set.
+	RootVolume *RootVolumeOpts
+
+	// IngressCIDRs specifies the IPv4 and IPv6 CIDRs allowed to reach
+	// the env instance. They replace the ones resolved from AWSOpts
+	// (IngressCIDRs and AutoDetectIngressIP) if set.
+	// Ignored for the private envs.
+	IngressCIDRs []string
 }
 
 func (a *AWS) CreateEnv(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) error {
+
+	return a.CreateEnvWithOpts(
+		ctx,
+		stepper,
+		config,
+		cluster,
+		env,
+		CreateEnvOpts{},
+	)
+}
+
+// CreateEnvWithOpts works like CreateEnv. The root volume options
+// are recorded in the env infrastructure so that a retried creation
+// (via CreateEnv) uses the options passed on the first attempt.
+func (a *AWS) CreateEnvWithOpts(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 	cluster *entities.Cluster,
 	env *entities.Env,
+	opts CreateEnvOpts,
 ) error {
 
 	var clusterInfra *ClusterInfrastructure
@@ -48,8 +131,75 @@ func (a *AWS) CreateEnv(
 		}
 	}
 
+	// Envs are private in the clusters created with private
+	// subnets or deployed into existing (private) subnets
+	if envInfra.SecurityGroup == nil {
+		envInfra.IsPrivate = clusterInfra.isPrivate() ||
+			(clusterInfra.VPC != nil && clusterInfra.VPC.IsExternallyOwned &&
+				a.opts.PrivateNetworking.Enabled)
+	}
+
+	if envInfra.IsPrivate && envInfra.Instance == nil &&
+		len(a.opts.PrivateNetworking.InstanceProfileName) == 0 {
+
+		return ErrMissingInstanceProfile{}
+	}
+
+	// Returned early as the SSH dial errors
+	// are retried until the SSH wait timeout
+	err = infrastructure.ValidateSSHJumpHosts(a.opts.SSHJumpHosts)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.Instance == nil {
+		if opts.RootVolume != nil {
+			rootVolumeOpts := *opts.RootVolume
+			envInfra.RootVolume = &rootVolumeOpts
+		} else if envInfra.RootVolume == nil {
+			rootVolumeOpts := a.opts.RootVolume
+			envInfra.RootVolume = &rootVolumeOpts
+		}
+
+		err := envInfra.RootVolume.validate()
+
+		if err != nil {
+			return err
+		}
+
+		err = a.opts.Spot.validate()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// Resolved before any resource is created
+	// to return invalid CIDRs early.
+	// Private instances accept no public ingress.
+	if envInfra.SecurityGroup == nil && !envInfra.IsPrivate {
+		if len(opts.IngressCIDRs) > 0 {
+			ingressCIDRs, err := normalizeIngressCIDRs(opts.IngressCIDRs)
+
+			if err != nil {
+				return err
+			}
+
+			envInfra.IngressCIDRs = ingressCIDRs
+		} else {
+			ingressCIDRs, err := a.resolveIngressCIDRs(ctx, clusterInfra.isDualStack())
+
+			if err != nil {
+				return wrapCanceledError(ctx, err)
+			}
+
+			envInfra.IngressCIDRs = ingressCIDRs
+		}
+	}
+
 	prefixResource := prefixEnvResource(cluster.GetNameSlug(), env.GetNameSlug())
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	ec2Client := a.ec2Client
 
 	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
 
@@ -58,74 +208,33 @@ func (a *AWS) CreateEnv(
 			return nil
 		}
 
-		elevenSSHServerListenPort, _ := strconv.ParseInt(
-			agentConfig.SSHServerListenPort,
-			10,
-			64,
-		)
-
-		elevenHTTPServerListenPort, _ := strconv.ParseInt(
-			agentConfig.HTTPServerListenPort,
-			10,
-			64,
-		)
-
-		elevenHTTPSServerListenPort, _ := strconv.ParseInt(
-			agentConfig.HTTPSServerListenPort,
-			10,
-			64,
-		)
+		// Private instances are reached via SSM
+		// (no ingress rule is needed)
+		ingressPorts := []types.IpPermission{}
+
+		if !infra.IsPrivate {
+			for _, managedPort := range elevenManagedPorts() {
+				ingressPorts = append(
+					ingressPorts,
+					infrastructure.PortSpec{
+						Protocol:    infrastructure.PortSpecProtocolTCP,
+						FromPort:    managedPort,
+						ToPort:      managedPort,
+						CIDRs:       infra.ingressCIDRs(),
+						Description: elevenManagedPortDescription,
+					}.IPPermission(),
+				)
+			}
+		}
 
 		securityGroup, err := infrastructure.CreateSecurityGroup(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			prefixResource("security-group"),
 			"The security group attached to your sandbox",
 			clusterInfra.VPC.ID,
-			[]types.IpPermission{
-				{
-					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int32(infrastructure.InstanceSSHPort),
-					ToPort:     aws.Int32(infrastructure.InstanceSSHPort),
-					IpRanges: []types.IpRange{
-						{
-							CidrIp: aws.String("0.0.0.0/0"),
-						},
-					},
-				},
-
-				{
-					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int32(int32(elevenSSHServerListenPort)),
-					ToPort:     aws.Int32(int32(elevenSSHServerListenPort)),
-					IpRanges: []types.IpRange{
-						{
-							CidrIp: aws.String("0.0.0.0/0"),
-						},
-					},
-				},
-
-				{
-					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int32(int32(elevenHTTPServerListenPort)),
-					ToPort:     aws.Int32(int32(elevenHTTPServerListenPort)),
-					IpRanges: []types.IpRange{
-						{
-							CidrIp: aws.String("0.0.0.0/0"),
-						},
-					},
-				},
-
-				{
-					IpProtocol: aws.String("tcp"),
-					FromPort:   aws.Int32(int32(elevenHTTPSServerListenPort)),
-					ToPort:     aws.Int32(int32(elevenHTTPSServerListenPort)),
-					IpRanges: []types.IpRange{
-						{
-							CidrIp: aws.String("0.0.0.0/0"),
-						},
-					},
-				},
-			},
+			ingressPorts,
 		)
 
 		if err != nil {
@@ -136,13 +245,65 @@ func (a *AWS) CreateEnv(
 		return nil
 	}
 
+	// The subnet is chosen according to the
+	// availability zones where the instance
+	// type is offered
+	chooseSubnet := func(infra *EnvInfrastructure) error {
+		if infra.Subnet != nil || infra.NetworkInterface != nil {
+			return nil
+		}
+
+		instanceTypeAvailabilityZones, err := infrastructure.LookupInstanceTypeAvailabilityZones(
+			ctx,
+			ec2Client,
+			env.InstanceType,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		isOfferedInAvailabilityZone := map[string]bool{}
+
+		for _, availabilityZone := range instanceTypeAvailabilityZones {
+			isOfferedInAvailabilityZone[availabilityZone] = true
+		}
+
+		subnets := clusterInfra.subnets()
+
+		if clusterInfra.isPrivate() {
+			subnets = clusterInfra.PrivateSubnets
+		}
+
+		subnetAvailabilityZones := []string{}
+
+		for _, subnet := range subnets {
+			if isOfferedInAvailabilityZone[subnet.AvailabilityZone] {
+				infra.Subnet = subnet
+				return nil
+			}
+
+			subnetAvailabilityZones = append(
+				subnetAvailabilityZones,
+				subnet.AvailabilityZone,
+			)
+		}
+
+		return ErrInstanceTypeNotOffered{
+			InstanceType:      env.InstanceType,
+			AvailabilityZones: subnetAvailabilityZones,
+		}
+	}
+
 	createKeyPair := func(infra *EnvInfrastructure) error {
 		if infra.KeyPair != nil {
 			return nil
 		}
 
 		keyPair, err := infrastructure.CreateKeyPair(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			prefixResource("key-pair"),
 		)
 
@@ -155,11 +316,12 @@ func (a *AWS) CreateEnv(
 	}
 
 	createElasticIP := func(infra *EnvInfrastructure) error {
-		if infra.ElasticIP != nil {
+		if infra.ElasticIP != nil || infra.IsPrivate {
 			return nil
 		}
 
 		elasticIP, err := infrastructure.CreateElasticIP(
+			ctx,
 			ec2Client,
 			prefixResource("elastic-ip"),
 		)
@@ -179,6 +341,7 @@ func (a *AWS) CreateEnv(
 				stepper.StartTemporaryStep("Creating a security group, a key pair and an elastic IP")
 				return nil
 			},
+			chooseSubnet,
 			createSecurityGroup,
 			createKeyPair,
 			createElasticIP,
@@ -191,11 +354,14 @@ func (a *AWS) CreateEnv(
 		}
 
 		networkInterface, err := infrastructure.CreateNetworkInterface(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			prefixResource("network-interface"),
 			"The network interface attached to your sandbox",
-			clusterInfra.Subnet.ID,
+			infra.Subnet.ID,
 			[]string{infra.SecurityGroup.ID},
+			clusterInfra.isDualStack() && !infra.IsPrivate,
 		)
 
 		if err != nil {
@@ -223,8 +389,10 @@ func (a *AWS) CreateEnv(
 		}
 
 		instanceTypeInfos, err := infrastructure.LookupInstanceTypeInfos(
+			ctx,
 			ec2Client,
 			env.InstanceType,
+			a.opts.Spot.instanceUsageClass(),
 		)
 
 		if err != nil {
@@ -252,7 +420,9 @@ func (a *AWS) CreateEnv(
 		}
 
 		instanceAMI, err := infrastructure.LookupUbuntuAMIForArch(
+			ctx,
 			ec2Client,
+			a.partition,
 			infra.InstanceTypeInfos.Arch,
 		)
 
@@ -280,14 +450,42 @@ func (a *AWS) CreateEnv(
 			return nil
 		}
 
+		instanceProfileName := ""
+
+		if infra.IsPrivate {
+			instanceProfileName = a.opts.PrivateNetworking.InstanceProfileName
+		}
+
+		rootVolumeOpts := infra.RootVolume.instanceRootVolumeOpts()
+
+		if a.opts.EnableHibernation {
+			err := checkHibernationSupport(infra)
+
+			if err != nil {
+				return err
+			}
+
+			rootVolumeOpts.SizeGb = infra.InstanceTypeInfos.HibernationRootVolumeSizeGb(
+				rootVolumeOpts.SizeGb,
+			)
+		}
+
 		instance, err := infrastructure.CreateInstance(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			prefixResource("instance"),
 			infra.InstanceAMI.ID,
 			infra.InstanceAMI.RootDeviceName,
 			infra.InstanceTypeInfos.Type,
 			infra.NetworkInterface.ID,
 			infra.KeyPair.Name,
+			infrastructure.CreateInstanceOpts{
+				InstanceProfileName: instanceProfileName,
+				RootVolume:          rootVolumeOpts,
+				EnableHibernation:   a.opts.EnableHibernation,
+				Spot:                a.opts.Spot.instanceSpotOpts(),
+			},
 		)
 
 		if err != nil {
@@ -309,15 +507,41 @@ func (a *AWS) CreateEnv(
 		},
 	)
 
+	// The SSH host keys are retrieved out of band
+	// (via the console output) to authenticate
+	// the instance during the init script lookup
+	lookupInstanceSSHHostKeys := func(infra *EnvInfrastructure) error {
+		return a.lookupEnvInstanceSSHHostKeys(ctx, infra)
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Retrieving the SSH host keys of the EC2 instance")
+				return nil
+			},
+			lookupInstanceSSHHostKeys,
+		},
+	)
+
 	lookupInstanceInitScriptResults := func(infra *EnvInfrastructure) error {
 		if infra.Instance.InitScriptResults != nil {
 			return nil
 		}
 
-		initScriptResults, err := infrastructure.LookupInitInstanceScriptResults(
-			ec2Client,
+		dialer, instanceHost := a.envInstanceDialer(
+			infra,
 			infra.Instance.TmpPublicIPAddress,
+		)
+
+		initScriptResults, err := infrastructure.LookupInitInstanceScriptResults(
+			ctx,
+			dialer,
+			a.opts.WaitOpts,
+			instanceHost,
 			fmt.Sprintf("%d", infrastructure.InstanceSSHPort),
+			infra.Instance.SSHHostKeys,
 			infrastructure.InstanceRootUser,
 			infra.KeyPair.PEMContent,
 		)
@@ -346,11 +570,12 @@ func (a *AWS) CreateEnv(
 	// instance initialization (due to IP
 	// switching).
 	attachElasticIP := func(infra *EnvInfrastructure) error {
-		if infra.ElasticIP.IsAttachedToInstance {
+		if infra.IsPrivate || infra.ElasticIP.IsAttachedToInstance {
 			return nil
 		}
 
 		associationID, err := infrastructure.AttachElasticIPToInstance(
+			ctx,
 			ec2Client,
 			infra.ElasticIP.ID,
 			infra.Instance.ID,
@@ -369,8 +594,10 @@ func (a *AWS) CreateEnv(
 	envInfraQueue = append(
 		envInfraQueue,
 		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
-			func(*EnvInfrastructure) error {
-				stepper.StartTemporaryStep("Attaching a public IP to the instance")
+			func(infra *EnvInfrastructure) error {
+				if !infra.IsPrivate {
+					stepper.StartTemporaryStep("Attaching a public IP to the instance")
+				}
 				return nil
 			},
 			attachElasticIP,
@@ -378,18 +605,18 @@ func (a *AWS) CreateEnv(
 	)
 
 	waitForEIPToBeReachable := func(infra *EnvInfrastructure) error {
-		return infrastructure.WaitForSSHAvailableInInstance(
-			ec2Client,
-			infra.ElasticIP.Address,
-			agentConfig.SSHServerListenPort,
-		)
+		return a.waitForEnvInstanceToBeReachable(ctx, infra)
 	}
 
 	envInfraQueue = append(
 		envInfraQueue,
 		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
-			func(*EnvInfrastructure) error {
-				stepper.StartTemporaryStep("Waiting for the public IP to be reachable")
+			func(infra *EnvInfrastructure) error {
+				if infra.IsPrivate {
+					stepper.StartTemporaryStep("Waiting for the instance to be reachable via SSM")
+				} else {
+					stepper.StartTemporaryStep("Waiting for the public IP to be reachable")
+				}
 				return nil
 			},
 			waitForEIPToBeReachable,
@@ -403,13 +630,68 @@ func (a *AWS) CreateEnv(
 	env.SetInfrastructureJSON(envInfra)
 
 	if err != nil {
-		return err
+		return wrapCanceledError(ctx, err)
 	}
 
-	env.InstancePublicIPAddress = envInfra.ElasticIP.Address
+	// Private envs are reached via the
+	// dialer returned by EnvAgentDialer
+	if envInfra.ElasticIP != nil {
+		env.InstancePublicIPAddress = envInfra.ElasticIP.Address
+	}
 
 	env.SSHHostKeys = envInfra.Instance.InitScriptResults.SSHHostKeys
 	env.SSHKeyPairPEMContent = envInfra.KeyPair.PEMContent
 
 	return nil
 }
+
+// lookupEnvInstanceSSHHostKeys retrieves the SSH host keys
+// of the instance of the env if they are not already known.
+func (a *AWS) lookupEnvInstanceSSHHostKeys(
+	ctx context.Context,
+	envInfra *EnvInfrastructure,
+) error {
+
+	if len(envInfra.Instance.SSHHostKeys) > 0 {
+		return nil
+	}
+
+	instanceSSHHostKeys, err := infrastructure.LookupInstanceSSHHostKeys(
+		ctx,
+		a.ec2Client,
+		a.opts.WaitOpts,
+		envInfra.Instance.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	envInfra.Instance.SSHHostKeys = instanceSSHHostKeys
+	return nil
+}
+
+// waitForEnvInstanceToBeReachable waits for the agent
+// SSH server of the env instance to accept connections.
+// Private instances are only reachable via SSM.
+func (a *AWS) waitForEnvInstanceToBeReachable(
+	ctx context.Context,
+	envInfra *EnvInfrastructure,
+) error {
+
+	publicIPAddress := ""
+
+	if envInfra.ElasticIP != nil {
+		publicIPAddress = envInfra.ElasticIP.Address
+	}
+
+	dialer, instanceHost := a.envInstanceDialer(envInfra, publicIPAddress)
+
+	return infrastructure.WaitForSSHAvailableInInstance(
+		ctx,
+		dialer,
+		a.opts.WaitOpts,
+		instanceHost,
+		agentConfig.SSHServerListenPort,
+	)
+}
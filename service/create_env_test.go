@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/mocks"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCreateEnvWithSecurityGroupCreationError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	createSecurityGroupErr := errors.New("CreateSecurityGroupError")
+
+	ec2Client := mocks.NewEC2Client(mockCtrl)
+	ec2Client.EXPECT().
+		CreateSecurityGroup(gomock.Any(), gomock.Any()).
+		Return(nil, createSecurityGroupErr).
+		Times(1)
+
+	ec2Client.EXPECT().
+		CreateKeyPair(gomock.Any(), gomock.Any()).
+		Return(nil, createSecurityGroupErr).
+		AnyTimes()
+
+	ec2Client.EXPECT().
+		AllocateAddress(gomock.Any(), gomock.Any()).
+		Return(nil, createSecurityGroupErr).
+		AnyTimes()
+
+	ec2Client.EXPECT().
+		DescribeInstanceTypeOfferings(gomock.Any(), gomock.Any()).
+		Return(nil, createSecurityGroupErr).
+		AnyTimes()
+
+	AWSService := service.NewAWSWithClients(
+		aws.Config{},
+		ec2Client,
+		mocks.NewDynamoDBClient(mockCtrl),
+		service.AWSOpts{},
+	)
+
+	clusterInfraJSON, err := json.Marshal(service.ClusterInfrastructure{
+		VPC: &infrastructure.VPC{
+			ID: "vpc-1",
+		},
+		Subnets: []*infrastructure.Subnet{{
+			ID: "subnet-1",
+		}},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(
+		context.Background(),
+		noopStepper{},
+		&entities.Config{},
+		&entities.Cluster{
+			Name:               entities.DefaultClusterName,
+			InfrastructureJSON: string(clusterInfraJSON),
+		},
+		env,
+	)
+
+	if !errors.Is(err, createSecurityGroupErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			createSecurityGroupErr,
+			err,
+		)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if envInfra.SecurityGroup != nil {
+		t.Fatalf("expected no security group, got '%+v'", envInfra.SecurityGroup)
+	}
+}
+
+// cancelingDialer cancels the context
+// of the operation on first dial.
+type cancelingDialer struct {
+	infrastructure.Dialer
+	cancel context.CancelFunc
+}
+
+func (c cancelingDialer) DialContext(
+	ctx context.Context,
+	network string,
+	address string,
+) (net.Conn, error) {
+
+	c.cancel()
+	return c.Dialer.DialContext(ctx, network, address)
+}
+
+func TestCreateEnvWithCanceledContext(t *testing.T) {
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: cancelingDialer{
+			Dialer: fakeAWS.Dialer(),
+			cancel: cancel,
+		},
+	})
+
+	config := &entities.Config{}
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	err := AWSService.CreateCluster(context.Background(), noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	var canceledErr service.ErrCanceled
+	if !errors.As(err, &canceledErr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrCanceled{Err: context.Canceled},
+			err,
+		)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if envInfra.Instance == nil || envInfra.Instance.InitScriptResults != nil {
+		t.Fatalf(
+			"expected instance to be recorded without init script results, got '%+v'",
+			envInfra.Instance,
+		)
+	}
+
+	err = AWSService.RemoveEnv(context.Background(), noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(context.Background(), noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestDualStackClusterAndEnv(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+		EnableIPv6: true,
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	var clusterInfra *service.ClusterInfrastructure
+	err := json.Unmarshal([]byte(cluster.InfrastructureJSON), &clusterInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	_, VPCIPv6Network, err := net.ParseCIDR(clusterInfra.VPC.IPv6CIDRBlock)
+
+	if err != nil {
+		t.Fatalf(
+			"expected VPC to have an IPv6 CIDR block, got '%+v'",
+			clusterInfra.VPC.IPv6CIDRBlock,
+		)
+	}
+
+	subnetIPv6IP, subnetIPv6Network, err := net.ParseCIDR(clusterInfra.Subnets[0].IPv6CIDRBlock)
+
+	if err != nil {
+		t.Fatalf(
+			"expected subnet to have an IPv6 CIDR block, got '%+v'",
+			clusterInfra.Subnets[0].IPv6CIDRBlock,
+		)
+	}
+
+	subnetIPv6MaskSize, _ := subnetIPv6Network.Mask.Size()
+
+	if !VPCIPv6Network.Contains(subnetIPv6IP) || subnetIPv6MaskSize != 64 {
+		t.Fatalf(
+			"expected subnet IPv6 CIDR block to be a /64 of '%+v', got '%+v'",
+			clusterInfra.VPC.IPv6CIDRBlock,
+			clusterInfra.Subnets[0].IPv6CIDRBlock,
+		)
+	}
+
+	if clusterInfra.IPv6Route == nil {
+		t.Fatalf("expected IPv6 route to be created, got nil")
+	}
+
+	ec2Client := ec2.NewFromConfig(fakeAWS.Config())
+
+	describeRouteTablesResp, err := ec2Client.DescribeRouteTables(
+		ctx,
+		&ec2.DescribeRouteTablesInput{
+			RouteTableIds: []string{clusterInfra.RouteTable.ID},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	hasIPv6DefaultRoute := false
+
+	for _, route := range describeRouteTablesResp.RouteTables[0].Routes {
+		if aws.ToString(route.DestinationIpv6CidrBlock) == "::/0" &&
+			aws.ToString(route.GatewayId) == clusterInfra.InternetGateway.ID {
+
+			hasIPv6DefaultRoute = true
+		}
+	}
+
+	if !hasIPv6DefaultRoute {
+		t.Fatalf(
+			"expected route table to have a '::/0' route, got '%+v'",
+			describeRouteTablesResp.RouteTables[0].Routes,
+		)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	instanceIPv6Address := net.ParseIP(envInfra.NetworkInterface.IPv6Address)
+
+	if instanceIPv6Address == nil || !subnetIPv6Network.Contains(instanceIPv6Address) {
+		t.Fatalf(
+			"expected network interface to have an IPv6 address in '%+v', got '%+v'",
+			clusterInfra.Subnets[0].IPv6CIDRBlock,
+			envInfra.NetworkInterface.IPv6Address,
+		)
+	}
+
+	securityGroupID := envInfra.SecurityGroup.ID
+	expectedSSHPortCIDRs := []string{"0.0.0.0/0", "::/0"}
+
+	SSHPortCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, 22)
+
+	if !reflect.DeepEqual(SSHPortCIDRs, expectedSSHPortCIDRs) {
+		t.Fatalf(
+			"expected SSH port CIDRs to equal '%+v', got '%+v'",
+			expectedSSHPortCIDRs,
+			SSHPortCIDRs,
+		)
+	}
+
+	err = AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "8080@2001:db8::/32")
+
+	if err != nil {
+		t.Fatalf("expected no error during port opening, got '%+v'", err)
+	}
+
+	expectedOpenedPortCIDRs := []string{"2001:db8::/32"}
+	openedPortCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, 8080)
+
+	if !reflect.DeepEqual(openedPortCIDRs, expectedOpenedPortCIDRs) {
+		t.Fatalf(
+			"expected opened port CIDRs to equal '%+v', got '%+v'",
+			expectedOpenedPortCIDRs,
+			openedPortCIDRs,
+		)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+}
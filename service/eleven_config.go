@@ -1,41 +1,46 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/entities"
 	"github.com/eleven-sh/eleven/stepper"
 )
 
 func (a *AWS) CreateElevenConfigStorage(
+	ctx context.Context,
 	stepper stepper.Stepper,
 ) error {
 
-	dynamoDBClient := dynamodb.NewFromConfig(a.sdkConfig)
+	dynamoDBClient := a.dynamoDBClient
 
 	stepper.StartTemporaryStep("Creating a DynamoDB table to store the Eleven configuration")
 
 	err := infrastructure.CreateDynamoDBTableForElevenConfig(
+		ctx,
 		dynamoDBClient,
+		a.opts.WaitOpts,
 	)
 
 	if err != nil && errors.Is(err, infrastructure.ErrElevenConfigTableAlreadyExists) {
 		return nil
 	}
 
-	return err
+	return wrapCanceledError(ctx, err)
 }
 
 func (a *AWS) LookupElevenConfig(
+	ctx context.Context,
 	stepper stepper.Stepper,
 ) (*entities.Config, error) {
 
-	dynamoDBClient := dynamodb.NewFromConfig(a.sdkConfig)
+	dynamoDBClient := a.dynamoDBClient
 
 	configJSON, err := infrastructure.LookupElevenConfigInDynamoDBTable(
+		ctx,
 		dynamoDBClient,
 	)
 
@@ -46,7 +51,7 @@ func (a *AWS) LookupElevenConfig(
 			return nil, entities.ErrElevenNotInstalled
 		}
 
-		return nil, err
+		return nil, wrapCanceledError(ctx, err)
 	}
 
 	var elevenConfig *entities.Config
@@ -60,6 +65,7 @@ func (a *AWS) LookupElevenConfig(
 }
 
 func (a *AWS) SaveElevenConfig(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 ) error {
@@ -70,24 +76,32 @@ func (a *AWS) SaveElevenConfig(
 		return err
 	}
 
-	dynamoDBClient := dynamodb.NewFromConfig(a.sdkConfig)
+	dynamoDBClient := a.dynamoDBClient
 
-	return infrastructure.UpdateElevenConfigInDynamoDBTable(
+	err = infrastructure.UpdateElevenConfigInDynamoDBTable(
+		ctx,
 		dynamoDBClient,
 		config.ID,
 		string(configJSON),
 	)
+
+	return wrapCanceledError(ctx, err)
 }
 
 func (a *AWS) RemoveElevenConfigStorage(
+	ctx context.Context,
 	stepper stepper.Stepper,
 ) error {
 
-	dynamoDBClient := dynamodb.NewFromConfig(a.sdkConfig)
+	dynamoDBClient := a.dynamoDBClient
 
 	stepper.StartTemporaryStep("Removing the DynamoDB table used to store the Eleven configuration")
 
-	return infrastructure.RemoveDynamoDBTableForElevenConfig(
+	err := infrastructure.RemoveDynamoDBTableForElevenConfig(
+		ctx,
 		dynamoDBClient,
+		a.opts.WaitOpts,
 	)
+
+	return wrapCanceledError(ctx, err)
 }
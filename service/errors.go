@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+)
+
+// ErrCanceled represents the error returned when an
+// operation was interrupted because its context was
+// canceled or its deadline exceeded.
+//
+// Err is set to the context error
+// (context.Canceled or context.DeadlineExceeded).
+type ErrCanceled struct {
+	Err error
+}
+
+func (ErrCanceled) Error() string {
+	return "ErrCanceled"
+}
+
+func (e ErrCanceled) Unwrap() error {
+	return e.Err
+}
+
+// wrapCanceledError converts the passed error
+// to ErrCanceled if ctx is done.
+func wrapCanceledError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	return ErrCanceled{
+		Err: ctx.Err(),
+	}
+}
@@ -0,0 +1,375 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestClusterInExistingNetwork(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	// The landing zone network is created
+	// via another cluster
+	landingZoneAWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: fakeAWS.Dialer(),
+	})
+
+	config := &entities.Config{}
+	landingZoneCluster := &entities.Cluster{
+		Name: "landing-zone",
+	}
+
+	err := landingZoneAWSService.CreateCluster(ctx, noopStepper{}, config, landingZoneCluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during landing zone creation, got '%+v'", err)
+	}
+
+	var landingZoneInfra *service.ClusterInfrastructure
+	err = json.Unmarshal([]byte(landingZoneCluster.InfrastructureJSON), &landingZoneInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	VPCID := landingZoneInfra.VPC.ID
+	subnetID := landingZoneInfra.Subnets[0].ID
+
+	resourceCountsBeforeCluster := fakeAWS.ResourceCounts()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: fakeAWS.Dialer(),
+		ClusterNetwork: service.ClusterNetworkOpts{
+			VPCID:     VPCID,
+			SubnetIDs: []string{subnetID},
+		},
+	})
+
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	err = AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != resourceCountsBeforeCluster {
+		t.Fatalf(
+			"expected no network resources to be created, got '%+v'",
+			resourceCounts,
+		)
+	}
+
+	var clusterInfra *service.ClusterInfrastructure
+	err = json.Unmarshal([]byte(cluster.InfrastructureJSON), &clusterInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if clusterInfra.VPC.ID != VPCID || !clusterInfra.VPC.IsExternallyOwned {
+		t.Fatalf(
+			"expected VPC '%s' to be recorded as externally owned, got '%+v'",
+			VPCID,
+			clusterInfra.VPC,
+		)
+	}
+
+	if len(clusterInfra.Subnets) != 1 ||
+		clusterInfra.Subnets[0].ID != subnetID ||
+		!clusterInfra.Subnets[0].IsExternallyOwned {
+
+		t.Fatalf(
+			"expected subnet '%s' to be recorded as externally owned, got '%+v'",
+			subnetID,
+			clusterInfra.Subnets,
+		)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts = fakeAWS.ResourceCounts()
+
+	if resourceCounts != resourceCountsBeforeCluster {
+		t.Fatalf(
+			"expected existing network resources to be kept, got '%+v'",
+			resourceCounts,
+		)
+	}
+
+	err = landingZoneAWSService.RemoveCluster(ctx, noopStepper{}, config, landingZoneCluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during landing zone removal, got '%+v'", err)
+	}
+
+	resourceCounts = fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestClusterInInvalidExistingNetwork(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	landingZoneAWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: fakeAWS.Dialer(),
+	})
+
+	landingZoneCluster := &entities.Cluster{
+		Name: "landing-zone",
+	}
+
+	err := landingZoneAWSService.CreateCluster(ctx, noopStepper{}, &entities.Config{}, landingZoneCluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during landing zone creation, got '%+v'", err)
+	}
+
+	var landingZoneInfra *service.ClusterInfrastructure
+	err = json.Unmarshal([]byte(landingZoneCluster.InfrastructureJSON), &landingZoneInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	ec2Client := ec2.NewFromConfig(fakeAWS.Config())
+
+	// A private subnet without route table
+	createSubnetResp, err := ec2Client.CreateSubnet(ctx, &ec2.CreateSubnetInput{
+		CidrBlock: aws.String("10.0.1.0/24"),
+		VpcId:     aws.String(landingZoneInfra.VPC.ID),
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	createVPCResp, err := ec2Client.CreateVpc(ctx, &ec2.CreateVpcInput{
+		CidrBlock: aws.String("10.1.0.0/16"),
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	testCases := []struct {
+		test          string
+		networkOpts   service.ClusterNetworkOpts
+		expectedError error
+	}{
+		{
+			test: "with unknown subnet",
+			networkOpts: service.ClusterNetworkOpts{
+				SubnetIDs: []string{"subnet-unknown"},
+			},
+			expectedError: service.ErrSubnetNotFound{},
+		},
+
+		{
+			test: "with VPC without subnets",
+			networkOpts: service.ClusterNetworkOpts{
+				VPCID: "vpc-unknown",
+			},
+			expectedError: service.ErrExistingVPCWithoutSubnets{},
+		},
+
+		{
+			test: "with subnet in another VPC",
+			networkOpts: service.ClusterNetworkOpts{
+				VPCID:     *createVPCResp.Vpc.VpcId,
+				SubnetIDs: []string{landingZoneInfra.Subnets[0].ID},
+			},
+			expectedError: service.ErrSubnetNotInVPC{},
+		},
+
+		{
+			test: "with subnet without egress route",
+			networkOpts: service.ClusterNetworkOpts{
+				SubnetIDs: []string{*createSubnetResp.Subnet.SubnetId},
+			},
+			expectedError: service.ErrSubnetWithoutEgressRoute{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+				InstanceDialer: fakeAWS.Dialer(),
+				ClusterNetwork: tc.networkOpts,
+			})
+
+			cluster := &entities.Cluster{
+				Name: entities.DefaultClusterName,
+			}
+
+			err := AWSService.CreateCluster(ctx, noopStepper{}, &entities.Config{}, cluster)
+
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					tc.expectedError,
+					err,
+				)
+			}
+
+			if len(cluster.InfrastructureJSON) > 0 {
+				t.Fatalf(
+					"expected no cluster infrastructure, got '%+v'",
+					cluster.InfrastructureJSON,
+				)
+			}
+		})
+	}
+}
+
+func TestClusterInExistingPrivateNetwork(t *testing.T) {
+	testCases := []struct {
+		test              string
+		privateNetworking service.PrivateNetworkingOpts
+		expectedError     error
+	}{
+		{
+			test:          "without private networking",
+			expectedError: service.ErrSubnetWithoutInternetGatewayRoute{},
+		},
+
+		{
+			test: "with private networking",
+			privateNetworking: service.PrivateNetworkingOpts{
+				Enabled:             true,
+				Egress:              service.PrivateEgressNATGateway,
+				InstanceProfileName: "eleven-ssm-instance-profile",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			// The landing zone private subnet
+			// is routed to a NAT gateway
+			landingZone := newFakeAWSCluster(t, service.AWSOpts{
+				PrivateNetworking: service.PrivateNetworkingOpts{
+					Enabled:             true,
+					Egress:              service.PrivateEgressNATGateway,
+					InstanceProfileName: "eleven-ssm-instance-profile",
+				},
+			})
+
+			fakeAWS := landingZone.fakeAWS
+
+			var landingZoneInfra *service.ClusterInfrastructure
+			err := json.Unmarshal([]byte(landingZone.cluster.InfrastructureJSON), &landingZoneInfra)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.privateNetworking.Enabled {
+				tc.privateNetworking.PortForwarder = fakeAWS.PortForwarder()
+			}
+
+			AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+				InstanceDialer:    fakeAWS.Dialer(),
+				PrivateNetworking: tc.privateNetworking,
+				ClusterNetwork: service.ClusterNetworkOpts{
+					SubnetIDs: []string{landingZoneInfra.PrivateSubnets[0].ID},
+				},
+			})
+
+			config := &entities.Config{}
+			cluster := &entities.Cluster{
+				Name: "existing-network",
+			}
+
+			err = AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+			if tc.expectedError != nil {
+				if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+			}
+
+			env := &entities.Env{
+				Name:         "eleven-api",
+				InstanceType: "t2.medium",
+			}
+
+			err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if err != nil {
+				t.Fatalf("expected no error during env creation, got '%+v'", err)
+			}
+
+			envInfra := unmarshalEnvInfra(t, env)
+
+			if !envInfra.IsPrivate {
+				t.Fatalf("expected env to be private, got '%+v'", envInfra)
+			}
+
+			err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if err != nil {
+				t.Fatalf("expected no error during env removal, got '%+v'", err)
+			}
+
+			err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+			if err != nil {
+				t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+// fakeAWSEnv represents the default cluster (and, optionally,
+// an "eleven-api" env) created on a fake AWS backend.
+type fakeAWSEnv struct {
+	fakeAWS    *fakeaws.Server
+	AWSService *service.AWS
+	config     *entities.Config
+	cluster    *entities.Cluster
+	env        *entities.Env
+}
+
+// newFakeAWSCluster creates the default cluster on a new fake AWS
+// backend that is closed once the test ends. The instance dialer and
+// the private networking port forwarder default to the ones of the
+// backend if not set.
+func newFakeAWSCluster(t *testing.T, opts service.AWSOpts) *fakeAWSEnv {
+	fakeAWS := fakeaws.NewServer()
+	t.Cleanup(fakeAWS.Close)
+
+	if opts.InstanceDialer == nil {
+		opts.InstanceDialer = fakeAWS.Dialer()
+	}
+
+	if opts.PrivateNetworking.Enabled && opts.PrivateNetworking.PortForwarder == nil {
+		opts.PrivateNetworking.PortForwarder = fakeAWS.PortForwarder()
+	}
+
+	fakeEnv := &fakeAWSEnv{
+		fakeAWS:    fakeAWS,
+		AWSService: service.NewAWS(fakeAWS.Config(), opts),
+		config:     &entities.Config{},
+		cluster: &entities.Cluster{
+			Name: entities.DefaultClusterName,
+		},
+	}
+
+	err := fakeEnv.AWSService.CreateCluster(
+		context.Background(),
+		noopStepper{},
+		fakeEnv.config,
+		fakeEnv.cluster,
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	return fakeEnv
+}
+
+// newFakeAWSEnv creates the default cluster and a "t2.medium"
+// env named "eleven-api" on a new fake AWS backend
+// (see newFakeAWSCluster).
+func newFakeAWSEnv(t *testing.T, opts service.AWSOpts) *fakeAWSEnv {
+	fakeEnv := newFakeAWSCluster(t, opts)
+
+	fakeEnv.env = &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err := fakeEnv.AWSService.CreateEnv(
+		context.Background(),
+		noopStepper{},
+		fakeEnv.config,
+		fakeEnv.cluster,
+		fakeEnv.env,
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	return fakeEnv
+}
+
+func unmarshalEnvInfra(t *testing.T, env *entities.Env) *service.EnvInfrastructure {
+	var envInfra *service.EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	return envInfra
+}
+
+func assertEnvPowerState(t *testing.T, env *entities.Env, expectedPowerState string) {
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if envInfra.Instance.PowerState != expectedPowerState {
+		t.Fatalf(
+			"expected power state to equal '%s', got '%s'",
+			expectedPowerState,
+			envInfra.Instance.PowerState,
+		)
+	}
+}
+
+func assertEnvInstanceType(t *testing.T, env *entities.Env, expectedInstanceType string) {
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if env.InstanceType != expectedInstanceType ||
+		envInfra.Instance.Type != expectedInstanceType ||
+		envInfra.InstanceTypeInfos.Type != expectedInstanceType {
+
+		t.Fatalf(
+			"expected instance type to equal '%s', got '%s', '%s' and '%s'",
+			expectedInstanceType,
+			env.InstanceType,
+			envInfra.Instance.Type,
+			envInfra.InstanceTypeInfos.Type,
+		)
+	}
+}
+
+func envInstanceID(t *testing.T, env *entities.Env) string {
+	return unmarshalEnvInfra(t, env).Instance.ID
+}
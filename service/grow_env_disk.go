@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/queues"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// ErrInvalidEnvDiskSize represents the error returned when
+// the requested size of the root volume of an env is smaller
+// than its current size (volumes could only grow) or out of the
+// sizes supported by EBS.
+type ErrInvalidEnvDiskSize struct {
+	CurrentSizeGb   int32
+	RequestedSizeGb int32
+	MaxSizeGb       int32
+}
+
+func (ErrInvalidEnvDiskSize) Error() string {
+	return "ErrInvalidEnvDiskSize"
+}
+
+// GrowEnvDisk increases the size of the root volume of the env
+// then extends its root filesystem over SSH. The filesystem of
+// stopped envs is extended by cloud-init during the next start.
+func (a *AWS) GrowEnvDisk(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+	sizeGb int32,
+) error {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.Instance == nil || envInfra.Instance.RootVolume() == nil {
+		return ErrEnvInstanceNotCreated{}
+	}
+
+	currentSizeGb := envInfra.Instance.RootVolume().SizeGb
+
+	if sizeGb < currentSizeGb || sizeGb < rootVolumeMinSizeGb ||
+		sizeGb > rootVolumeMaxSizeGb {
+
+		return ErrInvalidEnvDiskSize{
+			CurrentSizeGb:   currentSizeGb,
+			RequestedSizeGb: sizeGb,
+			MaxSizeGb:       rootVolumeMaxSizeGb,
+		}
+	}
+
+	ec2Client := a.ec2Client
+	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
+
+	growRootVolume := func(infra *EnvInfrastructure) error {
+		rootVolume := infra.Instance.RootVolume()
+
+		if rootVolume.SizeGb == sizeGb {
+			return nil
+		}
+
+		err := infrastructure.GrowVolume(
+			ctx,
+			ec2Client,
+			a.opts.WaitOpts,
+			rootVolume.ID,
+			sizeGb,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		rootVolume.SizeGb = sizeGb
+		return nil
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Growing the EBS volume")
+				return nil
+			},
+			growRootVolume,
+		},
+	)
+
+	if !envInfra.isInstanceStopped() {
+		// The instance is authenticated with the
+		// SSH host keys retrieved out of band
+		lookupInstanceSSHHostKeys := func(infra *EnvInfrastructure) error {
+			return a.lookupEnvInstanceSSHHostKeys(ctx, infra)
+		}
+
+		envInfraQueue = append(
+			envInfraQueue,
+			queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+				func(*EnvInfrastructure) error {
+					stepper.StartTemporaryStep("Retrieving the SSH host keys of the EC2 instance")
+					return nil
+				},
+				lookupInstanceSSHHostKeys,
+			},
+		)
+
+		extendRootFilesystem := func(infra *EnvInfrastructure) error {
+			publicIPAddress := ""
+
+			if infra.ElasticIP != nil {
+				publicIPAddress = infra.ElasticIP.Address
+			}
+
+			dialer, instanceHost := a.envInstanceDialer(infra, publicIPAddress)
+
+			return infrastructure.ExtendInstanceRootFilesystem(
+				ctx,
+				dialer,
+				a.opts.WaitOpts,
+				instanceHost,
+				fmt.Sprintf("%d", infrastructure.InstanceSSHPort),
+				infra.Instance.SSHHostKeys,
+				infrastructure.InstanceRootUser,
+				infra.KeyPair.PEMContent,
+			)
+		}
+
+		envInfraQueue = append(
+			envInfraQueue,
+			queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+				func(*EnvInfrastructure) error {
+					stepper.StartTemporaryStep("Extending the root filesystem")
+					return nil
+				},
+				extendRootFilesystem,
+			},
+		)
+	}
+
+	err = envInfraQueue.Run(envInfra)
+
+	// Env infra could be updated in the queue even
+	// in case of error (partial infrastructure)
+	env.SetInfrastructureJSON(envInfra)
+
+	return wrapCanceledError(ctx, err)
+}
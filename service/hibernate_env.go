@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/queues"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// ErrInstanceTypeHibernationNotSupported represents the error
+// returned when the instance type cannot hibernate (or has
+// more RAM than infrastructure.HibernationMaxMemorySizeInMiB).
+type ErrInstanceTypeHibernationNotSupported struct {
+	InstanceType    string
+	MemorySizeInMiB int64
+}
+
+func (ErrInstanceTypeHibernationNotSupported) Error() string {
+	return "ErrInstanceTypeHibernationNotSupported"
+}
+
+// ErrAMIHibernationNotSupported represents the error
+// returned when the AMI of the instance cannot hibernate.
+type ErrAMIHibernationNotSupported struct {
+	AMIID string
+}
+
+func (ErrAMIHibernationNotSupported) Error() string {
+	return "ErrAMIHibernationNotSupported"
+}
+
+// ErrEnvHibernationNotEnabled represents the error returned
+// when an env created without the EnableHibernation option
+// is hibernated (hibernation could only be enabled at launch).
+type ErrEnvHibernationNotEnabled struct{}
+
+func (ErrEnvHibernationNotEnabled) Error() string {
+	return "ErrEnvHibernationNotEnabled"
+}
+
+// checkHibernationSupport checks that the instance
+// type and the AMI of the env could hibernate.
+func checkHibernationSupport(envInfra *EnvInfrastructure) error {
+	err := envInfra.InstanceTypeInfos.CheckHibernationSupport()
+
+	if err != nil {
+		return ErrInstanceTypeHibernationNotSupported{
+			InstanceType:    envInfra.InstanceTypeInfos.Type,
+			MemorySizeInMiB: envInfra.InstanceTypeInfos.MemorySizeInMiB,
+		}
+	}
+
+	if !envInfra.InstanceAMI.HibernationSupported {
+		return ErrAMIHibernationNotSupported{
+			AMIID: envInfra.InstanceAMI.ID,
+		}
+	}
+
+	return nil
+}
+
+// HibernateEnv stops the instance of the env after saving
+// the content of its RAM to its root volume. Running processes
+// are resumed when the env is started via StartEnv.
+func (a *AWS) HibernateEnv(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) error {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.Instance == nil {
+		return ErrEnvInstanceNotCreated{}
+	}
+
+	if !envInfra.Instance.HibernationEnabled {
+		return ErrEnvHibernationNotEnabled{}
+	}
+
+	ec2Client := a.ec2Client
+	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
+
+	hibernateInstance := func(infra *EnvInfrastructure) error {
+		if infra.isInstanceStopped() {
+			return nil
+		}
+
+		err := infrastructure.StopInstance(
+			ctx,
+			ec2Client,
+			a.opts.WaitOpts,
+			infra.Instance.ID,
+			true,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.Instance.PowerState = infrastructure.InstancePowerStateHibernated
+		return nil
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Waiting for the EC2 instance to hibernate")
+				return nil
+			},
+			hibernateInstance,
+		},
+	)
+
+	err = envInfraQueue.Run(envInfra)
+
+	// Env infra could be updated in the queue even
+	// in case of error (partial infrastructure)
+	env.SetInfrastructureJSON(envInfra)
+
+	return wrapCanceledError(ctx, err)
+}
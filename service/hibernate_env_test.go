@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	agentConfig "github.com/eleven-sh/agent/config"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestHibernateAndStartEnv(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		EnableHibernation: true,
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if !envInfra.Instance.HibernationEnabled {
+		t.Fatalf("expected hibernation to be enabled, got nothing")
+	}
+
+	// Hibernating a hibernated env does nothing
+	for i := 0; i < 2; i++ {
+		err := AWSService.HibernateEnv(ctx, noopStepper{}, config, cluster, env)
+
+		if err != nil {
+			t.Fatalf("expected no error during env hibernation, got '%+v'", err)
+		}
+	}
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateHibernated)
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts.RunningInstances != 0 ||
+		resourceCounts.HibernatedInstances != 1 {
+
+		t.Fatalf(
+			"expected one hibernated instance, got '%+v'",
+			resourceCounts,
+		)
+	}
+
+	err := AWSService.StartEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env start, got '%+v'", err)
+	}
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+
+	conn, err := fakeAWS.Dialer().DialContext(
+		ctx,
+		"tcp",
+		net.JoinHostPort(
+			env.InstancePublicIPAddress,
+			agentConfig.SSHServerListenPort,
+		),
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error during agent dialing, got '%+v'", err)
+	}
+
+	conn.Close()
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts = fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestCreateEnvWithHibernationAndUnsupportedInstanceType(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+		EnableHibernation: true,
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "c5.12xlarge",
+	}
+
+	err := AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	typedErr, ok := err.(service.ErrInstanceTypeHibernationNotSupported)
+
+	if !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInstanceTypeHibernationNotSupported{},
+			err,
+		)
+	}
+
+	if typedErr.InstanceType != env.InstanceType {
+		t.Fatalf(
+			"expected instance type to equal '%s', got '%s'",
+			env.InstanceType,
+			typedErr.InstanceType,
+		)
+	}
+
+	if fakeAWS.ResourceCounts().RunningInstances != 0 {
+		t.Fatalf("expected no instance to be created, got '%+v'", fakeAWS.ResourceCounts())
+	}
+}
+
+func TestHibernateEnvWithoutHibernationEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	// The instance type cannot hibernate but the
+	// hibernation option is checked first
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "c5.12xlarge",
+	}
+
+	err := AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	err = AWSService.HibernateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if _, ok := err.(service.ErrEnvHibernationNotEnabled); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrEnvHibernationNotEnabled{},
+			err,
+		)
+	}
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+}
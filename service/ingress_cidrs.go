@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// ErrInvalidIngressCIDR represents the error returned
+// when one of the ingress CIDRs passed in AWSOpts
+// (or in the options of a call) is not a valid
+// IPv4 or IPv6 CIDR.
+type ErrInvalidIngressCIDR struct {
+	CIDR string
+}
+
+func (ErrInvalidIngressCIDR) Error() string {
+	return "ErrInvalidIngressCIDR"
+}
+
+// UpdateEnvIngressCIDRs replaces the ingress CIDRs recorded
+// in the env infrastructure with the ones resolved from
+// AWSOpts (like when the public IP of the caller changes).
+//
+// The SSH, agent and opened ports are all updated.
+func (a *AWS) UpdateEnvIngressCIDRs(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) error {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	ingressCIDRs, err := a.resolveIngressCIDRs(ctx, envInfra.isDualStack())
+
+	if err != nil {
+		return wrapCanceledError(ctx, err)
+	}
+
+	stepper.StartTemporaryStep("Updating the security group rules")
+
+	err = infrastructure.UpdateSecurityGroupIngressCIDRs(
+		ctx,
+		a.ec2Client,
+		envInfra.SecurityGroup.ID,
+		envInfra.ingressCIDRs(),
+		ingressCIDRs,
+	)
+
+	if err != nil {
+		return wrapCanceledError(ctx, err)
+	}
+
+	// The rules opened with a TTL were rewritten too
+	envInfra.updatePortExpirationsCIDRs(envInfra.ingressCIDRs(), ingressCIDRs)
+
+	envInfra.IngressCIDRs = ingressCIDRs
+	env.SetInfrastructureJSON(envInfra)
+
+	return nil
+}
+
+// updatePortExpirationsCIDRs replaces the old CIDRs with the new
+// ones in the port expirations that reference any of them, the same
+// way infrastructure.UpdateSecurityGroupIngressCIDRs does for the rules.
+// Otherwise, ClosePort and ReapExpiredPorts would not match them anymore.
+func (e *EnvInfrastructure) updatePortExpirationsCIDRs(
+	oldCIDRs []string,
+	newCIDRs []string,
+) {
+
+	isOldCIDR := map[string]bool{}
+	isNewCIDR := map[string]bool{}
+
+	for _, CIDR := range oldCIDRs {
+		isOldCIDR[CIDR] = true
+	}
+
+	for _, CIDR := range newCIDRs {
+		isNewCIDR[CIDR] = true
+	}
+
+	for i, portExpiration := range e.PortExpirations {
+		if !infrastructure.ReferencesAnyCIDR(portExpiration.PortSpec.CIDRs, oldCIDRs) {
+			continue
+		}
+
+		updatedCIDRs := []string{}
+		hasCIDR := map[string]bool{}
+
+		for _, CIDR := range portExpiration.PortSpec.CIDRs {
+			if isOldCIDR[CIDR] && !isNewCIDR[CIDR] {
+				continue
+			}
+
+			hasCIDR[CIDR] = true
+			updatedCIDRs = append(updatedCIDRs, CIDR)
+		}
+
+		for _, CIDR := range newCIDRs {
+			if !hasCIDR[CIDR] {
+				updatedCIDRs = append(updatedCIDRs, CIDR)
+			}
+		}
+
+		e.PortExpirations[i].PortSpec.CIDRs = updatedCIDRs
+	}
+}
+
+// resolveIngressCIDRs returns the normalized CIDRs passed in
+// AWSOpts, with the public IP of the caller if AutoDetectIngressIP
+// is set. Default to infrastructure.DefaultIngressCIDRs (with
+// infrastructure.DefaultIPv6IngressCIDRs for dual-stack instances).
+func (a *AWS) resolveIngressCIDRs(
+	ctx context.Context,
+	dualStack bool,
+) ([]string, error) {
+
+	ingressCIDRs := []string{}
+	isResolvedCIDR := map[string]bool{}
+
+	addCIDR := func(CIDR string) {
+		if isResolvedCIDR[CIDR] {
+			return
+		}
+
+		isResolvedCIDR[CIDR] = true
+		ingressCIDRs = append(ingressCIDRs, CIDR)
+	}
+
+	normalizedCIDRs, err := normalizeIngressCIDRs(a.opts.IngressCIDRs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, CIDR := range normalizedCIDRs {
+		addCIDR(CIDR)
+	}
+
+	if a.opts.AutoDetectIngressIP {
+		publicIP, err := a.opts.PublicIPResolver.LookupPublicIP(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		publicIPMaskSize := 128
+
+		if publicIP.To4() != nil {
+			publicIPMaskSize = 32
+		}
+
+		addCIDR((&net.IPNet{
+			IP:   publicIP,
+			Mask: net.CIDRMask(publicIPMaskSize, publicIPMaskSize),
+		}).String())
+	}
+
+	if len(ingressCIDRs) == 0 && dualStack {
+		return append(
+			append([]string{}, infrastructure.DefaultIngressCIDRs...),
+			infrastructure.DefaultIPv6IngressCIDRs...,
+		), nil
+	}
+
+	if len(ingressCIDRs) == 0 {
+		return infrastructure.DefaultIngressCIDRs, nil
+	}
+
+	return ingressCIDRs, nil
+}
+
+// normalizeIngressCIDRs validates the passed CIDRs and returns
+// them normalized (like "203.0.113.0/24" for "203.0.113.12/24")
+// to match the rules returned by AWS. Duplicates are removed.
+func normalizeIngressCIDRs(CIDRs []string) ([]string, error) {
+	normalizedCIDRs := []string{}
+	isNormalizedCIDR := map[string]bool{}
+
+	for _, CIDR := range CIDRs {
+		_, IPNet, err := net.ParseCIDR(CIDR)
+
+		if err != nil {
+			return nil, ErrInvalidIngressCIDR{
+				CIDR: CIDR,
+			}
+		}
+
+		normalizedCIDR := IPNet.String()
+
+		if isNormalizedCIDR[normalizedCIDR] {
+			continue
+		}
+
+		isNormalizedCIDR[normalizedCIDR] = true
+		normalizedCIDRs = append(normalizedCIDRs, normalizedCIDR)
+	}
+
+	return normalizedCIDRs, nil
+}
@@ -0,0 +1,349 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+type staticPublicIPResolver struct {
+	IP net.IP
+}
+
+func (s staticPublicIPResolver) LookupPublicIP(context.Context) (net.IP, error) {
+	return s.IP, nil
+}
+
+// lookupCIDRsForPort returns the sorted CIDRs
+// allowed to reach the passed TCP port.
+func lookupCIDRsForPort(
+	t *testing.T,
+	ec2Client *ec2.Client,
+	securityGroupID string,
+	port int32,
+) []string {
+
+	describeSecurityGroupsResp, err := ec2Client.DescribeSecurityGroups(
+		context.Background(),
+		&ec2.DescribeSecurityGroupsInput{
+			GroupIds: []string{securityGroupID},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	CIDRs := []string{}
+
+	for _, IPPermission := range describeSecurityGroupsResp.SecurityGroups[0].IpPermissions {
+		if aws.ToInt32(IPPermission.FromPort) != port {
+			continue
+		}
+
+		for _, IPRange := range IPPermission.IpRanges {
+			CIDRs = append(CIDRs, aws.ToString(IPRange.CidrIp))
+		}
+
+		for _, IPv6Range := range IPPermission.Ipv6Ranges {
+			CIDRs = append(CIDRs, aws.ToString(IPv6Range.CidrIpv6))
+		}
+	}
+
+	sort.Strings(CIDRs)
+	return CIDRs
+}
+
+func TestEnvIngressCIDRs(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		IngressCIDRs:        []string{"203.0.113.12/24", "2001:db8::/32"},
+		AutoDetectIngressIP: true,
+		PublicIPResolver: staticPublicIPResolver{
+			IP: net.ParseIP("198.51.100.7"),
+		},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	expectedCIDRs := []string{"203.0.113.0/24", "2001:db8::/32", "198.51.100.7/32"}
+
+	if !reflect.DeepEqual(envInfra.IngressCIDRs, expectedCIDRs) {
+		t.Fatalf(
+			"expected ingress CIDRs to equal '%+v', got '%+v'",
+			expectedCIDRs,
+			envInfra.IngressCIDRs,
+		)
+	}
+
+	ec2Client := ec2.NewFromConfig(fakeAWS.Config())
+	securityGroupID := envInfra.SecurityGroup.ID
+	sortedExpectedCIDRs := []string{"198.51.100.7/32", "2001:db8::/32", "203.0.113.0/24"}
+
+	SSHPortCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, 22)
+
+	if !reflect.DeepEqual(SSHPortCIDRs, sortedExpectedCIDRs) {
+		t.Fatalf(
+			"expected SSH port CIDRs to equal '%+v', got '%+v'",
+			sortedExpectedCIDRs,
+			SSHPortCIDRs,
+		)
+	}
+
+	err := AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "8080")
+
+	if err != nil {
+		t.Fatalf("expected no error during port opening, got '%+v'", err)
+	}
+
+	openedPortCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, 8080)
+
+	if !reflect.DeepEqual(openedPortCIDRs, sortedExpectedCIDRs) {
+		t.Fatalf(
+			"expected opened port CIDRs to equal '%+v', got '%+v'",
+			sortedExpectedCIDRs,
+			openedPortCIDRs,
+		)
+	}
+
+	// The public IP of the caller changes
+	updatedAWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer:      fakeAWS.Dialer(),
+		IngressCIDRs:        []string{"2001:db8::/32"},
+		AutoDetectIngressIP: true,
+		PublicIPResolver: staticPublicIPResolver{
+			IP: net.ParseIP("192.0.2.44"),
+		},
+	})
+
+	err = updatedAWSService.UpdateEnvIngressCIDRs(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during ingress CIDRs update, got '%+v'", err)
+	}
+
+	sortedExpectedCIDRs = []string{"192.0.2.44/32", "2001:db8::/32"}
+
+	for _, port := range []int32{22, 8080} {
+		portCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, port)
+
+		if !reflect.DeepEqual(portCIDRs, sortedExpectedCIDRs) {
+			t.Fatalf(
+				"expected port %d CIDRs to equal '%+v', got '%+v'",
+				port,
+				sortedExpectedCIDRs,
+				portCIDRs,
+			)
+		}
+	}
+
+	err = updatedAWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "8080")
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	openedPortCIDRs = lookupCIDRsForPort(t, ec2Client, securityGroupID, 8080)
+
+	if len(openedPortCIDRs) > 0 {
+		t.Fatalf("expected closed port to have no CIDRs, got '%+v'", openedPortCIDRs)
+	}
+
+	err = updatedAWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = updatedAWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+}
+
+func TestCreateEnvWithInvalidIngressCIDR(t *testing.T) {
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		IngressCIDRs: []string{"203.0.113.0/24", "203.0.113.0"},
+	})
+
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+		// Env creation must fail before
+		// cluster resources are used
+		InfrastructureJSON: "{}",
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err := AWSService.CreateEnv(context.Background(), noopStepper{}, &entities.Config{}, cluster, env)
+
+	expectedError := service.ErrInvalidIngressCIDR{
+		CIDR: "203.0.113.0",
+	}
+
+	var invalidCIDRErr service.ErrInvalidIngressCIDR
+	if !errors.As(err, &invalidCIDRErr) || invalidCIDRErr != expectedError {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			expectedError,
+			err,
+		)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no created resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestPerCallIngressCIDRs(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+		IngressCIDRs: []string{"203.0.113.0/24"},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err := AWSService.CreateEnvWithOpts(ctx, noopStepper{}, config, cluster, env, service.CreateEnvOpts{
+		IngressCIDRs: []string{"198.51.100.12/24"},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	// The per-call CIDRs replace the service ones
+	expectedCIDRs := []string{"198.51.100.0/24"}
+
+	if !reflect.DeepEqual(envInfra.IngressCIDRs, expectedCIDRs) {
+		t.Fatalf(
+			"expected ingress CIDRs to equal '%+v', got '%+v'",
+			expectedCIDRs,
+			envInfra.IngressCIDRs,
+		)
+	}
+
+	ec2Client := ec2.NewFromConfig(fakeAWS.Config())
+	securityGroupID := envInfra.SecurityGroup.ID
+
+	SSHPortCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, 22)
+
+	if !reflect.DeepEqual(SSHPortCIDRs, expectedCIDRs) {
+		t.Fatalf(
+			"expected SSH port CIDRs to equal '%+v', got '%+v'",
+			expectedCIDRs,
+			SSHPortCIDRs,
+		)
+	}
+
+	err = AWSService.OpenPortWithOpts(ctx, noopStepper{}, config, cluster, env, "8080", service.OpenPortOpts{
+		CIDRs: []string{"192.0.2.12/24", "2001:db8::/32"},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error during port opening, got '%+v'", err)
+	}
+
+	// The per-call CIDRs replace the env ones
+	expectedCIDRs = []string{"192.0.2.0/24", "2001:db8::/32"}
+	openedPortCIDRs := lookupCIDRsForPort(t, ec2Client, securityGroupID, 8080)
+
+	if !reflect.DeepEqual(openedPortCIDRs, expectedCIDRs) {
+		t.Fatalf(
+			"expected opened port CIDRs to equal '%+v', got '%+v'",
+			expectedCIDRs,
+			openedPortCIDRs,
+		)
+	}
+
+	// The env CIDRs were not allowed
+	err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "8080")
+
+	var portRuleNotFoundErr service.ErrPortRuleNotFound
+	if !errors.As(err, &portRuleNotFoundErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrPortRuleNotFound{PortSpec: "8080"},
+			err,
+		)
+	}
+
+	err = AWSService.ClosePortWithOpts(ctx, noopStepper{}, config, cluster, env, "8080", service.ClosePortOpts{
+		CIDRs: []string{"192.0.2.0/24"},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	expectedCIDRs = []string{"2001:db8::/32"}
+	openedPortCIDRs = lookupCIDRsForPort(t, ec2Client, securityGroupID, 8080)
+
+	if !reflect.DeepEqual(openedPortCIDRs, expectedCIDRs) {
+		t.Fatalf(
+			"expected opened port CIDRs to equal '%+v', got '%+v'",
+			expectedCIDRs,
+			openedPortCIDRs,
+		)
+	}
+
+	err = AWSService.OpenPortWithOpts(ctx, noopStepper{}, config, cluster, env, "9090@10.0.0.0/8", service.OpenPortOpts{
+		CIDRs: []string{"192.0.2.0/24"},
+	})
+
+	var invalidPortSpecErr service.ErrInvalidPortSpec
+	if !errors.As(err, &invalidPortSpecErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInvalidPortSpec{PortSpec: "9090@10.0.0.0/8"},
+			err,
+		)
+	}
+
+	err = AWSService.ClosePortWithOpts(ctx, noopStepper{}, config, cluster, env, "8080", service.ClosePortOpts{
+		CIDRs: []string{"2001:db8::"},
+	})
+
+	expectedError := service.ErrInvalidIngressCIDR{
+		CIDR: "2001:db8::",
+	}
+
+	var invalidCIDRErr service.ErrInvalidIngressCIDR
+	if !errors.As(err, &invalidCIDRErr) || invalidCIDRErr != expectedError {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			expectedError,
+			err,
+		)
+	}
+}
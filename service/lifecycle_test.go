@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestEnvLifecycleAgainstFakeAWS(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: fakeAWS.Dialer(),
+	})
+
+	_, err := AWSService.LookupElevenConfig(ctx, noopStepper{})
+
+	if !errors.Is(err, entities.ErrElevenNotInstalled) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			entities.ErrElevenNotInstalled,
+			err,
+		)
+	}
+
+	err = AWSService.CreateElevenConfigStorage(ctx, noopStepper{})
+
+	if err != nil {
+		t.Fatalf("expected no error during config storage creation, got '%+v'", err)
+	}
+
+	// Config storage creation must be idempotent
+	err = AWSService.CreateElevenConfigStorage(ctx, noopStepper{})
+
+	if err != nil {
+		t.Fatalf("expected no error during config storage re-creation, got '%+v'", err)
+	}
+
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	config := &entities.Config{
+		ID: "eleven-config",
+		Clusters: map[string]*entities.Cluster{
+			cluster.Name: cluster,
+		},
+	}
+
+	err = AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-env",
+		InstanceType: "t2.medium",
+	}
+
+	fakeAWS.FailNext("RunInstances", "InsufficientInstanceCapacity")
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err == nil || !strings.Contains(err.Error(), "InsufficientInstanceCapacity") {
+		t.Fatalf(
+			"expected error code to equal 'InsufficientInstanceCapacity', got '%+v'",
+			err,
+		)
+	}
+
+	var partialEnvInfra *service.EnvInfrastructure
+	err = json.Unmarshal([]byte(env.InfrastructureJSON), &partialEnvInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if partialEnvInfra.NetworkInterface == nil || partialEnvInfra.Instance != nil {
+		t.Fatalf(
+			"expected network interface to be recorded without instance, got '%+v'",
+			partialEnvInfra,
+		)
+	}
+
+	// Resume env creation from partial infrastructure
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if envInfra.NetworkInterface.ID != partialEnvInfra.NetworkInterface.ID {
+		t.Fatalf(
+			"expected network interface '%s' to be reused, got '%s'",
+			partialEnvInfra.NetworkInterface.ID,
+			envInfra.NetworkInterface.ID,
+		)
+	}
+
+	if env.InstancePublicIPAddress != envInfra.ElasticIP.Address {
+		t.Fatalf(
+			"expected instance public IP address to equal '%s', got '%s'",
+			envInfra.ElasticIP.Address,
+			env.InstancePublicIPAddress,
+		)
+	}
+
+	cluster.Envs = map[string]*entities.Env{
+		env.Name: env,
+	}
+
+	err = AWSService.SaveElevenConfig(ctx, noopStepper{}, config)
+
+	if err != nil {
+		t.Fatalf("expected no error during config saving, got '%+v'", err)
+	}
+
+	savedConfig, err := AWSService.LookupElevenConfig(ctx, noopStepper{})
+
+	if err != nil {
+		t.Fatalf("expected no error during config lookup, got '%+v'", err)
+	}
+
+	if savedConfig.ID != config.ID {
+		t.Fatalf(
+			"expected config ID to equal '%s', got '%s'",
+			config.ID,
+			savedConfig.ID,
+		)
+	}
+
+	err = AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "8080")
+
+	if err != nil {
+		t.Fatalf("expected no error during port opening, got '%+v'", err)
+	}
+
+	err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "8080")
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveElevenConfigStorage(ctx, noopStepper{})
+
+	if err != nil {
+		t.Fatalf("expected no error during config storage removal, got '%+v'", err)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
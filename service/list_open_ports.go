@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	agentConfig "github.com/eleven-sh/agent/config"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// elevenManagedPortDescription is set on the ingress rules
+// added during env creation (SSH and agent ports) to
+// distinguish them from the rules added via OpenPort.
+const elevenManagedPortDescription = "eleven-managed"
+
+// ErrManagedPort represents the error returned when
+// ClosePort is called for a rule added by Eleven
+// during env creation (SSH or agent ports).
+type ErrManagedPort struct {
+	PortSpec string
+}
+
+func (ErrManagedPort) Error() string {
+	return "ErrManagedPort"
+}
+
+// PortRule represents an ingress rule
+// of the security group of an env.
+type PortRule struct {
+	infrastructure.PortSpec
+
+	// ElevenManaged is set for the rules added during
+	// env creation (SSH and agent ports). Other rules
+	// were added via OpenPort.
+	ElevenManaged bool `json:"eleven_managed"`
+
+	// ExpiresAt is set for the rules added
+	// via OpenPortWithOpts with a TTL.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ListOpenPorts returns the ingress rules of the
+// security group of the env, sorted by protocol and ports.
+func (a *AWS) ListOpenPorts(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) ([]PortRule, error) {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ingressRules, err := infrastructure.LookupSecurityGroupIngressRules(
+		ctx,
+		a.ec2Client,
+		envInfra.SecurityGroup.ID,
+	)
+
+	if err != nil {
+		return nil, wrapCanceledError(ctx, err)
+	}
+
+	portRules := make([]PortRule, len(ingressRules))
+
+	for ingressRuleIndex, ingressRule := range ingressRules {
+		portRules[ingressRuleIndex] = PortRule{
+			PortSpec:      ingressRule,
+			ElevenManaged: envInfra.isElevenManagedPortRule(ingressRule),
+		}
+
+		if expiresAt, ok := parsePortExpirationDescription(ingressRule.Description); ok {
+			portRules[ingressRuleIndex].ExpiresAt = &expiresAt
+		}
+	}
+
+	return portRules, nil
+}
+
+// elevenManagedPorts returns the TCP ports opened during env
+// creation: the SSH port of the instance and the agent ports.
+func elevenManagedPorts() []int32 {
+	managedPorts := []int32{
+		infrastructure.InstanceSSHPort,
+	}
+
+	for _, agentPort := range []string{
+		agentConfig.SSHServerListenPort,
+		agentConfig.HTTPServerListenPort,
+		agentConfig.HTTPSServerListenPort,
+	} {
+		parsedAgentPort, _ := strconv.ParseInt(agentPort, 10, 32)
+		managedPorts = append(managedPorts, int32(parsedAgentPort))
+	}
+
+	return managedPorts
+}
+
+// isElevenManagedPort returns true if the port spec exactly
+// matches one of the ports returned by elevenManagedPorts.
+func isElevenManagedPort(portSpec infrastructure.PortSpec) bool {
+	if portSpec.Protocol != infrastructure.PortSpecProtocolTCP ||
+		portSpec.FromPort != portSpec.ToPort {
+
+		return false
+	}
+
+	for _, managedPort := range elevenManagedPorts() {
+		if managedPort == portSpec.FromPort {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isElevenManagedPortRule returns true if the ingress
+// rule was added during env creation.
+func (e *EnvInfrastructure) isElevenManagedPortRule(ingressRule infrastructure.PortSpec) bool {
+	// Private instances are reached via SSM
+	// (no ingress rule is added)
+	if e.IsPrivate {
+		return false
+	}
+
+	if ingressRule.Description == elevenManagedPortDescription {
+		return true
+	}
+
+	// The rules added before the description was set are
+	// the managed ports opened for the ingress CIDRs
+	return len(ingressRule.Description) == 0 &&
+		isElevenManagedPort(ingressRule) &&
+		infrastructure.ReferencesAnyCIDR(ingressRule.CIDRs, e.ingressCIDRs())
+}
@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+)
+
+func TestListOpenPorts(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		IngressCIDRs: []string{"203.0.113.0/24"},
+	})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	// The ranges that include the managed
+	// ports are not managed by Eleven
+	for _, portToOpen := range []string{"8080", "3000-3010/udp@2001:db8::/32", "20-30", "1-65535@198.51.100.0/24"} {
+		err := AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, portToOpen)
+
+		if err != nil {
+			t.Fatalf("expected no error during port opening, got '%+v'", err)
+		}
+	}
+
+	openPorts, err := AWSService.ListOpenPorts(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during open ports listing, got '%+v'", err)
+	}
+
+	ingressCIDRs := []string{"203.0.113.0/24"}
+	expectedOpenPorts := []service.PortRule{
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 1, ToPort: 65535, CIDRs: []string{"198.51.100.0/24"}}, ElevenManaged: false},
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 20, ToPort: 30, CIDRs: ingressCIDRs}, ElevenManaged: false},
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 22, ToPort: 22, CIDRs: ingressCIDRs, Description: "eleven-managed"}, ElevenManaged: true},
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 80, ToPort: 80, CIDRs: ingressCIDRs, Description: "eleven-managed"}, ElevenManaged: true},
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 443, ToPort: 443, CIDRs: ingressCIDRs, Description: "eleven-managed"}, ElevenManaged: true},
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 2200, ToPort: 2200, CIDRs: ingressCIDRs, Description: "eleven-managed"}, ElevenManaged: true},
+		{PortSpec: infrastructure.PortSpec{Protocol: "tcp", FromPort: 8080, ToPort: 8080, CIDRs: ingressCIDRs}, ElevenManaged: false},
+		{PortSpec: infrastructure.PortSpec{Protocol: "udp", FromPort: 3000, ToPort: 3010, CIDRs: []string{"2001:db8::/32"}}, ElevenManaged: false},
+	}
+
+	if !reflect.DeepEqual(openPorts, expectedOpenPorts) {
+		t.Fatalf("expected open ports to equal '%+v', got '%+v'", expectedOpenPorts, openPorts)
+	}
+
+	err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "22")
+
+	var managedPortErr service.ErrManagedPort
+	if !errors.As(err, &managedPortErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrManagedPort{PortSpec: "22"},
+			err,
+		)
+	}
+
+	for _, portToClose := range []string{"20-30", "1-65535@198.51.100.0/24"} {
+		err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, portToClose)
+
+		if err != nil {
+			t.Fatalf("expected no error during port closing, got '%+v'", err)
+		}
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+}
+
+func TestClosePortAgreesWithListOpenPorts(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		IngressCIDRs: []string{"203.0.113.0/24", "2001:db8::/32"},
+	})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	// User rules on the managed ports but for other CIDRs
+	for _, portToOpen := range []string{"22@198.51.100.0/24", "443/tcp@192.0.2.0/24", "8080"} {
+		err := AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, portToOpen)
+
+		if err != nil {
+			t.Fatalf("expected no error during port opening, got '%+v'", err)
+		}
+	}
+
+	openPorts, err := AWSService.ListOpenPorts(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during open ports listing, got '%+v'", err)
+	}
+
+	// Only the rules listed as managed are refused
+	for _, openPort := range openPorts {
+		for _, CIDR := range openPort.CIDRs {
+			portToClose := fmt.Sprintf(
+				"%d-%d/%s@%s",
+				openPort.FromPort,
+				openPort.ToPort,
+				openPort.Protocol,
+				CIDR,
+			)
+
+			err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, portToClose)
+
+			var managedPortErr service.ErrManagedPort
+			isManagedPortErr := errors.As(err, &managedPortErr)
+
+			if openPort.ElevenManaged && !isManagedPortErr {
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					service.ErrManagedPort{PortSpec: portToClose},
+					err,
+				)
+			}
+
+			if !openPort.ElevenManaged && err != nil {
+				t.Fatalf("expected no error during port closing of '%s', got '%+v'", portToClose, err)
+			}
+		}
+	}
+}
@@ -1,34 +1,162 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/entities"
 	"github.com/eleven-sh/eleven/stepper"
 )
 
+// ErrInvalidPortTTL represents the error
+// returned when a negative TTL is passed to OpenPortWithOpts.
+type ErrInvalidPortTTL struct {
+	TTL time.Duration
+}
+
+func (ErrInvalidPortTTL) Error() string {
+	return "ErrInvalidPortTTL"
+}
+
+// OpenPortOpts represents the options
+// used to configure OpenPortWithOpts.
+type OpenPortOpts struct {
+	// TTL specifies the duration after which the port
+	// is closed by ReapExpiredPorts.
+	// The port never expires if not set.
+	TTL time.Duration
+
+	// CIDRs specifies the IPv4 and IPv6 CIDRs allowed to reach
+	// the port. Default to the ingress CIDRs of the env if not set.
+	// Cannot be combined with the source CIDR of the port spec.
+	CIDRs []string
+}
+
+// OpenPort allows the traffic described by the passed port spec
+// (see ParsePortSpec) from the ingress CIDRs of the env
+// or from the source CIDR of the spec if set.
 func (a *AWS) OpenPort(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+	portToOpen string,
+) error {
+
+	return a.OpenPortWithOpts(
+		ctx,
+		stepper,
+		config,
+		cluster,
+		env,
+		portToOpen,
+		OpenPortOpts{},
+	)
+}
+
+// OpenPortWithOpts works like OpenPort. When a TTL is passed,
+// the expiration is recorded in the description of the
+// security group rule and in the env infrastructure.
+func (a *AWS) OpenPortWithOpts(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 	cluster *entities.Cluster,
 	env *entities.Env,
 	portToOpen string,
+	opts OpenPortOpts,
 ) error {
 
+	portSpec, err := ParsePortSpec(portToOpen)
+
+	if err != nil {
+		return err
+	}
+
+	if opts.TTL < 0 {
+		return ErrInvalidPortTTL{
+			TTL: opts.TTL,
+		}
+	}
+
 	var envInfra *EnvInfrastructure
-	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+	err = json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	portSpec.CIDRs, err = resolvePortCIDRs(envInfra, portToOpen, portSpec, opts.CIDRs)
 
 	if err != nil {
 		return err
 	}
 
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	var portExpiration *PortExpiration
+
+	if opts.TTL > 0 {
+		portExpiration = &PortExpiration{
+			ExpiresAt: time.Now().Add(opts.TTL).UTC(),
+		}
+
+		portSpec.Description = buildPortExpirationDescription(portExpiration.ExpiresAt)
+		portExpiration.PortSpec = portSpec
+	}
 
-	return infrastructure.OpenInstancePort(
+	ec2Client := a.ec2Client
+
+	err = infrastructure.OpenInstancePort(
+		ctx,
 		ec2Client,
 		envInfra.SecurityGroup.ID,
-		portToOpen,
+		portSpec,
 	)
+
+	if errors.Is(err, infrastructure.ErrDuplicateIngressRule) {
+		return ErrDuplicatePortRule{
+			PortSpec: portToOpen,
+		}
+	}
+
+	if err != nil {
+		return wrapCanceledError(ctx, err)
+	}
+
+	if portExpiration != nil {
+		envInfra.PortExpirations = append(envInfra.PortExpirations, *portExpiration)
+		env.SetInfrastructureJSON(envInfra)
+	}
+
+	return nil
+}
+
+// resolvePortCIDRs returns the CIDRs of the passed port spec: its
+// source CIDR, the (normalized) CIDRs passed in the options of the
+// call or the ingress CIDRs of the env, in that order.
+func resolvePortCIDRs(
+	envInfra *EnvInfrastructure,
+	portSpecAsString string,
+	portSpec infrastructure.PortSpec,
+	CIDRs []string,
+) ([]string, error) {
+
+	if len(portSpec.CIDRs) > 0 && len(CIDRs) > 0 {
+		return nil, ErrInvalidPortSpec{
+			PortSpec: portSpecAsString,
+		}
+	}
+
+	if len(portSpec.CIDRs) > 0 {
+		return portSpec.CIDRs, nil
+	}
+
+	if len(CIDRs) > 0 {
+		return normalizeIngressCIDRs(CIDRs)
+	}
+
+	return envInfra.ingressCIDRs(), nil
 }
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+)
+
+// ErrInvalidPortSpec represents the error returned
+// when the port passed to OpenPort or ClosePort
+// cannot be parsed by ParsePortSpec.
+type ErrInvalidPortSpec struct {
+	PortSpec string
+}
+
+func (ErrInvalidPortSpec) Error() string {
+	return "ErrInvalidPortSpec"
+}
+
+// ErrDuplicatePortRule represents the error returned
+// when OpenPort is called for a port that is already open.
+type ErrDuplicatePortRule struct {
+	PortSpec string
+}
+
+func (ErrDuplicatePortRule) Error() string {
+	return "ErrDuplicatePortRule"
+}
+
+// ErrPortRuleNotFound represents the error returned
+// when ClosePort is called for a port that is not open.
+type ErrPortRuleNotFound struct {
+	PortSpec string
+}
+
+func (ErrPortRuleNotFound) Error() string {
+	return "ErrPortRuleNotFound"
+}
+
+// ParsePortSpec parses a port spec formatted as
+// "<port>[-<port>][/<protocol>][@<cidr>]" (like "8080",
+// "3000-3010/udp" or "5432/tcp@203.0.113.0/24").
+//
+// The protocol defaults to TCP. The CIDRs are
+// only set when a source CIDR is passed.
+func ParsePortSpec(portSpec string) (infrastructure.PortSpec, error) {
+	invalidPortSpecErr := ErrInvalidPortSpec{
+		PortSpec: portSpec,
+	}
+
+	parsedPortSpec := infrastructure.PortSpec{
+		Protocol: infrastructure.PortSpecProtocolTCP,
+	}
+
+	portsAndProtocol, CIDR, hasCIDR := strings.Cut(portSpec, "@")
+
+	if hasCIDR {
+		_, IPNet, err := net.ParseCIDR(CIDR)
+
+		if err != nil {
+			return infrastructure.PortSpec{}, invalidPortSpecErr
+		}
+
+		parsedPortSpec.CIDRs = []string{IPNet.String()}
+	}
+
+	ports, protocol, hasProtocol := strings.Cut(portsAndProtocol, "/")
+
+	if hasProtocol {
+		protocol = strings.ToLower(protocol)
+
+		if protocol != infrastructure.PortSpecProtocolTCP &&
+			protocol != infrastructure.PortSpecProtocolUDP {
+
+			return infrastructure.PortSpec{}, invalidPortSpecErr
+		}
+
+		parsedPortSpec.Protocol = protocol
+	}
+
+	fromPort, toPort, isRange := strings.Cut(ports, "-")
+
+	if !isRange {
+		toPort = fromPort
+	}
+
+	parsedFromPort, err := parsePortNumber(fromPort)
+
+	if err != nil {
+		return infrastructure.PortSpec{}, invalidPortSpecErr
+	}
+
+	parsedToPort, err := parsePortNumber(toPort)
+
+	if err != nil || parsedFromPort > parsedToPort {
+		return infrastructure.PortSpec{}, invalidPortSpecErr
+	}
+
+	parsedPortSpec.FromPort = parsedFromPort
+	parsedPortSpec.ToPort = parsedToPort
+
+	return parsedPortSpec, nil
+}
+
+func parsePortNumber(port string) (int32, error) {
+	parsedPort, err := strconv.ParseInt(port, 10, 32)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if parsedPort < 1 || parsedPort > 65535 {
+		return 0, strconv.ErrRange
+	}
+
+	return int32(parsedPort), nil
+}
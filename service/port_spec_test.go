@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	testCases := []struct {
+		test             string
+		portSpec         string
+		expectedPortSpec infrastructure.PortSpec
+		expectedError    error
+	}{
+		{
+			test:     "with single port",
+			portSpec: "8080",
+			expectedPortSpec: infrastructure.PortSpec{
+				Protocol: "tcp",
+				FromPort: 8080,
+				ToPort:   8080,
+			},
+		},
+
+		{
+			test:     "with UDP port range",
+			portSpec: "3000-3010/udp",
+			expectedPortSpec: infrastructure.PortSpec{
+				Protocol: "udp",
+				FromPort: 3000,
+				ToPort:   3010,
+			},
+		},
+
+		{
+			test:     "with source CIDR",
+			portSpec: "5432/TCP@203.0.113.12/24",
+			expectedPortSpec: infrastructure.PortSpec{
+				Protocol: "tcp",
+				FromPort: 5432,
+				ToPort:   5432,
+				CIDRs:    []string{"203.0.113.0/24"},
+			},
+		},
+
+		{
+			test:     "with IPv6 source CIDR",
+			portSpec: "443@2001:db8::/32",
+			expectedPortSpec: infrastructure.PortSpec{
+				Protocol: "tcp",
+				FromPort: 443,
+				ToPort:   443,
+				CIDRs:    []string{"2001:db8::/32"},
+			},
+		},
+
+		{
+			test:          "with invalid port",
+			portSpec:      "http",
+			expectedError: service.ErrInvalidPortSpec{PortSpec: "http"},
+		},
+
+		{
+			test:          "with out of range port",
+			portSpec:      "65536",
+			expectedError: service.ErrInvalidPortSpec{PortSpec: "65536"},
+		},
+
+		{
+			test:          "with reversed port range",
+			portSpec:      "3010-3000",
+			expectedError: service.ErrInvalidPortSpec{PortSpec: "3010-3000"},
+		},
+
+		{
+			test:          "with unsupported protocol",
+			portSpec:      "8080/icmp",
+			expectedError: service.ErrInvalidPortSpec{PortSpec: "8080/icmp"},
+		},
+
+		{
+			test:          "with invalid source CIDR",
+			portSpec:      "8080@203.0.113.12",
+			expectedError: service.ErrInvalidPortSpec{PortSpec: "8080@203.0.113.12"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			portSpec, err := service.ParsePortSpec(tc.portSpec)
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil && err != tc.expectedError {
+				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
+			}
+
+			if !reflect.DeepEqual(portSpec, tc.expectedPortSpec) {
+				t.Fatalf("expected port spec to equal '%+v', got '%+v'", tc.expectedPortSpec, portSpec)
+			}
+		})
+	}
+}
+
+func TestOpenAndClosePortWithPortSpecs(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	err := AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "3000-3010/udp")
+
+	if err != nil {
+		t.Fatalf("expected no error during port opening, got '%+v'", err)
+	}
+
+	err = AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "3000-3010/udp")
+
+	var duplicateRuleErr service.ErrDuplicatePortRule
+	if !errors.As(err, &duplicateRuleErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrDuplicatePortRule{PortSpec: "3000-3010/udp"},
+			err,
+		)
+	}
+
+	err = AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "3000-3010")
+
+	if err != nil {
+		t.Fatalf("expected no error during TCP port opening, got '%+v'", err)
+	}
+
+	err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "3000-3010/udp")
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "3000-3010/udp")
+
+	var ruleNotFoundErr service.ErrPortRuleNotFound
+	if !errors.As(err, &ruleNotFoundErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrPortRuleNotFound{PortSpec: "3000-3010/udp"},
+			err,
+		)
+	}
+
+	err = AWSService.OpenPort(ctx, noopStepper{}, config, cluster, env, "80-")
+
+	var invalidPortSpecErr service.ErrInvalidPortSpec
+	if !errors.As(err, &invalidPortSpecErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInvalidPortSpec{PortSpec: "80-"},
+			err,
+		)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"encoding/json"
+	"net"
+
+	agentConfig "github.com/eleven-sh/agent/config"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+// PrivateEgressNATGateway represents the private networking mode
+// where the private subnets reach the internet (and the SSM endpoints)
+// through a NAT gateway created in the first public subnet.
+//
+// It is the only mode supported: the instance init script needs
+// internet access (to install packages and to download the agent).
+const PrivateEgressNATGateway = "nat_gateway"
+
+// PrivateNetworkingOpts represents the options used to create
+// sandboxes without public IP (reached via SSM Session Manager).
+//
+// With the default port forwarder, the AWS CLI and the Session Manager
+// plugin ("session-manager-plugin") must be installed locally.
+type PrivateNetworkingOpts struct {
+	// Enabled specifies if the sandboxes are created in private subnets.
+	// Only applies to the clusters and envs created after the option was set.
+	Enabled bool
+
+	// Egress specifies how the private subnets reach the internet
+	// and the SSM endpoints. Only PrivateEgressNATGateway is supported.
+	// Default to PrivateEgressNATGateway if not set.
+	Egress string
+
+	// InstanceProfileName specifies the IAM instance profile attached
+	// to the instances. Its role must allow SSM (like with the
+	// "AmazonSSMManagedInstanceCore" managed policy).
+	InstanceProfileName string
+
+	// PortForwarder specifies the port forwarder used to reach the instances.
+	// Default to infrastructure.SessionManagerPortForwarder if not set.
+	PortForwarder infrastructure.InstancePortForwarder
+}
+
+// ErrInvalidPrivateEgress represents the error returned
+// when the private networking egress mode is unknown.
+type ErrInvalidPrivateEgress struct {
+	Egress string
+}
+
+func (ErrInvalidPrivateEgress) Error() string {
+	return "ErrInvalidPrivateEgress"
+}
+
+// ErrMissingInstanceProfile represents the error returned
+// when private networking is enabled without instance profile.
+type ErrMissingInstanceProfile struct{}
+
+func (ErrMissingInstanceProfile) Error() string {
+	return "ErrMissingInstanceProfile"
+}
+
+// ErrEnvInstanceNotCreated represents the error returned
+// when the instance of an env is reached (or stopped /
+// started) before being created.
+type ErrEnvInstanceNotCreated struct{}
+
+func (ErrEnvInstanceNotCreated) Error() string {
+	return "ErrEnvInstanceNotCreated"
+}
+
+// resolvePrivateEgress validates the private networking
+// egress mode passed in AWSOpts.
+func (a *AWS) resolvePrivateEgress() (string, error) {
+	egress := a.opts.PrivateNetworking.Egress
+
+	if len(egress) == 0 {
+		return PrivateEgressNATGateway, nil
+	}
+
+	if egress != PrivateEgressNATGateway {
+		return "", ErrInvalidPrivateEgress{
+			Egress: egress,
+		}
+	}
+
+	return egress, nil
+}
+
+// envInstanceDialer returns the dialer and the host
+// used to reach the instance of the passed env.
+// Private instances are reached by ID via SSM.
+func (a *AWS) envInstanceDialer(
+	envInfra *EnvInfrastructure,
+	publicIPAddress string,
+) (infrastructure.Dialer, string) {
+
+	if envInfra.IsPrivate {
+		return infrastructure.PortForwardingDialer{
+			PortForwarder: a.opts.PrivateNetworking.PortForwarder,
+		}, envInfra.Instance.ID
+	}
+
+	return a.opts.InstanceDialer, publicIPAddress
+}
+
+// EnvAgentDialer returns the dialer and the address used to
+// reach the agent SSH server of the passed env. Private envs
+// have no public IP and are reached via SSM Session Manager.
+func (a *AWS) EnvAgentDialer(env *entities.Env) (infrastructure.Dialer, string, error) {
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if envInfra.IsPrivate && envInfra.Instance == nil {
+		return nil, "", ErrEnvInstanceNotCreated{}
+	}
+
+	dialer, instanceHost := a.envInstanceDialer(
+		envInfra,
+		env.InstancePublicIPAddress,
+	)
+
+	return dialer, net.JoinHostPort(
+		instanceHost,
+		agentConfig.SSHServerListenPort,
+	), nil
+}
@@ -0,0 +1,208 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestPrivateClusterAndEnv(t *testing.T) {
+	testCases := []struct {
+		test                string
+		egress              string
+		expectedNATGateways int
+	}{
+		{
+			test:                "with NAT gateway",
+			egress:              service.PrivateEgressNATGateway,
+			expectedNATGateways: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+				PrivateNetworking: service.PrivateNetworkingOpts{
+					Enabled:             true,
+					Egress:              tc.egress,
+					InstanceProfileName: "eleven-ssm-instance-profile",
+				},
+			})
+
+			fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+			config, cluster := fakeEnv.config, fakeEnv.cluster
+
+			var clusterInfra *service.ClusterInfrastructure
+			err := json.Unmarshal([]byte(cluster.InfrastructureJSON), &clusterInfra)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if len(clusterInfra.PrivateSubnets) != 1 ||
+				clusterInfra.PrivateSubnets[0].CIDRBlock != "10.0.1.0/24" ||
+				!clusterInfra.PrivateSubnets[0].IsAssociatedToRouteTable {
+
+				t.Fatalf(
+					"expected one associated private subnet '10.0.1.0/24', got '%+v'",
+					clusterInfra.PrivateSubnets,
+				)
+			}
+
+			resourceCounts := fakeAWS.ResourceCounts()
+
+			if resourceCounts.NATGateways != tc.expectedNATGateways {
+				t.Fatalf(
+					"expected %d NAT gateway(s), got '%+v'",
+					tc.expectedNATGateways,
+					resourceCounts,
+				)
+			}
+
+			env := &entities.Env{
+				Name:         "eleven-api",
+				InstanceType: "t2.medium",
+			}
+
+			err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if err != nil {
+				t.Fatalf("expected no error during env creation, got '%+v'", err)
+			}
+
+			envInfra := unmarshalEnvInfra(t, env)
+
+			if !envInfra.IsPrivate ||
+				envInfra.ElasticIP != nil ||
+				envInfra.Subnet.ID != clusterInfra.PrivateSubnets[0].ID {
+
+				t.Fatalf(
+					"expected env to be private without elastic IP, got '%+v'",
+					envInfra,
+				)
+			}
+
+			if len(env.InstancePublicIPAddress) > 0 {
+				t.Fatalf(
+					"expected no public IP address, got '%s'",
+					env.InstancePublicIPAddress,
+				)
+			}
+
+			// The agent is only reachable via SSM
+			dialer, agentAddress, err := AWSService.EnvAgentDialer(env)
+
+			if err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			conn, err := dialer.DialContext(ctx, "tcp", agentAddress)
+
+			if err != nil {
+				t.Fatalf("expected no error during agent dialing, got '%+v'", err)
+			}
+
+			conn.Close()
+
+			err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if err != nil {
+				t.Fatalf("expected no error during env removal, got '%+v'", err)
+			}
+
+			err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+			if err != nil {
+				t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+			}
+
+			resourceCounts = fakeAWS.ResourceCounts()
+
+			if resourceCounts != (fakeaws.ResourceCounts{}) {
+				t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+			}
+		})
+	}
+}
+
+func TestPrivateNetworkingWithInvalidOpts(t *testing.T) {
+	testCases := []struct {
+		test          string
+		opts          service.PrivateNetworkingOpts
+		expectedError error
+	}{
+		{
+			test: "with unknown egress",
+			opts: service.PrivateNetworkingOpts{
+				Enabled:             true,
+				Egress:              "internet_gateway",
+				InstanceProfileName: "eleven-ssm-instance-profile",
+			},
+			expectedError: service.ErrInvalidPrivateEgress{},
+		},
+
+		// The instances need internet access during init
+		{
+			test: "with removed vpc_endpoints egress",
+			opts: service.PrivateNetworkingOpts{
+				Enabled:             true,
+				Egress:              "vpc_endpoints",
+				InstanceProfileName: "eleven-ssm-instance-profile",
+			},
+			expectedError: service.ErrInvalidPrivateEgress{},
+		},
+
+		{
+			test: "without instance profile",
+			opts: service.PrivateNetworkingOpts{
+				Enabled: true,
+			},
+			expectedError: service.ErrMissingInstanceProfile{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			fakeAWS := fakeaws.NewServer()
+			defer fakeAWS.Close()
+
+			tc.opts.PortForwarder = fakeAWS.PortForwarder()
+
+			AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+				InstanceDialer:    fakeAWS.Dialer(),
+				PrivateNetworking: tc.opts,
+			})
+
+			config := &entities.Config{}
+			cluster := &entities.Cluster{
+				Name: entities.DefaultClusterName,
+			}
+
+			err := AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+			if err == nil {
+				err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, &entities.Env{
+					Name:         "eleven-api",
+					InstanceType: "t2.medium",
+				})
+			}
+
+			if reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					tc.expectedError,
+					err,
+				)
+			}
+		})
+	}
+}
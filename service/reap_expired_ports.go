@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+const (
+	portExpirationDescriptionPrefix = "eleven-expires-at="
+)
+
+// PortExpiration represents a port opened
+// via OpenPortWithOpts with a TTL.
+type PortExpiration struct {
+	PortSpec  infrastructure.PortSpec `json:"port_spec"`
+	ExpiresAt time.Time               `json:"expires_at"`
+}
+
+// ReapExpiredPorts closes the ports whose TTL has expired
+// in all the envs of the cluster.
+//
+// The expirations are read from the security group rules
+// so that rules are closed even if the env
+// infrastructure was not saved.
+func (a *AWS) ReapExpiredPorts(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+) error {
+
+	envNames := []string{}
+	for envName := range cluster.Envs {
+		envNames = append(envNames, envName)
+	}
+
+	sort.Strings(envNames)
+
+	for _, envName := range envNames {
+		err := a.reapEnvExpiredPorts(ctx, stepper, cluster.Envs[envName])
+
+		if err != nil {
+			return wrapCanceledError(ctx, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *AWS) reapEnvExpiredPorts(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	env *entities.Env,
+) error {
+
+	if len(env.InfrastructureJSON) == 0 {
+		return nil
+	}
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.SecurityGroup == nil {
+		return nil
+	}
+
+	ingressRules, err := infrastructure.LookupSecurityGroupIngressRules(
+		ctx,
+		a.ec2Client,
+		envInfra.SecurityGroup.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for _, ingressRule := range ingressRules {
+		expiresAt, ok := parsePortExpirationDescription(ingressRule.Description)
+
+		if !ok || expiresAt.After(now) {
+			continue
+		}
+
+		stepper.StartTemporaryStep("Closing expired ports")
+
+		err := infrastructure.CloseInstancePort(
+			ctx,
+			a.ec2Client,
+			envInfra.SecurityGroup.ID,
+			ingressRule,
+		)
+
+		if err != nil && !errors.Is(err, infrastructure.ErrIngressRuleNotFound) {
+			return err
+		}
+	}
+
+	unexpiredPorts := []PortExpiration{}
+
+	for _, portExpiration := range envInfra.PortExpirations {
+		if portExpiration.ExpiresAt.After(now) {
+			unexpiredPorts = append(unexpiredPorts, portExpiration)
+		}
+	}
+
+	if len(unexpiredPorts) != len(envInfra.PortExpirations) {
+		envInfra.PortExpirations = unexpiredPorts
+		env.SetInfrastructureJSON(envInfra)
+	}
+
+	return nil
+}
+
+func buildPortExpirationDescription(expiresAt time.Time) string {
+	return portExpirationDescriptionPrefix + expiresAt.UTC().Format(time.RFC3339Nano)
+}
+
+func parsePortExpirationDescription(description string) (time.Time, bool) {
+	if !strings.HasPrefix(description, portExpirationDescriptionPrefix) {
+		return time.Time{}, false
+	}
+
+	expiresAt, err := time.Parse(
+		time.RFC3339Nano,
+		strings.TrimPrefix(description, portExpirationDescriptionPrefix),
+	)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return expiresAt, true
+}
@@ -0,0 +1,307 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestReapExpiredPorts(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: fakeAWS.Dialer(),
+	})
+
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	config := &entities.Config{
+		Clusters: map[string]*entities.Cluster{
+			cluster.Name: cluster,
+		},
+	}
+
+	err := AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	cluster.Envs = map[string]*entities.Env{
+		env.Name: env,
+	}
+
+	portsToOpen := []struct {
+		portSpec string
+		TTL      time.Duration
+	}{
+		{portSpec: "8080", TTL: time.Nanosecond},
+		{portSpec: "9090", TTL: time.Hour},
+		{portSpec: "7000"},
+	}
+
+	for _, portToOpen := range portsToOpen {
+		err = AWSService.OpenPortWithOpts(
+			ctx,
+			noopStepper{},
+			config,
+			cluster,
+			env,
+			portToOpen.portSpec,
+			service.OpenPortOpts{
+				TTL: portToOpen.TTL,
+			},
+		)
+
+		if err != nil {
+			t.Fatalf("expected no error during port opening, got '%+v'", err)
+		}
+	}
+
+	err = AWSService.OpenPortWithOpts(
+		ctx,
+		noopStepper{},
+		config,
+		cluster,
+		env,
+		"6000",
+		service.OpenPortOpts{
+			TTL: -time.Hour,
+		},
+	)
+
+	var invalidTTLErr service.ErrInvalidPortTTL
+	if !errors.As(err, &invalidTTLErr) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInvalidPortTTL{TTL: -time.Hour},
+			err,
+		)
+	}
+
+	err = AWSService.ReapExpiredPorts(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during expired ports reaping, got '%+v'", err)
+	}
+
+	openPorts, err := AWSService.ListOpenPorts(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during open ports listing, got '%+v'", err)
+	}
+
+	openPortExpirations := map[int32]*time.Time{}
+
+	for _, openPort := range openPorts {
+		if !openPort.ElevenManaged {
+			openPortExpirations[openPort.FromPort] = openPort.ExpiresAt
+		}
+	}
+
+	if len(openPortExpirations) != 2 ||
+		openPortExpirations[9090] == nil ||
+		openPortExpirations[7000] != nil {
+
+		t.Fatalf(
+			"expected ports 9090 (expiring) and 7000 to remain open, got '%+v'",
+			openPorts,
+		)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if len(envInfra.PortExpirations) != 1 ||
+		envInfra.PortExpirations[0].PortSpec.FromPort != 9090 ||
+		!envInfra.PortExpirations[0].ExpiresAt.Equal(*openPortExpirations[9090]) {
+
+		t.Fatalf(
+			"expected only port 9090 expiration to be recorded, got '%+v'",
+			envInfra.PortExpirations,
+		)
+	}
+
+	err = AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "9090")
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	err = json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	if len(envInfra.PortExpirations) != 0 {
+		t.Fatalf(
+			"expected no port expirations after port closing, got '%+v'",
+			envInfra.PortExpirations,
+		)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+}
+
+func TestClosePortKeepsExpirationsOfOtherCIDRs(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	for _, portSpec := range []string{"8080@10.0.0.0/8", "8080@1.2.3.4/32"} {
+		err := AWSService.OpenPortWithOpts(
+			ctx,
+			noopStepper{},
+			config,
+			cluster,
+			env,
+			portSpec,
+			service.OpenPortOpts{
+				TTL: time.Hour,
+			},
+		)
+
+		if err != nil {
+			t.Fatalf("expected no error during port opening, got '%+v'", err)
+		}
+	}
+
+	err := AWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "8080@10.0.0.0/8")
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if len(envInfra.PortExpirations) != 1 ||
+		len(envInfra.PortExpirations[0].PortSpec.CIDRs) != 1 ||
+		envInfra.PortExpirations[0].PortSpec.CIDRs[0] != "1.2.3.4/32" {
+
+		t.Fatalf(
+			"expected only the expiration of 8080@1.2.3.4/32 to remain, got '%+v'",
+			envInfra.PortExpirations,
+		)
+	}
+}
+
+func TestUpdateEnvIngressCIDRsUpdatesPortExpirations(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		IngressCIDRs: []string{"203.0.113.0/24"},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	for _, portSpec := range []string{"8080", "9090@10.0.0.0/8"} {
+		err := AWSService.OpenPortWithOpts(
+			ctx,
+			noopStepper{},
+			config,
+			cluster,
+			env,
+			portSpec,
+			service.OpenPortOpts{
+				TTL: time.Hour,
+			},
+		)
+
+		if err != nil {
+			t.Fatalf("expected no error during port opening, got '%+v'", err)
+		}
+	}
+
+	updatedAWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		InstanceDialer: fakeAWS.Dialer(),
+		IngressCIDRs:   []string{"198.51.100.0/24"},
+	})
+
+	err := updatedAWSService.UpdateEnvIngressCIDRs(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during ingress CIDRs update, got '%+v'", err)
+	}
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	expectedCIDRs := map[int32][]string{
+		8080: {"198.51.100.0/24"},
+		9090: {"10.0.0.0/8"},
+	}
+
+	if len(envInfra.PortExpirations) != len(expectedCIDRs) {
+		t.Fatalf(
+			"expected %d port expirations, got '%+v'",
+			len(expectedCIDRs),
+			envInfra.PortExpirations,
+		)
+	}
+
+	for _, portExpiration := range envInfra.PortExpirations {
+		CIDRs := expectedCIDRs[portExpiration.PortSpec.FromPort]
+
+		if !reflect.DeepEqual(portExpiration.PortSpec.CIDRs, CIDRs) {
+			t.Fatalf(
+				"expected port %d expiration CIDRs to equal '%+v', got '%+v'",
+				portExpiration.PortSpec.FromPort,
+				CIDRs,
+				portExpiration.PortSpec.CIDRs,
+			)
+		}
+	}
+
+	err = updatedAWSService.ClosePort(ctx, noopStepper{}, config, cluster, env, "8080")
+
+	if err != nil {
+		t.Fatalf("expected no error during port closing, got '%+v'", err)
+	}
+
+	envInfra = unmarshalEnvInfra(t, env)
+
+	if len(envInfra.PortExpirations) != 1 ||
+		envInfra.PortExpirations[0].PortSpec.FromPort != 9090 {
+
+		t.Fatalf(
+			"expected only port 9090 expiration to remain, got '%+v'",
+			envInfra.PortExpirations,
+		)
+	}
+}
@@ -1,9 +1,9 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/entities"
 	"github.com/eleven-sh/eleven/queues"
@@ -11,6 +11,7 @@ import (
 )
 
 func (a *AWS) RemoveCluster(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 	cluster *entities.Cluster,
@@ -23,24 +24,112 @@ func (a *AWS) RemoveCluster(
 		return err
 	}
 
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	ec2Client := a.ec2Client
 	clusterInfraQueue := queues.InfrastructureQueue[*ClusterInfrastructure]{}
 
-	removeSubnet := func(infra *ClusterInfrastructure) error {
-		if infra.Subnet == nil {
+	// The NAT gateway holds a network interface
+	// that prevents the subnets removal
+	removeNATGateway := func(infra *ClusterInfrastructure) error {
+		if infra.NATGateway == nil {
 			return nil
 		}
 
-		err := infrastructure.RemoveSubnet(
+		err := infrastructure.RemoveNATGateway(
+			ctx,
 			ec2Client,
-			infra.Subnet.ID,
+			a.opts.WaitOpts,
+			infra.NATGateway.ID,
 		)
 
 		if err != nil {
 			return err
 		}
 
-		infra.Subnet = nil
+		infra.NATGateway = nil
+		infra.NATRoute = nil
+		return nil
+	}
+
+	clusterInfraQueue = append(
+		clusterInfraQueue,
+		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
+			func(infra *ClusterInfrastructure) error {
+				if infra.NATGateway != nil {
+					stepper.StartTemporaryStep("Removing the NAT gateway")
+				}
+				return nil
+			},
+			removeNATGateway,
+		},
+	)
+
+	removeNATGatewayElasticIP := func(infra *ClusterInfrastructure) error {
+		if infra.NATGatewayElasticIP == nil {
+			return nil
+		}
+
+		err := infrastructure.RemoveElasticIP(
+			ctx,
+			ec2Client,
+			infra.NATGatewayElasticIP.ID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.NATGatewayElasticIP = nil
+		return nil
+	}
+
+	removeSubnets := func(infra *ClusterInfrastructure) error {
+		for len(infra.PrivateSubnets) > 0 {
+			err := infrastructure.RemoveSubnet(
+				ctx,
+				ec2Client,
+				infra.PrivateSubnets[0].ID,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			infra.PrivateSubnets = infra.PrivateSubnets[1:]
+		}
+
+		if infra.Subnet != nil {
+			err := infrastructure.RemoveSubnet(
+				ctx,
+				ec2Client,
+				infra.Subnet.ID,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			infra.Subnet = nil
+		}
+
+		// Subnets are removed one by one and forgotten
+		// as soon as they are removed (partial infrastructure).
+		// Externally owned subnets are only forgotten.
+		for len(infra.Subnets) > 0 {
+			if !infra.Subnets[0].IsExternallyOwned {
+				err := infrastructure.RemoveSubnet(
+					ctx,
+					ec2Client,
+					infra.Subnets[0].ID,
+				)
+
+				if err != nil {
+					return err
+				}
+			}
+
+			infra.Subnets = infra.Subnets[1:]
+		}
+
 		return nil
 	}
 
@@ -48,19 +137,40 @@ func (a *AWS) RemoveCluster(
 		clusterInfraQueue,
 		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
 			func(*ClusterInfrastructure) error {
-				stepper.StartTemporaryStep("Removing the subnet")
+				stepper.StartTemporaryStep("Removing the subnets")
 				return nil
 			},
-			removeSubnet,
+			removeSubnets,
+			removeNATGatewayElasticIP,
 		},
 	)
 
+	removePrivateRouteTable := func(infra *ClusterInfrastructure) error {
+		if infra.PrivateRouteTable == nil {
+			return nil
+		}
+
+		err := infrastructure.RemoveRouteTable(
+			ctx,
+			ec2Client,
+			infra.PrivateRouteTable.ID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.PrivateRouteTable = nil
+		return nil
+	}
+
 	removeRouteTable := func(infra *ClusterInfrastructure) error {
 		if infra.RouteTable == nil {
 			return nil
 		}
 
 		err := infrastructure.RemoveRouteTable(
+			ctx,
 			ec2Client,
 			infra.RouteTable.ID,
 		)
@@ -77,9 +187,10 @@ func (a *AWS) RemoveCluster(
 		clusterInfraQueue,
 		queues.InfrastructureQueueSteps[*ClusterInfrastructure]{
 			func(*ClusterInfrastructure) error {
-				stepper.StartTemporaryStep("Removing the route table")
+				stepper.StartTemporaryStep("Removing the route tables")
 				return nil
 			},
+			removePrivateRouteTable,
 			removeRouteTable,
 		},
 	)
@@ -90,6 +201,7 @@ func (a *AWS) RemoveCluster(
 		}
 
 		err := infrastructure.DetachInternetGatewayFromVPC(
+			ctx,
 			ec2Client,
 			infra.InternetGateway.ID,
 			infra.VPC.ID,
@@ -120,6 +232,7 @@ func (a *AWS) RemoveCluster(
 		}
 
 		err := infrastructure.RemoveInternetGateway(
+			ctx,
 			ec2Client,
 			infra.InternetGateway.ID,
 		)
@@ -148,7 +261,14 @@ func (a *AWS) RemoveCluster(
 			return nil
 		}
 
+		// Externally owned VPCs are only forgotten
+		if infra.VPC.IsExternallyOwned {
+			infra.VPC = nil
+			return nil
+		}
+
 		err := infrastructure.RemoveVPC(
+			ctx,
 			ec2Client,
 			infra.VPC.ID,
 		)
@@ -180,5 +300,5 @@ func (a *AWS) RemoveCluster(
 	// in case of error (partial infrastructure)
 	cluster.SetInfrastructureJSON(clusterInfra)
 
-	return err
+	return wrapCanceledError(ctx, err)
 }
@@ -1,9 +1,9 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 	"github.com/eleven-sh/eleven/entities"
 	"github.com/eleven-sh/eleven/queues"
@@ -11,6 +11,7 @@ import (
 )
 
 func (a *AWS) RemoveEnv(
+	ctx context.Context,
 	stepper stepper.Stepper,
 	config *entities.Config,
 	cluster *entities.Cluster,
@@ -24,7 +25,7 @@ func (a *AWS) RemoveEnv(
 		return err
 	}
 
-	ec2Client := ec2.NewFromConfig(a.sdkConfig)
+	ec2Client := a.ec2Client
 	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
 
 	terminateInstance := func(infra *EnvInfrastructure) error {
@@ -32,8 +33,26 @@ func (a *AWS) RemoveEnv(
 			return nil
 		}
 
+		// Persistent spot requests launch a new instance
+		// when their instance is terminated
+		if infra.Instance.SpotInstanceRequest != nil {
+			err := infrastructure.CancelSpotInstanceRequest(
+				ctx,
+				ec2Client,
+				infra.Instance.SpotInstanceRequest.ID,
+			)
+
+			if err != nil {
+				return err
+			}
+
+			infra.Instance.SpotInstanceRequest = nil
+		}
+
 		err := infrastructure.TerminateInstance(
+			ctx,
 			ec2Client,
+			a.opts.WaitOpts,
 			infra.Instance.ID,
 		)
 
@@ -51,6 +70,7 @@ func (a *AWS) RemoveEnv(
 		}
 
 		err := infrastructure.DetachElasticIPFromInstance(
+			ctx,
 			ec2Client,
 			infra.ElasticIP.AssociationID,
 		)
@@ -82,6 +102,7 @@ func (a *AWS) RemoveEnv(
 		}
 
 		err := infrastructure.RemoveKeyPair(
+			ctx,
 			ec2Client,
 			infra.KeyPair.ID,
 		)
@@ -100,6 +121,7 @@ func (a *AWS) RemoveEnv(
 		}
 
 		err := infrastructure.RemoveElasticIP(
+			ctx,
 			ec2Client,
 			infra.ElasticIP.ID,
 		)
@@ -130,6 +152,7 @@ func (a *AWS) RemoveEnv(
 		}
 
 		err := infrastructure.RemoveNetworkInterface(
+			ctx,
 			ec2Client,
 			infra.NetworkInterface.ID,
 		)
@@ -159,6 +182,7 @@ func (a *AWS) RemoveEnv(
 		}
 
 		err := infrastructure.RemoveSecurityGroup(
+			ctx,
 			ec2Client,
 			infra.SecurityGroup.ID,
 		)
@@ -190,5 +214,5 @@ func (a *AWS) RemoveEnv(
 	// in case of error (partial infrastructure)
 	env.SetInfrastructureJSON(envInfra)
 
-	return err
+	return wrapCanceledError(ctx, err)
 }
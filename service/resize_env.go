@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/queues"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// ErrInstanceTypeArchMismatch represents the error returned
+// when an env is resized to an instance type whose architecture
+// doesn't match the one of the AMI of the instance.
+type ErrInstanceTypeArchMismatch struct {
+	InstanceType     string
+	InstanceTypeArch string
+	AMIArch          string
+}
+
+func (ErrInstanceTypeArchMismatch) Error() string {
+	return "ErrInstanceTypeArchMismatch"
+}
+
+// ErrEnvHibernationEnabled represents the error returned when
+// an env created with the EnableHibernation option is resized
+// (AWS doesn't allow changing the type of these instances).
+type ErrEnvHibernationEnabled struct{}
+
+func (ErrEnvHibernationEnabled) Error() string {
+	return "ErrEnvHibernationEnabled"
+}
+
+// ResizeEnv changes the instance type of the env in place.
+// The instance is stopped during the change and started again
+// once done, unless the env was stopped before the call.
+// The restart is recorded in the env infrastructure so that
+// a retried resize restarts the instance after a failure.
+func (a *AWS) ResizeEnv(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+	instanceType string,
+) error {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.Instance == nil {
+		return ErrEnvInstanceNotCreated{}
+	}
+
+	if envInfra.Instance.HibernationEnabled {
+		return ErrEnvHibernationEnabled{}
+	}
+
+	if envInfra.Instance.SpotInstanceRequest != nil {
+		return ErrEnvSpot{}
+	}
+
+	stepper.StartTemporaryStep("Validating the new instance type")
+
+	instanceTypeInfos, err := a.lookupInstanceTypeInfos(ctx, instanceType)
+
+	if err != nil {
+		return err
+	}
+
+	// The AMI was looked up for the arch of the
+	// instance type when its arch was not recorded
+	AMIArch := envInfra.InstanceAMI.Arch
+
+	if len(AMIArch) == 0 {
+		AMIArch = envInfra.InstanceTypeInfos.Arch
+	}
+
+	if instanceTypeInfos.Arch != AMIArch {
+		return ErrInstanceTypeArchMismatch{
+			InstanceType:     instanceType,
+			InstanceTypeArch: string(instanceTypeInfos.Arch),
+			AMIArch:          string(AMIArch),
+		}
+	}
+
+	if envInfra.Subnet != nil {
+		instanceTypeAvailabilityZones, err := infrastructure.LookupInstanceTypeAvailabilityZones(
+			ctx,
+			a.ec2Client,
+			instanceType,
+		)
+
+		if err != nil {
+			return wrapCanceledError(ctx, err)
+		}
+
+		isOfferedInSubnet := false
+
+		for _, availabilityZone := range instanceTypeAvailabilityZones {
+			isOfferedInSubnet = isOfferedInSubnet ||
+				availabilityZone == envInfra.Subnet.AvailabilityZone
+		}
+
+		if !isOfferedInSubnet {
+			return ErrInstanceTypeNotOffered{
+				InstanceType:      instanceType,
+				AvailabilityZones: []string{envInfra.Subnet.AvailabilityZone},
+			}
+		}
+	}
+
+	// The env is left stopped if it was stopped before
+	// the call (and not by a previous failed resize)
+	if !envInfra.isInstanceStopped() {
+		envInfra.StartInstanceOnceResized = true
+	}
+
+	startInstanceOnceResized := envInfra.StartInstanceOnceResized
+
+	ec2Client := a.ec2Client
+	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
+
+	stopInstance := func(infra *EnvInfrastructure) error {
+		if infra.Instance.Type == instanceType {
+			return nil
+		}
+
+		return a.stopEnvInstance(ctx, infra)
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Waiting for the EC2 instance to stop")
+				return nil
+			},
+			stopInstance,
+		},
+	)
+
+	updateInstanceType := func(infra *EnvInfrastructure) error {
+		if infra.Instance.Type == instanceType {
+			return nil
+		}
+
+		err := infrastructure.UpdateInstanceType(
+			ctx,
+			ec2Client,
+			infra.Instance.ID,
+			instanceType,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		infra.Instance.Type = instanceType
+		infra.InstanceTypeInfos = instanceTypeInfos
+		env.InstanceType = instanceType
+
+		return nil
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Updating the EC2 instance type")
+				return nil
+			},
+			updateInstanceType,
+		},
+	)
+
+	if startInstanceOnceResized {
+		startInstance := func(infra *EnvInfrastructure) error {
+			err := a.startEnvInstance(ctx, infra)
+
+			if err != nil {
+				return err
+			}
+
+			infra.StartInstanceOnceResized = false
+			return nil
+		}
+
+		envInfraQueue = append(
+			envInfraQueue,
+			queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+				func(*EnvInfrastructure) error {
+					stepper.StartTemporaryStep("Waiting for the EC2 instance to start")
+					return nil
+				},
+				startInstance,
+			},
+		)
+
+		waitForInstanceToBeReachable := func(infra *EnvInfrastructure) error {
+			return a.waitForEnvInstanceToBeReachable(ctx, infra)
+		}
+
+		envInfraQueue = append(
+			envInfraQueue,
+			queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+				func(*EnvInfrastructure) error {
+					stepper.StartTemporaryStep("Waiting for the instance to be reachable")
+					return nil
+				},
+				waitForInstanceToBeReachable,
+			},
+		)
+	}
+
+	err = envInfraQueue.Run(envInfra)
+
+	// Env infra could be updated in the queue even
+	// in case of error (partial infrastructure)
+	env.SetInfrastructureJSON(envInfra)
+
+	return wrapCanceledError(ctx, err)
+}
@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	agentConfig "github.com/eleven-sh/agent/config"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+)
+
+func TestResizeEnv(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	// The architecture of the AMI doesn't match
+	err := AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "a1.xlarge")
+
+	typedErr, ok := err.(service.ErrInstanceTypeArchMismatch)
+
+	if !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInstanceTypeArchMismatch{},
+			err,
+		)
+	}
+
+	if typedErr.InstanceTypeArch != infrastructure.InstanceTypeArchArm64 ||
+		typedErr.AMIArch != infrastructure.InstanceTypeArchX8664 {
+
+		t.Fatalf("expected arm64 and x86_64 archs, got '%+v'", typedErr)
+	}
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+
+	err = AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "m5.large")
+
+	if err != nil {
+		t.Fatalf("expected no error during env resize, got '%+v'", err)
+	}
+
+	assertEnvInstanceType(t, env, "m5.large")
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+
+	conn, err := fakeAWS.Dialer().DialContext(
+		ctx,
+		"tcp",
+		net.JoinHostPort(
+			env.InstancePublicIPAddress,
+			agentConfig.SSHServerListenPort,
+		),
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error during agent dialing, got '%+v'", err)
+	}
+
+	conn.Close()
+
+	// Stopped envs stay stopped once resized
+	err = AWSService.StopEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env stop, got '%+v'", err)
+	}
+
+	err = AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "t3.medium")
+
+	if err != nil {
+		t.Fatalf("expected no error during env resize, got '%+v'", err)
+	}
+
+	assertEnvInstanceType(t, env, "t3.medium")
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateStopped)
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts.RunningInstances != 0 ||
+		resourceCounts.StoppedInstances != 1 {
+
+		t.Fatalf(
+			"expected one stopped instance, got '%+v'",
+			resourceCounts,
+		)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts = fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestResizeEnvRetryAfterFailure(t *testing.T) {
+	testCases := []struct {
+		test         string
+		failedAction string
+	}{
+		{
+			test:         "with instance type update failure",
+			failedAction: "ModifyInstanceAttribute",
+		},
+
+		{
+			test:         "with instance start failure",
+			failedAction: "StartInstances",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			fakeEnv := newFakeAWSEnv(t, service.AWSOpts{})
+
+			fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+			config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+			fakeAWS.FailNext(tc.failedAction, "InsufficientInstanceCapacity")
+
+			err := AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "m5.large")
+
+			if err == nil {
+				t.Fatalf("expected error during env resize, got nothing")
+			}
+
+			assertEnvPowerState(t, env, infrastructure.InstancePowerStateStopped)
+
+			// The instance was running before the first
+			// call so the retry must start it again
+			err = AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "m5.large")
+
+			if err != nil {
+				t.Fatalf("expected no error during env resize, got '%+v'", err)
+			}
+
+			assertEnvInstanceType(t, env, "m5.large")
+			assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+
+			envInfra := unmarshalEnvInfra(t, env)
+
+			if envInfra.StartInstanceOnceResized {
+				t.Fatalf("expected restart to be cleared once resized, got '%+v'", envInfra)
+			}
+		})
+	}
+}
+
+func TestResizeEnvWithHibernationEnabled(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		EnableHibernation: true,
+	})
+
+	AWSService := fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	err := AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "m5.large")
+
+	if _, ok := err.(service.ErrEnvHibernationEnabled); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrEnvHibernationEnabled{},
+			err,
+		)
+	}
+
+	assertEnvInstanceType(t, env, "t2.medium")
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+}
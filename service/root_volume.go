@@ -0,0 +1,135 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+)
+
+const (
+	RootVolumeTypeGP2 = "gp2"
+	RootVolumeTypeGP3 = "gp3"
+)
+
+const (
+	rootVolumeMinSizeGb = 8
+	rootVolumeMaxSizeGb = 16384
+
+	gp3MinIOPS            = 3000
+	gp3MaxIOPS            = 16000
+	gp3MinThroughputMiBps = 125
+	gp3MaxThroughputMiBps = 1000
+)
+
+// RootVolumeOpts represents the options used
+// to create the root volume of the instances.
+type RootVolumeOpts struct {
+	// SizeGb specifies the size of the root volume.
+	// Default to infrastructure.InstanceRootDeviceSizeGb if not set.
+	SizeGb int32
+
+	// Type specifies the type of the root volume.
+	// Either RootVolumeTypeGP2 or RootVolumeTypeGP3.
+	// Default to the AWS default type if not set.
+	Type string
+
+	// IOPS and ThroughputMiBps specify the performance of
+	// the RootVolumeTypeGP3 volumes (from 3000 to 16000 IOPS and
+	// from 125 to 1000 MiB/s). Default to the AWS baseline if not set.
+	IOPS            int32
+	ThroughputMiBps int32
+
+	// KMSKeyID specifies the ID (or ARN) of the KMS key used to
+	// encrypt the root volume. Not encrypted if not set (unless
+	// hibernation is enabled, then the default EBS key is used).
+	KMSKeyID string
+}
+
+// ErrInvalidRootVolumeOpts represents the error
+// returned when the root volume options are invalid.
+type ErrInvalidRootVolumeOpts struct {
+	Reason string
+}
+
+func (ErrInvalidRootVolumeOpts) Error() string {
+	return "ErrInvalidRootVolumeOpts"
+}
+
+func (r RootVolumeOpts) validate() error {
+	if r.SizeGb != 0 &&
+		(r.SizeGb < rootVolumeMinSizeGb || r.SizeGb > rootVolumeMaxSizeGb) {
+
+		return ErrInvalidRootVolumeOpts{
+			Reason: fmt.Sprintf(
+				"size must be between %d and %d GiB, got %d",
+				rootVolumeMinSizeGb,
+				rootVolumeMaxSizeGb,
+				r.SizeGb,
+			),
+		}
+	}
+
+	if len(r.Type) > 0 && r.Type != RootVolumeTypeGP2 && r.Type != RootVolumeTypeGP3 {
+		return ErrInvalidRootVolumeOpts{
+			Reason: fmt.Sprintf(
+				"type must be either %q or %q, got %q",
+				RootVolumeTypeGP2,
+				RootVolumeTypeGP3,
+				r.Type,
+			),
+		}
+	}
+
+	if (r.IOPS != 0 || r.ThroughputMiBps != 0) && r.Type != RootVolumeTypeGP3 {
+		return ErrInvalidRootVolumeOpts{
+			Reason: fmt.Sprintf(
+				"IOPS and throughput could only be set for %q volumes",
+				RootVolumeTypeGP3,
+			),
+		}
+	}
+
+	if r.IOPS != 0 && (r.IOPS < gp3MinIOPS || r.IOPS > gp3MaxIOPS) {
+		return ErrInvalidRootVolumeOpts{
+			Reason: fmt.Sprintf(
+				"IOPS must be between %d and %d, got %d",
+				gp3MinIOPS,
+				gp3MaxIOPS,
+				r.IOPS,
+			),
+		}
+	}
+
+	if r.ThroughputMiBps != 0 &&
+		(r.ThroughputMiBps < gp3MinThroughputMiBps || r.ThroughputMiBps > gp3MaxThroughputMiBps) {
+
+		return ErrInvalidRootVolumeOpts{
+			Reason: fmt.Sprintf(
+				"throughput must be between %d and %d MiB/s, got %d",
+				gp3MinThroughputMiBps,
+				gp3MaxThroughputMiBps,
+				r.ThroughputMiBps,
+			),
+		}
+	}
+
+	return nil
+}
+
+// instanceRootVolumeOpts returns the options passed to
+// infrastructure.CreateInstance, with the defaults applied.
+func (r RootVolumeOpts) instanceRootVolumeOpts() infrastructure.InstanceRootVolumeOpts {
+	sizeGb := r.SizeGb
+
+	if sizeGb == 0 {
+		sizeGb = infrastructure.InstanceRootDeviceSizeGb
+	}
+
+	return infrastructure.InstanceRootVolumeOpts{
+		SizeGb:          sizeGb,
+		Type:            r.Type,
+		IOPS:            r.IOPS,
+		ThroughputMiBps: r.ThroughputMiBps,
+		KMSKeyID:        r.KMSKeyID,
+	}
+}
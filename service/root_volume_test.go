@@ -0,0 +1,327 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+const testRootVolumeKMSKeyID = "arn:aws:kms:us-east-1:000000000000:key/eleven"
+
+func TestCreateEnvWithRootVolumeOptsAndGrowEnvDisk(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		RootVolume: service.RootVolumeOpts{
+			SizeGb:          50,
+			Type:            service.RootVolumeTypeGP3,
+			IOPS:            6000,
+			ThroughputMiBps: 250,
+			KMSKeyID:        testRootVolumeKMSKeyID,
+		},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	rootVolume := describeEnvRootVolume(t, fakeAWS, env)
+
+	if aws.ToInt32(rootVolume.Size) != 50 ||
+		rootVolume.VolumeType != types.VolumeTypeGp3 ||
+		aws.ToInt32(rootVolume.Iops) != 6000 ||
+		aws.ToInt32(rootVolume.Throughput) != 250 ||
+		!aws.ToBool(rootVolume.Encrypted) ||
+		aws.ToString(rootVolume.KmsKeyId) != testRootVolumeKMSKeyID {
+
+		t.Fatalf("expected root volume to match the options, got '%+v'", rootVolume)
+	}
+
+	// Volumes could only grow
+	err := AWSService.GrowEnvDisk(ctx, noopStepper{}, config, cluster, env, 40)
+
+	if _, ok := err.(service.ErrInvalidEnvDiskSize); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInvalidEnvDiskSize{},
+			err,
+		)
+	}
+
+	err = AWSService.GrowEnvDisk(ctx, noopStepper{}, config, cluster, env, 100)
+
+	if err != nil {
+		t.Fatalf("expected no error during env disk growth, got '%+v'", err)
+	}
+
+	assertEnvRootVolumeSize(t, fakeAWS, env, 100)
+
+	instanceID := envInstanceID(t, env)
+	SSHCommands := fakeAWS.InstanceSSHCommands(instanceID)
+
+	if len(SSHCommands) == 0 ||
+		!strings.Contains(SSHCommands[len(SSHCommands)-1], "resize2fs") {
+
+		t.Fatalf("expected root filesystem to be extended, got '%+v'", SSHCommands)
+	}
+
+	// The filesystem of stopped envs is
+	// extended during the next start
+	err = AWSService.StopEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env stop, got '%+v'", err)
+	}
+
+	err = AWSService.GrowEnvDisk(ctx, noopStepper{}, config, cluster, env, 120)
+
+	if err != nil {
+		t.Fatalf("expected no error during env disk growth, got '%+v'", err)
+	}
+
+	assertEnvRootVolumeSize(t, fakeAWS, env, 120)
+
+	if len(fakeAWS.InstanceSSHCommands(instanceID)) != len(SSHCommands) {
+		t.Fatalf(
+			"expected no command to be run on stopped instance, got '%+v'",
+			fakeAWS.InstanceSSHCommands(instanceID),
+		)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestCreateEnvWithEnvLevelRootVolumeOpts(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+		RootVolume: service.RootVolumeOpts{
+			SizeGb: 20,
+			Type:   service.RootVolumeTypeGP2,
+		},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster := fakeEnv.config, fakeEnv.cluster
+
+	envWithOpts := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err := AWSService.CreateEnvWithOpts(
+		ctx,
+		noopStepper{},
+		config,
+		cluster,
+		envWithOpts,
+		service.CreateEnvOpts{
+			RootVolume: &service.RootVolumeOpts{
+				SizeGb: 40,
+				Type:   service.RootVolumeTypeGP3,
+				IOPS:   4000,
+			},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	rootVolume := describeEnvRootVolume(t, fakeAWS, envWithOpts)
+
+	if aws.ToInt32(rootVolume.Size) != 40 ||
+		rootVolume.VolumeType != types.VolumeTypeGp3 ||
+		aws.ToInt32(rootVolume.Iops) != 4000 {
+
+		t.Fatalf("expected root volume to match the env options, got '%+v'", rootVolume)
+	}
+
+	// Envs created without options use the service options
+	envWithoutOpts := &entities.Env{
+		Name:         "eleven-web",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, envWithoutOpts)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	rootVolume = describeEnvRootVolume(t, fakeAWS, envWithoutOpts)
+
+	if aws.ToInt32(rootVolume.Size) != 20 ||
+		rootVolume.VolumeType != types.VolumeTypeGp2 {
+
+		t.Fatalf("expected root volume to match the service options, got '%+v'", rootVolume)
+	}
+
+	// Invalid env options are rejected
+	// before any resource is created
+	envWithInvalidOpts := &entities.Env{
+		Name:         "eleven-cli",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnvWithOpts(
+		ctx,
+		noopStepper{},
+		config,
+		cluster,
+		envWithInvalidOpts,
+		service.CreateEnvOpts{
+			RootVolume: &service.RootVolumeOpts{
+				Type: "io2",
+			},
+		},
+	)
+
+	if _, ok := err.(service.ErrInvalidRootVolumeOpts); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInvalidRootVolumeOpts{},
+			err,
+		)
+	}
+}
+
+func TestCreateEnvWithInvalidRootVolumeOpts(t *testing.T) {
+	testCases := []struct {
+		test           string
+		rootVolumeOpts service.RootVolumeOpts
+	}{
+		{
+			test: "with too small size",
+			rootVolumeOpts: service.RootVolumeOpts{
+				SizeGb: 4,
+			},
+		},
+
+		{
+			test: "with unsupported type",
+			rootVolumeOpts: service.RootVolumeOpts{
+				Type: "io2",
+			},
+		},
+
+		{
+			test: "with IOPS and gp2 type",
+			rootVolumeOpts: service.RootVolumeOpts{
+				Type: service.RootVolumeTypeGP2,
+				IOPS: 4000,
+			},
+		},
+
+		{
+			test: "with too large throughput",
+			rootVolumeOpts: service.RootVolumeOpts{
+				Type:            service.RootVolumeTypeGP3,
+				ThroughputMiBps: 2000,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+				RootVolume: tc.rootVolumeOpts,
+			})
+
+			fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+			config, cluster := fakeEnv.config, fakeEnv.cluster
+
+			env := &entities.Env{
+				Name:         "eleven-api",
+				InstanceType: "t2.medium",
+			}
+
+			err := AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if _, ok := err.(service.ErrInvalidRootVolumeOpts); !ok {
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					service.ErrInvalidRootVolumeOpts{},
+					err,
+				)
+			}
+
+			if fakeAWS.ResourceCounts().SecurityGroups != 0 {
+				t.Fatalf(
+					"expected no env resources to be created, got '%+v'",
+					fakeAWS.ResourceCounts(),
+				)
+			}
+		})
+	}
+}
+
+func describeEnvRootVolume(
+	t *testing.T,
+	fakeAWS *fakeaws.Server,
+	env *entities.Env,
+) types.Volume {
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	ec2Client := ec2.NewFromConfig(fakeAWS.Config())
+
+	describeVolumesResp, err := ec2Client.DescribeVolumes(
+		context.Background(),
+		&ec2.DescribeVolumesInput{
+			VolumeIds: []string{envInfra.Instance.RootVolume().ID},
+		},
+	)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	return describeVolumesResp.Volumes[0]
+}
+
+func assertEnvRootVolumeSize(
+	t *testing.T,
+	fakeAWS *fakeaws.Server,
+	env *entities.Env,
+	expectedSizeGb int32,
+) {
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	recordedSizeGb := envInfra.Instance.RootVolume().SizeGb
+	actualSizeGb := aws.ToInt32(describeEnvRootVolume(t, fakeAWS, env).Size)
+
+	if recordedSizeGb != expectedSizeGb || actualSizeGb != expectedSizeGb {
+		t.Fatalf(
+			"expected root volume size to equal %d, got %d (recorded %d)",
+			expectedSizeGb,
+			actualSizeGb,
+			recordedSizeGb,
+		)
+	}
+}
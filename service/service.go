@@ -1,15 +1,209 @@
 package service
 
 import (
+	"net"
+	"net/url"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eleven-sh/aws-cloud-provider/config"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
 )
 
+//go:generate go run github.com/golang/mock/mockgen -destination ../mocks/ec2_client.go -package mocks -mock_names EC2Client=EC2Client github.com/eleven-sh/aws-cloud-provider/service EC2Client
+type EC2Client interface {
+	infrastructure.AssociateRouteTableAPIClient
+	infrastructure.AttachElasticIPToInstanceAPIClient
+	infrastructure.AttachInternetGatewayToVPCAPIClient
+	infrastructure.CancelSpotInstanceRequestAPIClient
+	infrastructure.CloseInstancePortAPIClient
+	infrastructure.CreateElasticIPAPIClient
+	infrastructure.CreateInstanceAPIClient
+	infrastructure.CreateInternetGatewayAPIClient
+	infrastructure.CreateKeyPairAPIClient
+	infrastructure.CreateNATGatewayAPIClient
+	infrastructure.CreateNetworkInterfaceAPIClient
+	infrastructure.CreateRouteAPIClient
+	infrastructure.CreateRouteTableAPIClient
+	infrastructure.CreateSecurityGroupAPIClient
+	infrastructure.CreateSubnetAPIClient
+	infrastructure.CreateVPCAPIClient
+	infrastructure.DetachElasticIPFromInstanceAPIClient
+	infrastructure.DetachInternetGatewayFromVPCAPIClient
+	infrastructure.GrowVolumeAPIClient
+	infrastructure.LookupAvailabilityZonesAPIClient
+	infrastructure.LookupInstanceSSHHostKeysAPIClient
+	infrastructure.LookupInstanceTypeAvailabilityZonesAPIClient
+	infrastructure.LookupSpotInstanceRequestStatusAPIClient
+	infrastructure.LookupSubnetAPIClient
+	infrastructure.LookupSubnetEgressTargetAPIClient
+	infrastructure.LookupVPCAPIClient
+	infrastructure.LookupInstanceTypeInfosAPIClient
+	infrastructure.LookupUbuntuAMIForArchAPIClient
+	infrastructure.OpenInstancePortAPIClient
+	infrastructure.RemoveElasticIPAPIClient
+	infrastructure.RemoveInternetGatewayAPIClient
+	infrastructure.RemoveKeyPairAPIClient
+	infrastructure.RemoveNATGatewayAPIClient
+	infrastructure.RemoveNetworkInterfaceAPIClient
+	infrastructure.RemoveRouteTableAPIClient
+	infrastructure.RemoveSecurityGroupAPIClient
+	infrastructure.RemoveSubnetAPIClient
+	infrastructure.RemoveVPCAPIClient
+	infrastructure.StartInstanceAPIClient
+	infrastructure.StopInstanceAPIClient
+	infrastructure.TerminateInstanceAPIClient
+	infrastructure.UpdateInstanceTypeAPIClient
+	infrastructure.UpdateSecurityGroupIngressCIDRsAPIClient
+}
+
+//go:generate go run github.com/golang/mock/mockgen -destination ../mocks/dynamodb_client.go -package mocks -mock_names DynamoDBClient=DynamoDBClient github.com/eleven-sh/aws-cloud-provider/service DynamoDBClient
+type DynamoDBClient interface {
+	infrastructure.CreateDynamoDBTableForElevenConfigAPIClient
+	infrastructure.LookupElevenConfigInDynamoDBTableAPIClient
+	infrastructure.UpdateElevenConfigInDynamoDBTableAPIClient
+	infrastructure.RemoveDynamoDBTableForElevenConfigAPIClient
+}
+
+// AWSOpts represents the options
+// used to configure the AWS service.
+type AWSOpts struct {
+	// InstanceDialer specifies the dialer used to reach instances over SSH.
+	// Default to net.Dialer if not set.
+	InstanceDialer infrastructure.Dialer
+
+	// SSHJumpHosts specifies the chain of jump hosts (like "ssh -J")
+	// used to reach the instances over SSH. The instance ingress CIDRs
+	// must allow the last jump host. The host key of each jump host
+	// must be set.
+	SSHJumpHosts []infrastructure.SSHJumpHost
+
+	// SSHProxyURL specifies the SOCKS5 ("socks5://", or "socks5h://" to
+	// let the proxy resolve the host names) or HTTP CONNECT
+	// ("http://") proxy used to reach the first jump host
+	// (or the instances if SSHJumpHosts is not set).
+	SSHProxyURL *url.URL
+
+	// WaitOpts specifies the timeouts and the poll intervals
+	// used while waiting for resources to reach the expected state.
+	// Unset values default to the ones described in infrastructure.WaitOpts.
+	WaitOpts infrastructure.WaitOpts
+
+	// IngressCIDRs specifies the IPv4 and IPv6 CIDRs allowed to reach
+	// the instances (SSH, agent and opened ports).
+	// Default to "0.0.0.0/0" if not set and AutoDetectIngressIP is false.
+	IngressCIDRs []string
+
+	// AutoDetectIngressIP specifies if the public IP of
+	// the caller is added to the IngressCIDRs.
+	AutoDetectIngressIP bool
+
+	// PublicIPResolver specifies the resolver used to look up
+	// the public IP of the caller when AutoDetectIngressIP is set.
+	// Default to infrastructure.HTTPPublicIPResolver if not set.
+	PublicIPResolver infrastructure.PublicIPResolver
+
+	// ClusterNetwork specifies the VPC CIDR block and the subnets
+	// of the clusters (or the existing VPC and subnets to use).
+	// Only applies to the clusters created after the options were set.
+	ClusterNetwork ClusterNetworkOpts
+
+	// EnableIPv6 specifies if the clusters are created in dual-stack mode
+	// (an Amazon-provided IPv6 CIDR block is associated with the VPC and
+	// the instances are assigned an IPv6 address).
+	// Only applies to the clusters created after the option was set.
+	EnableIPv6 bool
+
+	// RootVolume specifies the size, the type, the performance
+	// and the encryption of the root volume of the instances.
+	// Only applies to the envs created after the options were set.
+	// Could be overridden per env via CreateEnvWithOpts.
+	RootVolume RootVolumeOpts
+
+	// EnableHibernation specifies if the instances are created with
+	// hibernation enabled (via an encrypted root volume sized for the RAM)
+	// so that the envs could be hibernated via HibernateEnv.
+	// Only applies to the envs created after the option was set.
+	EnableHibernation bool
+
+	// Spot specifies if the instances are launched as persistent spot
+	// instances (stopped when interrupted) and their max price.
+	// Only applies to the envs created after the options were set.
+	Spot SpotOpts
+
+	// PrivateNetworking specifies if the sandboxes are created in private
+	// subnets, without public IP, and reached via SSM Session Manager.
+	PrivateNetworking PrivateNetworkingOpts
+
+	// ValidateRegionOptIn specifies if the Builder checks (via the EC2 API)
+	// that the region exists and is enabled on the account.
+	// Requires the "ec2:DescribeRegions" permission.
+	ValidateRegionOptIn bool
+}
+
 type AWS struct {
-	sdkConfig aws.Config
+	sdkConfig      aws.Config
+	partition      string
+	ec2Client      EC2Client
+	dynamoDBClient DynamoDBClient
+	opts           AWSOpts
 }
 
-func NewAWS(SDKConfig aws.Config) *AWS {
+func NewAWS(SDKConfig aws.Config, opts AWSOpts) *AWS {
+	return NewAWSWithClients(
+		SDKConfig,
+		ec2.NewFromConfig(SDKConfig),
+		dynamodb.NewFromConfig(SDKConfig),
+		opts,
+	)
+}
+
+func NewAWSWithClients(
+	SDKConfig aws.Config,
+	ec2Client EC2Client,
+	dynamoDBClient DynamoDBClient,
+	opts AWSOpts,
+) *AWS {
+
+	if opts.InstanceDialer == nil {
+		opts.InstanceDialer = &net.Dialer{}
+	}
+
+	if len(opts.SSHJumpHosts) > 0 || opts.SSHProxyURL != nil {
+		opts.InstanceDialer = infrastructure.SSHJumpHostDialer{
+			Dialer:    opts.InstanceDialer,
+			ProxyURL:  opts.SSHProxyURL,
+			JumpHosts: opts.SSHJumpHosts,
+		}
+	}
+
+	if opts.PublicIPResolver == nil {
+		opts.PublicIPResolver = infrastructure.HTTPPublicIPResolver{}
+	}
+
+	if opts.PrivateNetworking.PortForwarder == nil {
+		opts.PrivateNetworking.PortForwarder = infrastructure.SessionManagerPortForwarder{
+			Region:      SDKConfig.Region,
+			Credentials: SDKConfig.Credentials,
+		}
+	}
+
+	opts.WaitOpts = opts.WaitOpts.WithDefaults()
+
+	// Regions are validated by the Builder. Fall back to
+	// the standard partition for the other ones.
+	partition, ok := config.LookupPartitionForRegion(SDKConfig.Region)
+
+	if !ok {
+		partition.ID = config.PartitionAWS
+	}
+
 	return &AWS{
-		sdkConfig: SDKConfig,
+		sdkConfig:      SDKConfig,
+		partition:      partition.ID,
+		ec2Client:      ec2Client,
+		dynamoDBClient: dynamoDBClient,
+		opts:           opts,
 	}
 }
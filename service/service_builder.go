@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/eleven-sh/aws-cloud-provider/config"
 	"github.com/eleven-sh/aws-cloud-provider/userconfig"
 	"github.com/eleven-sh/eleven/entities"
 )
@@ -25,22 +29,28 @@ type Builder struct {
 	userConfigResolver  UserConfigResolver
 	userConfigValidator UserConfigValidator
 	userConfigLoader    UserConfigLoader
+	opts                AWSOpts
 }
 
 func NewBuilder(
 	userConfigResolver UserConfigResolver,
 	userConfigValidator UserConfigValidator,
 	userConfigLoader UserConfigLoader,
+	AWSOpts AWSOpts,
 ) Builder {
 
 	return Builder{
 		userConfigResolver:  userConfigResolver,
 		userConfigValidator: userConfigValidator,
 		userConfigLoader:    userConfigLoader,
+		opts:                AWSOpts,
 	}
 }
 
-func (b Builder) Build() (entities.CloudService, error) {
+// Build resolves, validates and loads the user config then
+// constructs the AWS service. The context is used to cancel the
+// region opt-in validation (see AWSOpts.ValidateRegionOptIn).
+func (b Builder) Build(ctx context.Context) (entities.CloudService, error) {
 	userConfig, err := b.userConfigResolver.Resolve()
 
 	if err != nil {
@@ -57,7 +67,19 @@ func (b Builder) Build() (entities.CloudService, error) {
 		return nil, err
 	}
 
-	AWSService := NewAWS(AWSSDKConfig)
+	if b.opts.ValidateRegionOptIn {
+		regionValidator := config.NewRegionOptInValidator(
+			ec2.NewFromConfig(AWSSDKConfig),
+		)
+
+		err := regionValidator.Validate(ctx, userConfig.Region)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	AWSService := NewAWS(AWSSDKConfig, b.opts)
 
 	return AWSService, nil
 }
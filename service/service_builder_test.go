@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -31,8 +32,9 @@ func TestServiceBuilderBuildWithResolvedUserConfig(t *testing.T) {
 		userConfigResolver,
 		userConfigValidator,
 		userConfigLoader,
+		service.AWSOpts{},
 	)
-	_, err := builder.Build()
+	_, err := builder.Build(context.Background())
 
 	if err != nil {
 		t.Fatalf("expected no error, got '%+v'", err)
@@ -59,8 +61,9 @@ func TestServiceBuilderBuildWithUserConfigResolverError(t *testing.T) {
 		userConfigResolver,
 		userConfigValidator,
 		userConfigLoader,
+		service.AWSOpts{},
 	)
-	_, err := builder.Build()
+	_, err := builder.Build(context.Background())
 
 	if err == nil {
 		t.Fatalf("expected error, got nothing")
@@ -95,8 +98,9 @@ func TestServiceBuilderBuildWithUserConfigValidatorError(t *testing.T) {
 		userConfigResolver,
 		userConfigValidator,
 		userConfigLoader,
+		service.AWSOpts{},
 	)
-	_, err := builder.Build()
+	_, err := builder.Build(context.Background())
 
 	if err == nil {
 		t.Fatalf("expected error, got nothing")
@@ -131,8 +135,9 @@ func TestBuildWithConfigLoaderError(t *testing.T) {
 		userConfigResolver,
 		userConfigValidator,
 		userConfigLoader,
+		service.AWSOpts{},
 	)
-	_, err := builder.Build()
+	_, err := builder.Build(context.Background())
 
 	if err == nil {
 		t.Fatalf("expected error, got nothing")
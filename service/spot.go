@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// SpotOpts represents the options used to launch
+// the instances as persistent spot instances.
+type SpotOpts struct {
+	// Enabled specifies if the instances are launched as persistent
+	// spot instances. Interrupted instances are stopped (or hibernated
+	// when EnableHibernation is set) and started again by EC2 once
+	// capacity is available.
+	Enabled bool
+
+	// MaxPrice specifies the maximum hourly price in USD (like "0.05").
+	// Default to the on-demand price if not set.
+	MaxPrice string
+}
+
+// ErrInvalidSpotOpts represents the error
+// returned when the spot options are invalid.
+type ErrInvalidSpotOpts struct {
+	Reason string
+}
+
+func (ErrInvalidSpotOpts) Error() string {
+	return "ErrInvalidSpotOpts"
+}
+
+// ErrEnvNotSpot represents the error returned when the spot
+// interruptions of an env created without the Spot option
+// are looked up.
+type ErrEnvNotSpot struct{}
+
+func (ErrEnvNotSpot) Error() string {
+	return "ErrEnvNotSpot"
+}
+
+// ErrEnvSpot represents the error returned when an env
+// created with the Spot option is resized (AWS doesn't allow
+// changing the type of spot instances).
+type ErrEnvSpot struct{}
+
+func (ErrEnvSpot) Error() string {
+	return "ErrEnvSpot"
+}
+
+func (s SpotOpts) validate() error {
+	if len(s.MaxPrice) == 0 {
+		return nil
+	}
+
+	if !s.Enabled {
+		return ErrInvalidSpotOpts{
+			Reason: "the max price requires spot instances to be enabled",
+		}
+	}
+
+	maxPrice, err := strconv.ParseFloat(s.MaxPrice, 64)
+
+	if err != nil || maxPrice <= 0 {
+		return ErrInvalidSpotOpts{
+			Reason: "the max price must be a positive number of USD",
+		}
+	}
+
+	return nil
+}
+
+// instanceUsageClass returns the usage class
+// that the instance types must support.
+func (s SpotOpts) instanceUsageClass() string {
+	if s.Enabled {
+		return infrastructure.InstanceUsageClassSpot
+	}
+
+	return infrastructure.InstanceUsageClassOnDemand
+}
+
+// instanceSpotOpts returns the options passed
+// to infrastructure.CreateInstance.
+func (s SpotOpts) instanceSpotOpts() infrastructure.InstanceSpotOpts {
+	return infrastructure.InstanceSpotOpts{
+		Enabled:  s.Enabled,
+		MaxPrice: s.MaxPrice,
+	}
+}
+
+// SpotInterruption represents an interruption notice
+// (sent two minutes before the interruption) or an
+// interruption of the spot instance of an env.
+type SpotInterruption struct {
+	StatusCode  string    `json:"status_code"`
+	Message     string    `json:"message"`
+	Time        time.Time `json:"time"`
+	Interrupted bool      `json:"interrupted"`
+}
+
+// LookupEnvSpotInterruption returns the interruption notice or
+// the interruption of the spot instance of the env, or nil if the
+// instance is not about to be (or was not) interrupted.
+func (a *AWS) LookupEnvSpotInterruption(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) (*SpotInterruption, error) {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if envInfra.Instance == nil {
+		return nil, ErrEnvInstanceNotCreated{}
+	}
+
+	if envInfra.Instance.SpotInstanceRequest == nil {
+		return nil, ErrEnvNotSpot{}
+	}
+
+	stepper.StartTemporaryStep("Looking up the status of the spot request")
+
+	spotRequestStatus, err := infrastructure.LookupSpotInstanceRequestStatus(
+		ctx,
+		a.ec2Client,
+		envInfra.Instance.SpotInstanceRequest.ID,
+	)
+
+	if err != nil {
+		return nil, wrapCanceledError(ctx, err)
+	}
+
+	if !spotRequestStatus.IsInterruptionNotice() &&
+		!spotRequestStatus.IsInterrupted() {
+
+		return nil, nil
+	}
+
+	return &SpotInterruption{
+		StatusCode:  spotRequestStatus.StatusCode,
+		Message:     spotRequestStatus.Message,
+		Time:        spotRequestStatus.UpdateTime,
+		Interrupted: spotRequestStatus.IsInterrupted(),
+	}, nil
+}
@@ -0,0 +1,263 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestCreateSpotEnvAndLookupInterruption(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{
+		Spot: service.SpotOpts{
+			Enabled:  true,
+			MaxPrice: "0.05",
+		},
+	})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	envInfra := unmarshalEnvInfra(t, env)
+
+	if envInfra.Instance.SpotInstanceRequest == nil ||
+		len(envInfra.Instance.SpotInstanceRequest.ID) == 0 ||
+		envInfra.Instance.SpotInstanceRequest.MaxPrice != "0.05" {
+
+		t.Fatalf(
+			"expected spot request to be recorded, got '%+v'",
+			envInfra.Instance.SpotInstanceRequest,
+		)
+	}
+
+	if fakeAWS.ResourceCounts().SpotInstanceRequests != 1 {
+		t.Fatalf(
+			"expected one spot request, got '%+v'",
+			fakeAWS.ResourceCounts(),
+		)
+	}
+
+	// Spot instances cannot be resized
+	err := AWSService.ResizeEnv(ctx, noopStepper{}, config, cluster, env, "m5.large")
+
+	if _, ok := err.(service.ErrEnvSpot); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrEnvSpot{},
+			err,
+		)
+	}
+
+	interruption, err := AWSService.LookupEnvSpotInterruption(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during interruption lookup, got '%+v'", err)
+	}
+
+	if interruption != nil {
+		t.Fatalf("expected no interruption, got '%+v'", interruption)
+	}
+
+	// Stopping the env is not an interruption
+	err = AWSService.StopEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env stop, got '%+v'", err)
+	}
+
+	interruption, err = AWSService.LookupEnvSpotInterruption(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during interruption lookup, got '%+v'", err)
+	}
+
+	if interruption != nil {
+		t.Fatalf("expected no interruption once stopped, got '%+v'", interruption)
+	}
+
+	err = AWSService.StartEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env start, got '%+v'", err)
+	}
+
+	fakeAWS.NotifySpotInstanceInterruption(envInfra.Instance.ID)
+
+	interruption, err = AWSService.LookupEnvSpotInterruption(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during interruption lookup, got '%+v'", err)
+	}
+
+	if interruption == nil || interruption.Interrupted ||
+		interruption.StatusCode != "marked-for-stop" || interruption.Time.IsZero() {
+
+		t.Fatalf("expected interruption notice, got '%+v'", interruption)
+	}
+
+	fakeAWS.InterruptSpotInstance(envInfra.Instance.ID)
+
+	interruption, err = AWSService.LookupEnvSpotInterruption(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during interruption lookup, got '%+v'", err)
+	}
+
+	if interruption == nil || !interruption.Interrupted ||
+		interruption.StatusCode != "instance-stopped-no-capacity" {
+
+		t.Fatalf("expected interruption, got '%+v'", interruption)
+	}
+
+	// The spot request is canceled so that
+	// no instance is launched once terminated
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestLookupSpotInterruptionOfOnDemandEnv(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	_, err := AWSService.LookupEnvSpotInterruption(ctx, noopStepper{}, config, cluster, env)
+
+	if _, ok := err.(service.ErrEnvNotSpot); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrEnvNotSpot{},
+			err,
+		)
+	}
+
+	if fakeAWS.ResourceCounts().SpotInstanceRequests != 0 {
+		t.Fatalf(
+			"expected no spot request, got '%+v'",
+			fakeAWS.ResourceCounts(),
+		)
+	}
+}
+
+func TestCheckInstanceTypeValidityWithSpot(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		Spot: service.SpotOpts{
+			Enabled: true,
+		},
+	})
+
+	err := AWSService.CheckInstanceTypeValidity(ctx, noopStepper{}, "t2.medium")
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	// High memory instances are only available on demand
+	err = AWSService.CheckInstanceTypeValidity(ctx, noopStepper{}, "u-6tb1.metal")
+
+	typedErr, ok := err.(service.ErrInstanceTypeUsageClassNotSupported)
+
+	if !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrInstanceTypeUsageClassNotSupported{},
+			err,
+		)
+	}
+
+	if typedErr.UsageClass != infrastructure.InstanceUsageClassSpot {
+		t.Fatalf("expected spot usage class, got '%+v'", typedErr)
+	}
+}
+
+func TestCreateEnvWithInvalidSpotOpts(t *testing.T) {
+	testCases := []struct {
+		test     string
+		spotOpts service.SpotOpts
+	}{
+		{
+			test: "with max price and spot disabled",
+			spotOpts: service.SpotOpts{
+				MaxPrice: "0.05",
+			},
+		},
+
+		{
+			test: "with invalid max price",
+			spotOpts: service.SpotOpts{
+				Enabled:  true,
+				MaxPrice: "five cents",
+			},
+		},
+
+		{
+			test: "with negative max price",
+			spotOpts: service.SpotOpts{
+				Enabled:  true,
+				MaxPrice: "-0.05",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			fakeEnv := newFakeAWSCluster(t, service.AWSOpts{
+				Spot: tc.spotOpts,
+			})
+
+			fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+			config, cluster := fakeEnv.config, fakeEnv.cluster
+
+			env := &entities.Env{
+				Name:         "eleven-api",
+				InstanceType: "t2.medium",
+			}
+
+			err := AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if _, ok := err.(service.ErrInvalidSpotOpts); !ok {
+				t.Fatalf(
+					"expected error to equal '%+v', got '%+v'",
+					service.ErrInvalidSpotOpts{},
+					err,
+				)
+			}
+
+			if fakeAWS.ResourceCounts().SecurityGroups != 0 {
+				t.Fatalf(
+					"expected no env resources to be created, got '%+v'",
+					fakeAWS.ResourceCounts(),
+				)
+			}
+		})
+	}
+}
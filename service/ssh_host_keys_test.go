@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestCreateEnvPinsInstanceSSHHostKeys(t *testing.T) {
+	testCases := []struct {
+		test                         string
+		impersonateInstanceSSHServer bool
+		expectedError                error
+	}{
+		{
+			test: "with genuine instance",
+		},
+
+		{
+			test:                         "with impersonated instance",
+			impersonateInstanceSSHServer: true,
+			expectedError:                infrastructure.ErrInstanceSSHHostKeyMismatch{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			ctx := context.Background()
+
+			fakeEnv := newFakeAWSCluster(t, service.AWSOpts{})
+
+			fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+			config, cluster := fakeEnv.config, fakeEnv.cluster
+
+			if tc.impersonateInstanceSSHServer {
+				fakeAWS.ImpersonateInstanceSSHServers()
+			}
+
+			env := &entities.Env{
+				Name:         "eleven-api",
+				InstanceType: "t2.medium",
+			}
+
+			err := AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+			if tc.expectedError != nil {
+				var hostKeyMismatchErr infrastructure.ErrInstanceSSHHostKeyMismatch
+
+				if !errors.As(err, &hostKeyMismatchErr) {
+					t.Fatalf(
+						"expected error to equal '%+v', got '%+v'",
+						tc.expectedError,
+						err,
+					)
+				}
+
+				if len(hostKeyMismatchErr.ExpectedFingerprints) != 1 ||
+					hostKeyMismatchErr.ExpectedFingerprints[0] == hostKeyMismatchErr.ReceivedFingerprint {
+
+					t.Fatalf(
+						"expected fingerprints to differ, got '%+v'",
+						hostKeyMismatchErr,
+					)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error during env creation, got '%+v'", err)
+			}
+
+			envInfra := unmarshalEnvInfra(t, env)
+
+			if len(envInfra.Instance.SSHHostKeys) != 1 {
+				t.Fatalf(
+					"expected one pinned SSH host key, got '%+v'",
+					envInfra.Instance.SSHHostKeys,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package service_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCreateEnvThroughSSHJumpHosts(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	bastionPrivateKey, bastionPublicKey := generateJumpHostKeyPair(t)
+	internalPrivateKey, internalPublicKey := generateJumpHostKeyPair(t)
+
+	bastion := fakeAWS.NewJumpHost("bastion", bastionPublicKey)
+	defer bastion.Close()
+
+	internalJumpHost := fakeAWS.NewJumpHost("internal", internalPublicKey)
+	defer internalJumpHost.Close()
+
+	// No InstanceDialer: the instances are
+	// only reachable through the jump hosts
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		SSHJumpHosts: []infrastructure.SSHJumpHost{
+			{
+				Address:           bastion.Address(),
+				LoginUser:         "bastion",
+				PrivateKeyContent: bastionPrivateKey,
+				HostKey:           bastion.HostKey(),
+			},
+
+			{
+				Address:           internalJumpHost.Address(),
+				LoginUser:         "internal",
+				PrivateKeyContent: internalPrivateKey,
+				HostKey:           internalJumpHost.HostKey(),
+			},
+		},
+	})
+
+	config := &entities.Config{}
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	err := AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env creation, got '%+v'", err)
+	}
+
+	bastionForwardedAddresses := bastion.ForwardedAddresses()
+
+	if len(bastionForwardedAddresses) == 0 {
+		t.Fatalf("expected the bastion to be used, got no forwarded addresses")
+	}
+
+	for _, forwardedAddress := range bastionForwardedAddresses {
+		if forwardedAddress != internalJumpHost.Address() {
+			t.Fatalf(
+				"expected the bastion to only reach '%s', got '%s'",
+				internalJumpHost.Address(),
+				forwardedAddress,
+			)
+		}
+	}
+
+	internalForwardedAddresses := internalJumpHost.ForwardedAddresses()
+
+	if len(internalForwardedAddresses) == 0 {
+		t.Fatalf("expected the internal jump host to be used, got no forwarded addresses")
+	}
+
+	// The temporary public IP address is
+	// used until the elastic IP is attached
+	lastForwardedAddress := internalForwardedAddresses[len(internalForwardedAddresses)-1]
+	lastForwardedHost, _, err := net.SplitHostPort(lastForwardedAddress)
+
+	if err != nil || lastForwardedHost != env.InstancePublicIPAddress {
+		t.Fatalf(
+			"expected last forwarded address to target '%s', got '%s'",
+			env.InstancePublicIPAddress,
+			lastForwardedAddress,
+		)
+	}
+}
+
+func TestSSHJumpHostDialerWithMismatchingHostKey(t *testing.T) {
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	bastionPrivateKey, bastionPublicKey := generateJumpHostKeyPair(t)
+
+	bastion := fakeAWS.NewJumpHost("bastion", bastionPublicKey)
+	defer bastion.Close()
+
+	otherJumpHost := fakeAWS.NewJumpHost("bastion", bastionPublicKey)
+	defer otherJumpHost.Close()
+
+	dialer := infrastructure.SSHJumpHostDialer{
+		JumpHosts: []infrastructure.SSHJumpHost{
+			{
+				Address:           bastion.Address(),
+				LoginUser:         "bastion",
+				PrivateKeyContent: bastionPrivateKey,
+				HostKey:           otherJumpHost.HostKey(),
+			},
+		},
+	}
+
+	_, err := dialer.DialContext(
+		context.Background(),
+		"tcp",
+		otherJumpHost.Address(),
+	)
+
+	if err == nil {
+		t.Fatalf("expected host key mismatch error, got nothing")
+	}
+
+	if len(bastion.ForwardedAddresses()) > 0 {
+		t.Fatalf(
+			"expected no forwarded addresses, got '%+v'",
+			bastion.ForwardedAddresses(),
+		)
+	}
+}
+
+func TestCreateEnvThroughSSHJumpHostWithoutHostKey(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	bastionPrivateKey, bastionPublicKey := generateJumpHostKeyPair(t)
+
+	bastion := fakeAWS.NewJumpHost("bastion", bastionPublicKey)
+	defer bastion.Close()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{
+		SSHJumpHosts: []infrastructure.SSHJumpHost{
+			{
+				Address:           bastion.Address(),
+				LoginUser:         "bastion",
+				PrivateKeyContent: bastionPrivateKey,
+			},
+		},
+	})
+
+	config := &entities.Config{}
+	cluster := &entities.Cluster{
+		Name: entities.DefaultClusterName,
+	}
+
+	err := AWSService.CreateCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster creation, got '%+v'", err)
+	}
+
+	env := &entities.Env{
+		Name:         "eleven-api",
+		InstanceType: "t2.medium",
+	}
+
+	err = AWSService.CreateEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if !errors.Is(err, infrastructure.ErrMissingSSHJumpHostKey) {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			infrastructure.ErrMissingSSHJumpHostKey,
+			err,
+		)
+	}
+
+	if fakeAWS.ResourceCounts().SecurityGroups != 0 {
+		t.Fatalf(
+			"expected no env resources to be created, got '%+v'",
+			fakeAWS.ResourceCounts(),
+		)
+	}
+
+	if len(bastion.ForwardedAddresses()) > 0 {
+		t.Fatalf(
+			"expected no forwarded addresses, got '%+v'",
+			bastion.ForwardedAddresses(),
+		)
+	}
+}
+
+func generateJumpHostKeyPair(t *testing.T) (string, ssh.PublicKey) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	privateKeyBytes, err := x509.MarshalECPrivateKey(privateKey)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+
+	if err != nil {
+		t.Fatalf("expected no error, got '%+v'", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "EC PRIVATE KEY",
+		Bytes: privateKeyBytes,
+	})), publicKey
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/queues"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// StartEnv starts the instance of an env stopped via StopEnv (or
+// HibernateEnv) and waits for the instance to be reachable again.
+func (a *AWS) StartEnv(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) error {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.Instance == nil {
+		return ErrEnvInstanceNotCreated{}
+	}
+
+	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
+
+	startInstance := func(infra *EnvInfrastructure) error {
+		return a.startEnvInstance(ctx, infra)
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Waiting for the EC2 instance to start")
+				return nil
+			},
+			startInstance,
+		},
+	)
+
+	// The elastic IP association is kept
+	// while the instance is stopped
+	waitForInstanceToBeReachable := func(infra *EnvInfrastructure) error {
+		return a.waitForEnvInstanceToBeReachable(ctx, infra)
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Waiting for the instance to be reachable")
+				return nil
+			},
+			waitForInstanceToBeReachable,
+		},
+	)
+
+	err = envInfraQueue.Run(envInfra)
+
+	// Env infra could be updated in the queue even
+	// in case of error (partial infrastructure)
+	env.SetInfrastructureJSON(envInfra)
+
+	return wrapCanceledError(ctx, err)
+}
+
+// startEnvInstance starts the instance of
+// the env if it is stopped (or hibernated).
+func (a *AWS) startEnvInstance(ctx context.Context, infra *EnvInfrastructure) error {
+	if !infra.isInstanceStopped() {
+		return nil
+	}
+
+	err := infrastructure.StartInstance(
+		ctx,
+		a.ec2Client,
+		a.opts.WaitOpts,
+		infra.Instance.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	infra.Instance.PowerState = infrastructure.InstancePowerStateRunning
+	return nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/eleven/entities"
+	"github.com/eleven-sh/eleven/queues"
+	"github.com/eleven-sh/eleven/stepper"
+)
+
+// StopEnv stops the instance of the env to stop paying for it
+// while keeping its volumes. The elastic IP stays associated
+// with the instance so the env keeps its public IP address.
+func (a *AWS) StopEnv(
+	ctx context.Context,
+	stepper stepper.Stepper,
+	config *entities.Config,
+	cluster *entities.Cluster,
+	env *entities.Env,
+) error {
+
+	var envInfra *EnvInfrastructure
+	err := json.Unmarshal([]byte(env.InfrastructureJSON), &envInfra)
+
+	if err != nil {
+		return err
+	}
+
+	if envInfra.Instance == nil {
+		return ErrEnvInstanceNotCreated{}
+	}
+
+	envInfraQueue := queues.InfrastructureQueue[*EnvInfrastructure]{}
+
+	// An explicit stop cancels the restart
+	// of a previous failed resize
+	stopInstance := func(infra *EnvInfrastructure) error {
+		err := a.stopEnvInstance(ctx, infra)
+
+		if err != nil {
+			return err
+		}
+
+		infra.StartInstanceOnceResized = false
+		return nil
+	}
+
+	envInfraQueue = append(
+		envInfraQueue,
+		queues.InfrastructureQueueSteps[*EnvInfrastructure]{
+			func(*EnvInfrastructure) error {
+				stepper.StartTemporaryStep("Waiting for the EC2 instance to stop")
+				return nil
+			},
+			stopInstance,
+		},
+	)
+
+	err = envInfraQueue.Run(envInfra)
+
+	// Env infra could be updated in the queue even
+	// in case of error (partial infrastructure)
+	env.SetInfrastructureJSON(envInfra)
+
+	return wrapCanceledError(ctx, err)
+}
+
+// stopEnvInstance stops the instance of the
+// env if it is not already stopped.
+func (a *AWS) stopEnvInstance(ctx context.Context, infra *EnvInfrastructure) error {
+	if infra.isInstanceStopped() {
+		return nil
+	}
+
+	err := infrastructure.StopInstance(
+		ctx,
+		a.ec2Client,
+		a.opts.WaitOpts,
+		infra.Instance.ID,
+		false,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	infra.Instance.PowerState = infrastructure.InstancePowerStateStopped
+	return nil
+}
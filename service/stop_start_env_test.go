@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	agentConfig "github.com/eleven-sh/agent/config"
+	"github.com/eleven-sh/aws-cloud-provider/fakeaws"
+	"github.com/eleven-sh/aws-cloud-provider/infrastructure"
+	"github.com/eleven-sh/aws-cloud-provider/service"
+	"github.com/eleven-sh/eleven/entities"
+)
+
+func TestStopAndStartEnv(t *testing.T) {
+	ctx := context.Background()
+
+	fakeEnv := newFakeAWSEnv(t, service.AWSOpts{})
+
+	fakeAWS, AWSService := fakeEnv.fakeAWS, fakeEnv.AWSService
+	config, cluster, env := fakeEnv.config, fakeEnv.cluster, fakeEnv.env
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+
+	publicIPAddress := env.InstancePublicIPAddress
+	agentAddress := net.JoinHostPort(
+		publicIPAddress,
+		agentConfig.SSHServerListenPort,
+	)
+
+	// Stopping a stopped env does nothing
+	for i := 0; i < 2; i++ {
+		err := AWSService.StopEnv(ctx, noopStepper{}, config, cluster, env)
+
+		if err != nil {
+			t.Fatalf("expected no error during env stop, got '%+v'", err)
+		}
+	}
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateStopped)
+
+	resourceCounts := fakeAWS.ResourceCounts()
+
+	if resourceCounts.RunningInstances != 0 ||
+		resourceCounts.StoppedInstances != 1 ||
+		resourceCounts.ElasticIPs != 1 {
+
+		t.Fatalf(
+			"expected one stopped instance and one elastic IP, got '%+v'",
+			resourceCounts,
+		)
+	}
+
+	conn, err := fakeAWS.Dialer().DialContext(ctx, "tcp", agentAddress)
+
+	if err == nil {
+		conn.Close()
+		t.Fatalf("expected stopped instance to be unreachable, got nothing")
+	}
+
+	err = AWSService.StartEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env start, got '%+v'", err)
+	}
+
+	assertEnvPowerState(t, env, infrastructure.InstancePowerStateRunning)
+
+	if env.InstancePublicIPAddress != publicIPAddress {
+		t.Fatalf(
+			"expected public IP address to equal '%s', got '%s'",
+			publicIPAddress,
+			env.InstancePublicIPAddress,
+		)
+	}
+
+	// The elastic IP association was kept
+	conn, err = fakeAWS.Dialer().DialContext(ctx, "tcp", agentAddress)
+
+	if err != nil {
+		t.Fatalf("expected no error during agent dialing, got '%+v'", err)
+	}
+
+	conn.Close()
+
+	// Stopped envs could be removed
+	err = AWSService.StopEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env stop, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveEnv(ctx, noopStepper{}, config, cluster, env)
+
+	if err != nil {
+		t.Fatalf("expected no error during env removal, got '%+v'", err)
+	}
+
+	err = AWSService.RemoveCluster(ctx, noopStepper{}, config, cluster)
+
+	if err != nil {
+		t.Fatalf("expected no error during cluster removal, got '%+v'", err)
+	}
+
+	resourceCounts = fakeAWS.ResourceCounts()
+
+	if resourceCounts != (fakeaws.ResourceCounts{}) {
+		t.Fatalf("expected no remaining resources, got '%+v'", resourceCounts)
+	}
+}
+
+func TestStopEnvWithoutInstance(t *testing.T) {
+	fakeAWS := fakeaws.NewServer()
+	defer fakeAWS.Close()
+
+	AWSService := service.NewAWS(fakeAWS.Config(), service.AWSOpts{})
+
+	env := &entities.Env{
+		InfrastructureJSON: "{}",
+	}
+
+	err := AWSService.StopEnv(
+		context.Background(),
+		noopStepper{},
+		&entities.Config{},
+		&entities.Cluster{},
+		env,
+	)
+
+	if _, ok := err.(service.ErrEnvInstanceNotCreated); !ok {
+		t.Fatalf(
+			"expected error to equal '%+v', got '%+v'",
+			service.ErrEnvInstanceNotCreated{},
+			err,
+		)
+	}
+}
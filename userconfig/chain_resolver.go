@@ -0,0 +1,98 @@
+package userconfig
+
+import "errors"
+
+// Resolver represents the interface
+// implemented by all the user config resolvers.
+type Resolver interface {
+	Resolve() (*Config, error)
+}
+
+// ChainResolverSource represents a named
+// resolver tried by the ChainResolver.
+type ChainResolverSource struct {
+	// Name represents the name of the source (like "env" or "files").
+	// Set in the Source field of the resolved config.
+	Name string
+
+	// Resolver represents the resolver used to resolve the config.
+	Resolver Resolver
+}
+
+// ErrChainResolverSource represents the error
+// returned by one source of the ChainResolver.
+type ErrChainResolverSource struct {
+	Source string
+	Err    error
+}
+
+func (ErrChainResolverSource) Error() string {
+	return "ErrChainResolverSource"
+}
+
+func (e ErrChainResolverSource) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoSourceResolved represents the error returned when
+// none of the sources of the ChainResolver could resolve config.
+//
+// Errors contains the errors returned by each
+// source, in order (except ErrMissingConfig).
+type ErrNoSourceResolved struct {
+	Errors []ErrChainResolverSource
+}
+
+func (ErrNoSourceResolved) Error() string {
+	return "ErrNoSourceResolved"
+}
+
+// ChainResolver retrieves the AWS account configuration
+// by trying an ordered list of resolvers.
+type ChainResolver struct {
+	sources []ChainResolverSource
+}
+
+// NewChainResolver constructs the ChainResolver struct.
+func NewChainResolver(sources ...ChainResolverSource) ChainResolver {
+	return ChainResolver{
+		sources: sources,
+	}
+}
+
+// Resolve tries each source in order and returns
+// the config resolved by the first one that succeeds.
+// The Source field of the returned config is set to
+// the name of this source.
+//
+// ErrMissingConfig is returned if all sources return ErrMissingConfig.
+// Otherwise, ErrNoSourceResolved is returned with the errors of all sources.
+func (c ChainResolver) Resolve() (*Config, error) {
+	sourceErrors := []ErrChainResolverSource{}
+
+	for _, source := range c.sources {
+		resolvedConfig, err := source.Resolver.Resolve()
+
+		if err == nil {
+			resolvedConfig.Source = source.Name
+			return resolvedConfig, nil
+		}
+
+		if errors.Is(err, ErrMissingConfig) {
+			continue
+		}
+
+		sourceErrors = append(sourceErrors, ErrChainResolverSource{
+			Source: source.Name,
+			Err:    err,
+		})
+	}
+
+	if len(sourceErrors) == 0 {
+		return nil, ErrMissingConfig
+	}
+
+	return nil, ErrNoSourceResolved{
+		Errors: sourceErrors,
+	}
+}
@@ -0,0 +1,118 @@
+package userconfig_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/userconfig"
+)
+
+type resolverFunc func() (*userconfig.Config, error)
+
+func (r resolverFunc) Resolve() (*userconfig.Config, error) {
+	return r()
+}
+
+func resolverReturning(config *userconfig.Config, err error) userconfig.Resolver {
+	return resolverFunc(func() (*userconfig.Config, error) {
+		return config, err
+	})
+}
+
+func TestChainResolving(t *testing.T) {
+	unknownError := errors.New("UnknownError")
+
+	testCases := []struct {
+		test           string
+		sources        []userconfig.ChainResolverSource
+		expectedError  error
+		expectedConfig *userconfig.Config
+	}{
+		{
+			test: "first source resolved",
+			sources: []userconfig.ChainResolverSource{
+				{Name: "env", Resolver: resolverReturning(userconfig.NewConfig("a", "b", "c"), nil)},
+				{Name: "files", Resolver: resolverReturning(userconfig.NewConfig("d", "e", "f"), nil)},
+			},
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "a",
+					SecretAccessKey: "b",
+				},
+				Region: "c",
+				Source: "env",
+			},
+		},
+
+		{
+			test: "sources with errors skipped",
+			sources: []userconfig.ChainResolverSource{
+				{Name: "env", Resolver: resolverReturning(nil, userconfig.ErrMissingConfig)},
+				{Name: "files", Resolver: resolverReturning(nil, unknownError)},
+				{Name: "imds", Resolver: resolverReturning(userconfig.NewConfig("a", "b", "c"), nil)},
+			},
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "a",
+					SecretAccessKey: "b",
+				},
+				Region: "c",
+				Source: "imds",
+			},
+		},
+
+		{
+			test: "all sources missing",
+			sources: []userconfig.ChainResolverSource{
+				{Name: "env", Resolver: resolverReturning(nil, userconfig.ErrMissingConfig)},
+				{Name: "files", Resolver: resolverReturning(nil, userconfig.ErrMissingConfig)},
+			},
+			expectedError: userconfig.ErrMissingConfig,
+		},
+
+		{
+			test:          "without sources",
+			sources:       []userconfig.ChainResolverSource{},
+			expectedError: userconfig.ErrMissingConfig,
+		},
+
+		{
+			test: "all sources with errors",
+			sources: []userconfig.ChainResolverSource{
+				{Name: "env", Resolver: resolverReturning(nil, userconfig.ErrMissingRegionInEnv)},
+				{Name: "files", Resolver: resolverReturning(nil, userconfig.ErrMissingConfig)},
+				{Name: "sso", Resolver: resolverReturning(nil, userconfig.ErrSSOSessionExpired{})},
+			},
+			expectedError: userconfig.ErrNoSourceResolved{
+				Errors: []userconfig.ErrChainResolverSource{
+					{Source: "env", Err: userconfig.ErrMissingRegionInEnv},
+					{Source: "sso", Err: userconfig.ErrSSOSessionExpired{}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			resolver := userconfig.NewChainResolver(tc.sources...)
+			resolvedConfig, err := resolver.Resolve()
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil && !reflect.DeepEqual(err, tc.expectedError) {
+				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
+			}
+
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
+				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
+			}
+
+			if tc.expectedConfig == nil && resolvedConfig != nil {
+				t.Fatalf("expected no config, got '%+v'", *resolvedConfig)
+			}
+		})
+	}
+}
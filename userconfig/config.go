@@ -1,5 +1,7 @@
 package userconfig
 
+import "time"
+
 // Credentials represents the AWS credentials resolved from user config.
 type Credentials struct {
 	// AccessKeyID represents the access key.
@@ -8,9 +10,8 @@ type Credentials struct {
 	// SecretAccessKey represents the secret associated with the access key.
 	SecretAccessKey string
 
-	// SessionToken represents an AWS session token that
-	// could replace the access key + secret set.
-	// Currently not supported.
+	// SessionToken represents the AWS session token that
+	// comes with temporary credentials (access keys starting with "ASIA").
 	SessionToken string
 }
 
@@ -20,15 +21,120 @@ func (u Credentials) HasKeys() bool {
 	return len(u.AccessKeyID) > 0 && len(u.SecretAccessKey) > 0
 }
 
+// SSO represents the AWS SSO (IAM Identity Center)
+// configuration resolved from user config.
+type SSO struct {
+	// StartURL represents the URL of the SSO user portal.
+	StartURL string
+
+	// Region represents the region where the SSO user portal is hosted.
+	Region string
+
+	// AccountID represents the AWS account assigned to the user.
+	AccountID string
+
+	// RoleName represents the role assigned to the user.
+	RoleName string
+
+	// AccessToken represents the token retrieved from the SSO cache
+	// directory that will be exchanged for temporary role credentials.
+	AccessToken string
+}
+
+// IsSet is an helper method used to check
+// that the SSO struct is not empty.
+func (s SSO) IsSet() bool {
+	return len(s.StartURL) > 0 && len(s.AccessToken) > 0
+}
+
+// WebIdentity represents the configuration used to assume
+// a role with a web identity token (like an OIDC token in CI).
+type WebIdentity struct {
+	// RoleARN represents the ARN of the role to assume.
+	RoleARN string
+
+	// TokenFilePath represents the path of the file
+	// that contains the web identity token.
+	TokenFilePath string
+
+	// RoleSessionName represents the name
+	// of the resulting role session.
+	RoleSessionName string
+}
+
+// IsSet is an helper method used to check
+// that the WebIdentity struct is not empty.
+func (w WebIdentity) IsSet() bool {
+	return len(w.RoleARN) > 0 && len(w.TokenFilePath) > 0
+}
+
+// AssumeRole represents an IAM role assumed
+// using the credentials resolved from user config.
+type AssumeRole struct {
+	// RoleARN represents the ARN of the role to assume.
+	RoleARN string
+
+	// ExternalID represents the external ID passed
+	// to STS when the role is assumed.
+	ExternalID string
+
+	// RoleSessionName represents the name
+	// of the resulting role session.
+	RoleSessionName string
+
+	// Duration represents the duration of the
+	// role session. Default to the STS one if not set.
+	Duration time.Duration
+
+	// MFASerial represents the serial number (or ARN) of the MFA device
+	// that needs to be used to assume the role.
+	MFASerial string
+}
+
 // Config represents the resolved user config.
 type Config struct {
 	// Credentials represents the resolved credentials (access key + secret).
 	Credentials Credentials
 
+	// SSO represents the resolved SSO configuration.
+	// Set in place of Credentials when the user signs in through SSO.
+	SSO SSO
+
+	// CredentialProcess represents the command run
+	// to retrieve credentials (credential_process).
+	// Set in place of Credentials when the profile uses an external process.
+	CredentialProcess string
+
+	// WebIdentity represents the resolved web identity configuration.
+	// Set in place of Credentials when a web identity token is used.
+	WebIdentity WebIdentity
+
+	// UseInstanceMetadata is set in place of Credentials when
+	// the credentials of the EC2 instance role are used.
+	UseInstanceMetadata bool
+
+	// RoleChain represents the roles assumed, in order,
+	// using the resolved credentials (or SSO configuration).
+	RoleChain []AssumeRole
+
+	// Source represents the name of the source
+	// the config was resolved from (see ChainResolver).
+	Source string
+
 	// Region represents the resolved region.
 	Region string
 }
 
+// HasStaticCredentials is an helper method used to check
+// that the config uses the access key + secret set in
+// Credentials instead of retrieving credentials from a provider.
+func (c Config) HasStaticCredentials() bool {
+	return !c.SSO.IsSet() &&
+		len(c.CredentialProcess) == 0 &&
+		!c.WebIdentity.IsSet() &&
+		!c.UseInstanceMetadata
+}
+
 // NewConfig constructs a new resolved user config.
 func NewConfig(
 	accessKeyID string,
@@ -44,3 +150,16 @@ func NewConfig(
 		Region: region,
 	}
 }
+
+// NewSSOConfig constructs a new resolved
+// user config that uses SSO credentials.
+func NewSSOConfig(
+	sso SSO,
+	region string,
+) *Config {
+
+	return &Config{
+		SSO:    sso,
+		Region: region,
+	}
+}
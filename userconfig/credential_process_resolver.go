@@ -0,0 +1,61 @@
+package userconfig
+
+// CredentialProcessResolver retrieves the AWS account
+// configuration from config files when the
+// profile uses an external process (credential_process)
+// to retrieve credentials.
+type CredentialProcessResolver struct {
+	filesResolver FilesResolver
+}
+
+// NewCredentialProcessResolver constructs the CredentialProcessResolver struct.
+//
+// The options are the same than the ones used by the FilesResolver.
+func NewCredentialProcessResolver(
+	profileLoader ProfileLoader,
+	opts FilesResolverOpts,
+	envVars EnvVarsGetter,
+) CredentialProcessResolver {
+
+	return CredentialProcessResolver{
+		filesResolver: NewFilesResolver(
+			profileLoader,
+			opts,
+			envVars,
+		),
+	}
+}
+
+// Resolve retrieves the credential_process command
+// (and the roles to assume) from config files.
+//
+// The command is not run during resolution. It is
+// run by the UserConfigLoader when credentials are needed.
+//
+// ErrMissingConfig is returned if the loaded
+// profile doesn't use credential_process.
+func (c CredentialProcessResolver) Resolve() (*Config, error) {
+	loadedProfile, err := c.filesResolver.loadProfile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sourceProfile, roleChain := c.filesResolver.resolveRoleChain(loadedProfile)
+
+	if len(sourceProfile.CredentialProcess) == 0 {
+		return nil, ErrMissingConfig
+	}
+
+	resolvedRegion := c.filesResolver.resolveRegion(loadedProfile.Region)
+
+	if len(resolvedRegion) == 0 {
+		return nil, ErrMissingRegionInFiles
+	}
+
+	return &Config{
+		CredentialProcess: sourceProfile.CredentialProcess,
+		RoleChain:         roleChain,
+		Region:            resolvedRegion,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package userconfig_test
+
+import (
+	"reflect"
+	"testing"
+
+	providerconfig "github.com/eleven-sh/aws-cloud-provider/config"
+	"github.com/eleven-sh/aws-cloud-provider/mocks"
+	"github.com/eleven-sh/aws-cloud-provider/userconfig"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCredentialProcessResolving(t *testing.T) {
+	testCases := []struct {
+		test           string
+		profile        string
+		regionOpts     string
+		expectedError  error
+		expectedConfig *userconfig.Config
+	}{
+		{
+			test:    "valid",
+			profile: "process",
+			expectedConfig: &userconfig.Config{
+				CredentialProcess: "/usr/local/bin/fetch-credentials --account sandbox",
+				Region:            "eu-west-1",
+			},
+		},
+
+		{
+			test:    "valid with role chain",
+			profile: "process_role",
+			expectedConfig: &userconfig.Config{
+				CredentialProcess: "/usr/local/bin/fetch-credentials --account sandbox",
+				RoleChain: []userconfig.AssumeRole{
+					{
+						RoleARN: "arn:aws:iam::123456789012:role/sandbox",
+					},
+				},
+				Region: "eu-west-3",
+			},
+		},
+
+		{
+			test:          "missing region",
+			profile:       "process_without_region",
+			expectedError: userconfig.ErrMissingRegionInFiles,
+		},
+
+		{
+			test:       "missing region with region option",
+			profile:    "process_without_region",
+			regionOpts: "eu-west-1",
+			expectedConfig: &userconfig.Config{
+				CredentialProcess: "/usr/local/bin/fetch-credentials",
+				Region:            "eu-west-1",
+			},
+		},
+
+		{
+			test:          "profile without credential process",
+			profile:       "static",
+			expectedError: userconfig.ErrMissingConfig,
+		},
+
+		{
+			test:          "missing profile",
+			profile:       "unknown",
+			expectedError: userconfig.ErrProfileNotFound{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			envVarsGetterMock := mocks.NewUserConfigEnvVarsGetter(mockCtrl)
+			envVarsGetterMock.
+				EXPECT().
+				Get(userconfig.AWSRegionEnvVar).
+				Return("").
+				AnyTimes()
+
+			resolver := userconfig.NewCredentialProcessResolver(
+				providerconfig.NewProfileLoader(),
+				userconfig.FilesResolverOpts{
+					Profile:             tc.profile,
+					Region:              tc.regionOpts,
+					CredentialsFilePath: "./testdata/credential_process_credentials",
+					ConfigFilePath:      "./testdata/credential_process_config",
+				},
+				envVarsGetterMock,
+			)
+
+			resolvedConfig, err := resolver.Resolve()
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil &&
+				reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) &&
+				err != tc.expectedError {
+
+				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
+			}
+
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
+				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
+			}
+
+			if tc.expectedConfig == nil && resolvedConfig != nil {
+				t.Fatalf("expected no config, got '%+v'", *resolvedConfig)
+			}
+		})
+	}
+}
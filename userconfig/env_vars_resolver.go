@@ -28,6 +28,10 @@ const (
 	// that the resolver will look for when resolving the AWS secret access key.
 	AWSSecretAccessKeyEnvVar = "AWS_SECRET_ACCESS_KEY"
 
+	// AWSSessionTokenEnvVar represents the environment variable name
+	// that the resolver will look for when resolving the AWS session token.
+	AWSSessionTokenEnvVar = "AWS_SESSION_TOKEN"
+
 	// AWSRegionEnvVar represents the environment variable name
 	// that the resolver will look for when resolving the AWS region.
 	AWSRegionEnvVar = "AWS_REGION"
@@ -84,6 +88,8 @@ func (e EnvVarsResolver) Resolve() (*Config, error) {
 		e.resolveRegion(e.envVars.Get(AWSRegionEnvVar)),
 	)
 
+	resolvedConfig.Credentials.SessionToken = e.envVars.Get(AWSSessionTokenEnvVar)
+
 	if resolvedConfig.Credentials.HasKeys() &&
 		len(resolvedConfig.Region) > 0 {
 
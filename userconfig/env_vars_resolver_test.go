@@ -2,6 +2,7 @@ package userconfig_test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/eleven-sh/aws-cloud-provider/mocks"
@@ -14,6 +15,7 @@ func TestEnvVarsResolving(t *testing.T) {
 		test                  string
 		accessKeyIDEnvVar     string
 		secretAccessKeyEnvVar string
+		sessionTokenEnvVar    string
 		regionEnvVar          string
 		regionOpts            string
 		expectedError         error
@@ -28,6 +30,23 @@ func TestEnvVarsResolving(t *testing.T) {
 			expectedError:         nil,
 		},
 
+		{
+			test:                  "valid with session token",
+			accessKeyIDEnvVar:     "a",
+			secretAccessKeyEnvVar: "b",
+			sessionTokenEnvVar:    "c",
+			regionEnvVar:          "d",
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "a",
+					SecretAccessKey: "b",
+					SessionToken:    "c",
+				},
+				Region: "d",
+			},
+			expectedError: nil,
+		},
+
 		{
 			test:                  "valid with region opts",
 			accessKeyIDEnvVar:     "a",
@@ -107,6 +126,7 @@ func TestEnvVarsResolving(t *testing.T) {
 			envVarsGetterMock := mocks.NewUserConfigEnvVarsGetter(mockCtrl)
 			envVarsGetterMock.EXPECT().Get(userconfig.AWSAccessKeyIDEnvVar).Return(tc.accessKeyIDEnvVar).AnyTimes()
 			envVarsGetterMock.EXPECT().Get(userconfig.AWSSecretAccessKeyEnvVar).Return(tc.secretAccessKeyEnvVar).AnyTimes()
+			envVarsGetterMock.EXPECT().Get(userconfig.AWSSessionTokenEnvVar).Return(tc.sessionTokenEnvVar).AnyTimes()
 			envVarsGetterMock.EXPECT().Get(userconfig.AWSRegionEnvVar).Return(tc.regionEnvVar).AnyTimes()
 
 			resolver := userconfig.NewEnvVarsResolver(
@@ -126,7 +146,7 @@ func TestEnvVarsResolving(t *testing.T) {
 				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
 			}
 
-			if tc.expectedConfig != nil && *resolvedConfig != *tc.expectedConfig {
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
 				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
 			}
 
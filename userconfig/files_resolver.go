@@ -1,7 +1,13 @@
 package userconfig
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 )
@@ -25,6 +31,54 @@ func (ErrProfileNotFound) Error() string {
 	return "ErrProfileNotFound"
 }
 
+// ErrSourceProfileNotFound represents the error returned
+// when the source_profile of an assume-role profile was not found.
+type ErrSourceProfileNotFound struct {
+	RoleARN       string
+	SourceProfile string
+}
+
+func (ErrSourceProfileNotFound) Error() string {
+	return "ErrSourceProfileNotFound"
+}
+
+// ErrMissingCredentialsInSourceProfile represents the error returned
+// when the source_profile of an assume-role profile has no credentials.
+type ErrMissingCredentialsInSourceProfile struct {
+	RoleARN       string
+	SourceProfile string
+}
+
+func (ErrMissingCredentialsInSourceProfile) Error() string {
+	return "ErrMissingCredentialsInSourceProfile"
+}
+
+// ErrSourceProfileCycle represents the error returned when
+// the source_profile chain of the loaded profile loops back
+// to SourceProfile without ever reaching credentials.
+type ErrSourceProfileCycle struct {
+	Profile       string
+	SourceProfile string
+}
+
+func (ErrSourceProfileCycle) Error() string {
+	return "ErrSourceProfileCycle"
+}
+
+// ErrSSOSessionExpired represents the error returned when
+// the loaded profile uses SSO and the token found in the
+// SSO cache directory has expired (or is missing).
+// The user needs to run "aws sso login --profile <Profile>".
+type ErrSSOSessionExpired struct {
+	Profile       string
+	StartURL      string
+	CacheFilePath string
+}
+
+func (ErrSSOSessionExpired) Error() string {
+	return "ErrSSOSessionExpired"
+}
+
 const (
 	// AWSConfigFileDefaultProfile represents the configuration profile
 	// that will be loaded by default if the Profile option is not set.
@@ -58,6 +112,18 @@ type FilesResolverOpts struct {
 
 	// ConfigFilePath specifies the file path of the config file.
 	ConfigFilePath string
+
+	// SSOCacheDirPath specifies the path of the directory where
+	// the AWS CLI caches the tokens retrieved during "aws sso login".
+	// Default to "~/.aws/sso/cache" if not set.
+	SSOCacheDirPath string
+}
+
+// ssoCachedToken represents the content of
+// the token files found in the SSO cache directory.
+type ssoCachedToken struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
 }
 
 // FilesResolver retrieves the AWS account
@@ -93,9 +159,67 @@ func NewFilesResolver(
 //
 // The Region option takes precedence over the region found in config files.
 //
+// When the loaded profile uses SSO (sso_start_url or sso_session),
+// the token is read from the SSOCacheDirPath directory.
+// ErrSSOSessionExpired is returned if the token has expired or is missing.
+//
+// When the loaded profile assumes a role (role_arn + source_profile),
+// the source_profile chain is followed until credentials are found
+// and the roles to assume are returned in the RoleChain field.
+//
 // Config files are loaded via the ProfileLoader interface
 // passed as constructor argument.
 func (f FilesResolver) Resolve() (*Config, error) {
+	loadedProfile, err := f.loadProfile()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sourceProfile, roleChain := f.resolveRoleChain(loadedProfile)
+	isSSOProfile := len(sourceProfile.SSOStartURL) > 0 ||
+		sourceProfile.SSOSession != nil
+
+	if !sourceProfile.Credentials.HasKeys() && !isSSOProfile {
+		// the config file is set but
+		// the credentials one is missing
+		return nil, ErrMissingConfig
+	}
+
+	resolvedRegion := f.resolveRegion(loadedProfile.Region)
+
+	if len(resolvedRegion) == 0 {
+		return nil, ErrMissingRegionInFiles
+	}
+
+	var resolvedConfig *Config
+
+	if !sourceProfile.Credentials.HasKeys() && isSSOProfile {
+		resolvedSSO, err := f.resolveSSO(sourceProfile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		resolvedConfig = NewSSOConfig(resolvedSSO, resolvedRegion)
+	} else {
+		resolvedConfig = NewConfig(
+			sourceProfile.Credentials.AccessKeyID,
+			sourceProfile.Credentials.SecretAccessKey,
+			resolvedRegion,
+		)
+
+		resolvedConfig.Credentials.SessionToken = sourceProfile.Credentials.SessionToken
+	}
+
+	resolvedConfig.RoleChain = roleChain
+
+	return resolvedConfig, nil
+}
+
+// loadProfile loads the configuration profile
+// and converts the AWS SDK errors to meaningful ones.
+func (f FilesResolver) loadProfile() (config.SharedConfig, error) {
 	loadedProfile, err := f.profileLoader.Load(
 		f.resolveProfile(),
 		f.opts.CredentialsFilePath,
@@ -103,40 +227,115 @@ func (f FilesResolver) Resolve() (*Config, error) {
 	)
 
 	if err != nil {
+		var assumeRoleErr config.SharedConfigAssumeRoleError
+		if errors.As(err, &assumeRoleErr) {
+			return config.SharedConfig{}, f.resolveAssumeRoleError(assumeRoleErr)
+		}
+
 		if errors.As(err, &config.SharedConfigProfileNotExistError{}) {
 			if len(f.opts.Profile) > 0 {
-				return nil, ErrProfileNotFound{
+				return config.SharedConfig{}, ErrProfileNotFound{
 					Profile:             f.opts.Profile,
 					CredentialsFilePath: f.opts.CredentialsFilePath,
 					ConfigFilePath:      f.opts.ConfigFilePath,
 				}
 			}
 
-			return nil, ErrMissingConfig
+			return config.SharedConfig{}, ErrMissingConfig
 		}
 
-		return nil, err
+		return config.SharedConfig{}, err
 	}
 
-	if !loadedProfile.Credentials.HasKeys() {
-		// the config file is set but
-		// the credentials one is missing
-		return nil, ErrMissingConfig
+	return loadedProfile, nil
+}
+
+// resolveRoleChain follows the source profiles linked by the AWS SDK
+// and returns the profile that holds the credentials with
+// the roles to assume, in order, using these credentials.
+func (f FilesResolver) resolveRoleChain(
+	loadedProfile config.SharedConfig,
+) (config.SharedConfig, []AssumeRole) {
+
+	sourceProfile := loadedProfile
+	var roleChain []AssumeRole
+
+	for len(sourceProfile.RoleARN) > 0 && sourceProfile.Source != nil {
+		assumeRole := AssumeRole{
+			RoleARN:         sourceProfile.RoleARN,
+			ExternalID:      sourceProfile.ExternalID,
+			RoleSessionName: sourceProfile.RoleSessionName,
+			MFASerial:       sourceProfile.MFASerial,
+		}
+
+		if sourceProfile.RoleDurationSeconds != nil {
+			assumeRole.Duration = *sourceProfile.RoleDurationSeconds
+		}
+
+		roleChain = append([]AssumeRole{assumeRole}, roleChain...)
+		sourceProfile = *sourceProfile.Source
 	}
 
-	resolvedRegion := f.resolveRegion(loadedProfile.Region)
+	return sourceProfile, roleChain
+}
 
-	if len(resolvedRegion) == 0 {
-		return nil, ErrMissingRegionInFiles
+// resolveAssumeRoleError converts the error returned by the AWS SDK
+// when a source profile is invalid to a more meaningful one.
+//
+// The AWS SDK doesn't report cycles in source profile chains.
+// It stops following the chain on the first profile seen twice and
+// reports it as a source profile without credentials. So, the chain
+// is followed again from the reported source profile to tell cycles
+// apart from profiles that really have no credentials.
+func (f FilesResolver) resolveAssumeRoleError(
+	assumeRoleErr config.SharedConfigAssumeRoleError,
+) error {
+
+	if assumeRoleErr.Err != nil {
+		if errors.As(assumeRoleErr.Err, &config.SharedConfigProfileNotExistError{}) {
+			return ErrSourceProfileNotFound{
+				RoleARN:       assumeRoleErr.RoleARN,
+				SourceProfile: assumeRoleErr.Profile,
+			}
+		}
+
+		return assumeRoleErr
 	}
 
-	resolvedConfig := NewConfig(
-		loadedProfile.Credentials.AccessKeyID,
-		loadedProfile.Credentials.SecretAccessKey,
-		resolvedRegion,
-	)
+	profile := f.resolveProfile()
+	seenProfiles := map[string]bool{
+		profile: true,
+	}
 
-	return resolvedConfig, nil
+	for {
+		sourceProfile := assumeRoleErr.Profile
+
+		if seenProfiles[sourceProfile] {
+			return ErrSourceProfileCycle{
+				Profile:       profile,
+				SourceProfile: sourceProfile,
+			}
+		}
+
+		seenProfiles[sourceProfile] = true
+
+		_, err := f.profileLoader.Load(
+			sourceProfile,
+			f.opts.CredentialsFilePath,
+			f.opts.ConfigFilePath,
+		)
+
+		if err == nil {
+			return ErrMissingCredentialsInSourceProfile{
+				RoleARN:       assumeRoleErr.RoleARN,
+				SourceProfile: sourceProfile,
+			}
+		}
+
+		if !errors.As(err, &assumeRoleErr) || assumeRoleErr.Err != nil {
+			return err
+		}
+	}
 }
 
 func (f FilesResolver) resolveProfile() string {
@@ -158,3 +357,87 @@ func (f FilesResolver) resolveRegion(regionInFile string) string {
 
 	return regionInFile
 }
+
+func (f FilesResolver) resolveSSO(SSOProfile config.SharedConfig) (SSO, error) {
+	cacheDirPath, err := f.resolveSSOCacheDirPath()
+
+	if err != nil {
+		return SSO{}, err
+	}
+
+	// The AWS CLI names the token files after the SHA-1 hash
+	// of the SSO session name (or of the SSO start URL for
+	// the profiles configured without SSO session)
+	cacheKey := SSOProfile.SSOStartURL
+	startURL := SSOProfile.SSOStartURL
+	region := SSOProfile.SSORegion
+
+	if SSOProfile.SSOSession != nil {
+		cacheKey = SSOProfile.SSOSession.Name
+		startURL = SSOProfile.SSOSession.SSOStartURL
+		region = SSOProfile.SSOSession.SSORegion
+	}
+
+	cacheKeyHash := sha1.Sum([]byte(cacheKey))
+	cacheFilePath := filepath.Join(
+		cacheDirPath,
+		hex.EncodeToString(cacheKeyHash[:])+".json",
+	)
+
+	sessionExpiredErr := ErrSSOSessionExpired{
+		Profile:       f.resolveSSOProfileName(SSOProfile),
+		StartURL:      startURL,
+		CacheFilePath: cacheFilePath,
+	}
+
+	cacheFileContent, err := os.ReadFile(cacheFilePath)
+
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return SSO{}, sessionExpiredErr
+		}
+
+		return SSO{}, err
+	}
+
+	var cachedToken ssoCachedToken
+	if err := json.Unmarshal(cacheFileContent, &cachedToken); err != nil {
+		return SSO{}, err
+	}
+
+	if len(cachedToken.AccessToken) == 0 ||
+		!time.Now().Before(cachedToken.ExpiresAt) {
+
+		return SSO{}, sessionExpiredErr
+	}
+
+	return SSO{
+		StartURL:    startURL,
+		Region:      region,
+		AccountID:   SSOProfile.SSOAccountID,
+		RoleName:    SSOProfile.SSORoleName,
+		AccessToken: cachedToken.AccessToken,
+	}, nil
+}
+
+func (f FilesResolver) resolveSSOCacheDirPath() (string, error) {
+	if len(f.opts.SSOCacheDirPath) > 0 {
+		return f.opts.SSOCacheDirPath, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".aws", "sso", "cache"), nil
+}
+
+func (f FilesResolver) resolveSSOProfileName(SSOProfile config.SharedConfig) string {
+	if len(SSOProfile.Profile) > 0 {
+		return SSOProfile.Profile
+	}
+
+	return f.resolveProfile()
+}
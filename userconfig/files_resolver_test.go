@@ -2,9 +2,12 @@ package userconfig_test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
+	providerconfig "github.com/eleven-sh/aws-cloud-provider/config"
 	"github.com/eleven-sh/aws-cloud-provider/mocks"
 	"github.com/eleven-sh/aws-cloud-provider/userconfig"
 	"github.com/golang/mock/gomock"
@@ -21,6 +24,7 @@ func TestFilesResolving(t *testing.T) {
 		profileOpts             string
 		credentialsFilePathOpts string
 		configFilePathOpts      string
+		ssoCacheDirPathOpts     string
 		errorReturnedByLoader   error
 		expectedError           error
 		expectedConfig          *userconfig.Config
@@ -32,6 +36,27 @@ func TestFilesResolving(t *testing.T) {
 			expectedError:  nil,
 		},
 
+		{
+			test: "valid with session token",
+			configInFiles: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "a",
+					SecretAccessKey: "b",
+					SessionToken:    "c",
+				},
+				Region: "d",
+			},
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "a",
+					SecretAccessKey: "b",
+					SessionToken:    "c",
+				},
+				Region: "d",
+			},
+			expectedError: nil,
+		},
+
 		{
 			test:           "valid with region option",
 			configInFiles:  userconfig.NewConfig("a", "b", "c"),
@@ -79,6 +104,72 @@ func TestFilesResolving(t *testing.T) {
 			expectedConfig: nil,
 		},
 
+		{
+			test: "valid with SSO profile",
+			configInFiles: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL:  "https://valid.awsapps.com/start",
+				Region:    "eu-west-1",
+				AccountID: "123456789012",
+				RoleName:  "Admin",
+			}, "c"),
+			ssoCacheDirPathOpts: "./testdata/sso_cache",
+			expectedConfig: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL:    "https://valid.awsapps.com/start",
+				Region:      "eu-west-1",
+				AccountID:   "123456789012",
+				RoleName:    "Admin",
+				AccessToken: "valid_access_token",
+			}, "c"),
+			expectedError: nil,
+		},
+
+		{
+			test: "valid with SSO profile and static credentials",
+			configInFiles: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "a",
+					SecretAccessKey: "b",
+				},
+				SSO: userconfig.SSO{
+					StartURL: "https://expired.awsapps.com/start",
+				},
+				Region: "c",
+			},
+			ssoCacheDirPathOpts: "./testdata/sso_cache",
+			expectedConfig:      userconfig.NewConfig("a", "b", "c"),
+			expectedError:       nil,
+		},
+
+		{
+			test: "expired SSO session",
+			configInFiles: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL: "https://expired.awsapps.com/start",
+			}, "c"),
+			ssoCacheDirPathOpts: "./testdata/sso_cache",
+			expectedError:       userconfig.ErrSSOSessionExpired{},
+			expectedConfig:      nil,
+		},
+
+		{
+			test: "missing SSO token",
+			configInFiles: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL: "https://unknown.awsapps.com/start",
+			}, "c"),
+			ssoCacheDirPathOpts: "./testdata/sso_cache",
+			expectedError:       userconfig.ErrSSOSessionExpired{},
+			expectedConfig:      nil,
+		},
+
+		{
+			test: "missing region with SSO profile",
+			configInFiles: userconfig.NewSSOConfig(userconfig.SSO{
+				StartURL: "https://valid.awsapps.com/start",
+			}, ""),
+			ssoCacheDirPathOpts: "./testdata/sso_cache",
+			expectedError:       userconfig.ErrMissingRegionInFiles,
+			expectedConfig:      nil,
+		},
+
 		{
 			test:                  "missing config files without profile option",
 			errorReturnedByLoader: config.SharedConfigProfileNotExistError{},
@@ -116,7 +207,12 @@ func TestFilesResolving(t *testing.T) {
 			if tc.configInFiles != nil {
 				configAsReturnedByProfileLoader.Credentials.AccessKeyID = tc.configInFiles.Credentials.AccessKeyID
 				configAsReturnedByProfileLoader.Credentials.SecretAccessKey = tc.configInFiles.Credentials.SecretAccessKey
+				configAsReturnedByProfileLoader.Credentials.SessionToken = tc.configInFiles.Credentials.SessionToken
 				configAsReturnedByProfileLoader.Region = tc.configInFiles.Region
+				configAsReturnedByProfileLoader.SSOStartURL = tc.configInFiles.SSO.StartURL
+				configAsReturnedByProfileLoader.SSORegion = tc.configInFiles.SSO.Region
+				configAsReturnedByProfileLoader.SSOAccountID = tc.configInFiles.SSO.AccountID
+				configAsReturnedByProfileLoader.SSORoleName = tc.configInFiles.SSO.RoleName
 			}
 
 			profileLoaderMock := mocks.NewUserConfigProfileLoader(mockCtrl)
@@ -140,6 +236,7 @@ func TestFilesResolving(t *testing.T) {
 					Profile:             tc.profileOpts,
 					CredentialsFilePath: tc.credentialsFilePathOpts,
 					ConfigFilePath:      tc.configFilePathOpts,
+					SSOCacheDirPath:     tc.ssoCacheDirPathOpts,
 				},
 				envVarsGetterMock,
 			)
@@ -150,11 +247,13 @@ func TestFilesResolving(t *testing.T) {
 				t.Fatalf("expected no error, got '%+v'", err)
 			}
 
-			if tc.expectedError != nil && !errors.Is(err, tc.expectedError) && !errors.As(err, &tc.expectedError) {
+			if tc.expectedError != nil && !errors.Is(err, tc.expectedError) &&
+				reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+
 				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
 			}
 
-			if tc.expectedConfig != nil && *resolvedConfig != *tc.expectedConfig {
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
 				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
 			}
 
@@ -164,3 +263,199 @@ func TestFilesResolving(t *testing.T) {
 		})
 	}
 }
+
+func TestFilesResolvingWithAssumeRoleProfiles(t *testing.T) {
+	testCases := []struct {
+		test           string
+		profile        string
+		expectedError  error
+		expectedConfig *userconfig.Config
+	}{
+		{
+			test:    "with role assumed from static credentials",
+			profile: "sandbox",
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "base_access_key_id",
+					SecretAccessKey: "base_secret_access_key",
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{
+						RoleARN:         "arn:aws:iam::123456789012:role/sandbox",
+						ExternalID:      "sandbox_external_id",
+						RoleSessionName: "eleven",
+						Duration:        30 * time.Minute,
+					},
+				},
+				Region: "eu-west-3",
+			},
+		},
+
+		{
+			test:    "with nested roles",
+			profile: "nested",
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "base_access_key_id",
+					SecretAccessKey: "base_secret_access_key",
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{
+						RoleARN:         "arn:aws:iam::123456789012:role/sandbox",
+						ExternalID:      "sandbox_external_id",
+						RoleSessionName: "eleven",
+						Duration:        30 * time.Minute,
+					},
+					{
+						RoleARN:   "arn:aws:iam::210987654321:role/nested",
+						MFASerial: "arn:aws:iam::123456789012:mfa/user",
+					},
+				},
+				Region: "us-east-1",
+			},
+		},
+
+		{
+			test:    "with self-referencing profile",
+			profile: "self",
+			expectedConfig: &userconfig.Config{
+				Credentials: userconfig.Credentials{
+					AccessKeyID:     "self_access_key_id",
+					SecretAccessKey: "self_secret_access_key",
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{
+						RoleARN: "arn:aws:iam::123456789012:role/self",
+					},
+				},
+				Region: "eu-west-1",
+			},
+		},
+
+		{
+			test:    "with role assumed from SSO profile",
+			profile: "sso_sandbox",
+			expectedConfig: &userconfig.Config{
+				SSO: userconfig.SSO{
+					StartURL:    "https://valid.awsapps.com/start",
+					Region:      "eu-west-1",
+					AccountID:   "123456789012",
+					RoleName:    "Admin",
+					AccessToken: "valid_access_token",
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{
+						RoleARN: "arn:aws:iam::210987654321:role/sandbox",
+					},
+				},
+				Region: "eu-west-3",
+			},
+		},
+
+		{
+			test:    "with SSO session profile",
+			profile: "sso_session_base",
+			expectedConfig: &userconfig.Config{
+				SSO: userconfig.SSO{
+					StartURL:    "https://valid-session.awsapps.com/start",
+					Region:      "eu-west-1",
+					AccountID:   "123456789012",
+					RoleName:    "Admin",
+					AccessToken: "valid_session_access_token",
+				},
+				Region: "eu-west-1",
+			},
+		},
+
+		{
+			test:    "with role assumed from SSO session profile",
+			profile: "sso_session_sandbox",
+			expectedConfig: &userconfig.Config{
+				SSO: userconfig.SSO{
+					StartURL:    "https://valid-session.awsapps.com/start",
+					Region:      "eu-west-1",
+					AccountID:   "123456789012",
+					RoleName:    "Admin",
+					AccessToken: "valid_session_access_token",
+				},
+				RoleChain: []userconfig.AssumeRole{
+					{
+						RoleARN: "arn:aws:iam::210987654321:role/sandbox",
+					},
+				},
+				Region: "eu-west-3",
+			},
+		},
+
+		{
+			test:          "with expired SSO session",
+			profile:       "sso_session_expired",
+			expectedError: userconfig.ErrSSOSessionExpired{},
+		},
+
+		{
+			test:          "with cycle",
+			profile:       "cycle_a",
+			expectedError: userconfig.ErrSourceProfileCycle{},
+		},
+
+		{
+			test:          "with cycle after first source profile",
+			profile:       "cycle_entry",
+			expectedError: userconfig.ErrSourceProfileCycle{},
+		},
+
+		{
+			test:          "with source profile without credentials",
+			profile:       "without_source_credentials",
+			expectedError: userconfig.ErrMissingCredentialsInSourceProfile{},
+		},
+
+		{
+			test:          "with missing source profile",
+			profile:       "missing_source",
+			expectedError: userconfig.ErrSourceProfileNotFound{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			envVarsGetterMock := mocks.NewUserConfigEnvVarsGetter(mockCtrl)
+			envVarsGetterMock.
+				EXPECT().
+				Get(userconfig.AWSRegionEnvVar).
+				Return("").
+				AnyTimes()
+
+			resolver := userconfig.NewFilesResolver(
+				providerconfig.NewProfileLoader(),
+				userconfig.FilesResolverOpts{
+					Profile:             tc.profile,
+					CredentialsFilePath: "./testdata/assume_role_credentials",
+					ConfigFilePath:      "./testdata/assume_role_config",
+					SSOCacheDirPath:     "./testdata/sso_cache",
+				},
+				envVarsGetterMock,
+			)
+
+			resolvedConfig, err := resolver.Resolve()
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil &&
+				reflect.TypeOf(err) != reflect.TypeOf(tc.expectedError) {
+
+				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
+			}
+
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
+				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
+			}
+		})
+	}
+}
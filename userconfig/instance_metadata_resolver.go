@@ -0,0 +1,147 @@
+package userconfig
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+const (
+	// DefaultInstanceMetadataTimeout represents the time the
+	// InstanceMetadataResolver waits for the instance metadata
+	// service to respond if the Timeout option is not set.
+	DefaultInstanceMetadataTimeout = 2 * time.Second
+
+	instanceMetadataRolesPath = "iam/security-credentials/"
+)
+
+//go:generate go run github.com/golang/mock/mockgen -destination ../mocks/user_config_instance_metadata_client.go -package mocks -mock_names InstanceMetadataClient=UserConfigInstanceMetadataClient github.com/eleven-sh/aws-cloud-provider/userconfig InstanceMetadataClient
+// InstanceMetadataClient represents the interface
+// used to access the EC2 instance metadata service.
+type InstanceMetadataClient interface {
+	GetMetadata(
+		ctx context.Context,
+		params *imds.GetMetadataInput,
+		optFns ...func(*imds.Options),
+	) (*imds.GetMetadataOutput, error)
+
+	GetRegion(
+		ctx context.Context,
+		params *imds.GetRegionInput,
+		optFns ...func(*imds.Options),
+	) (*imds.GetRegionOutput, error)
+}
+
+// InstanceMetadataResolverOpts represents the options
+// used to configure the InstanceMetadataResolver.
+type InstanceMetadataResolverOpts struct {
+	// Region specifies which region will be used in the resulting config.
+	// Default to the one found in environment variables, then to
+	// the region of the instance if not set.
+	Region string
+
+	// Timeout specifies how long to wait for
+	// the instance metadata service to respond.
+	// Default to DefaultInstanceMetadataTimeout if not set.
+	Timeout time.Duration
+}
+
+// InstanceMetadataResolver retrieves the AWS account
+// configuration from the EC2 instance metadata service
+// when Eleven is run in an instance with a role attached.
+type InstanceMetadataResolver struct {
+	opts     InstanceMetadataResolverOpts
+	metadata InstanceMetadataClient
+	envVars  EnvVarsGetter
+}
+
+// NewInstanceMetadataResolver constructs the InstanceMetadataResolver struct.
+func NewInstanceMetadataResolver(
+	metadata InstanceMetadataClient,
+	envVars EnvVarsGetter,
+	opts InstanceMetadataResolverOpts,
+) InstanceMetadataResolver {
+
+	return InstanceMetadataResolver{
+		opts:     opts,
+		metadata: metadata,
+		envVars:  envVars,
+	}
+}
+
+// Resolve checks that the instance metadata service
+// is reachable and that a role is attached to the instance.
+//
+// The Region option takes precedence over the AWS_REGION
+// environment variable that takes precedence over
+// the region of the instance.
+//
+// The role credentials are not retrieved during resolution. They
+// are retrieved by the UserConfigLoader when credentials are needed.
+//
+// ErrMissingConfig is returned if the instance metadata service
+// cannot be reached (when not run in an EC2 instance) or if
+// no role is attached to the instance.
+func (i InstanceMetadataResolver) Resolve() (*Config, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), i.resolveTimeout())
+	defer cancel()
+
+	rolesResp, err := i.metadata.GetMetadata(ctx, &imds.GetMetadataInput{
+		Path: instanceMetadataRolesPath,
+	})
+
+	if err != nil {
+		return nil, ErrMissingConfig
+	}
+
+	defer rolesResp.Content.Close()
+	roles, err := io.ReadAll(rolesResp.Content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(string(roles))) == 0 {
+		return nil, ErrMissingConfig
+	}
+
+	resolvedRegion, err := i.resolveRegion(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		UseInstanceMetadata: true,
+		Region:              resolvedRegion,
+	}, nil
+}
+
+func (i InstanceMetadataResolver) resolveTimeout() time.Duration {
+	if i.opts.Timeout > 0 {
+		return i.opts.Timeout
+	}
+
+	return DefaultInstanceMetadataTimeout
+}
+
+func (i InstanceMetadataResolver) resolveRegion(ctx context.Context) (string, error) {
+	if len(i.opts.Region) > 0 {
+		return i.opts.Region, nil
+	}
+
+	if len(i.envVars.Get(AWSRegionEnvVar)) > 0 {
+		return i.envVars.Get(AWSRegionEnvVar), nil
+	}
+
+	regionResp, err := i.metadata.GetRegion(ctx, &imds.GetRegionInput{})
+
+	if err != nil {
+		return "", err
+	}
+
+	return regionResp.Region, nil
+}
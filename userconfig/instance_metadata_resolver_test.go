@@ -0,0 +1,139 @@
+package userconfig_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/eleven-sh/aws-cloud-provider/mocks"
+	"github.com/eleven-sh/aws-cloud-provider/userconfig"
+	"github.com/golang/mock/gomock"
+)
+
+func TestInstanceMetadataResolving(t *testing.T) {
+	unreachableError := errors.New("UnreachableError")
+
+	testCases := []struct {
+		test             string
+		rolesInMetadata  string
+		metadataError    error
+		regionInMetadata string
+		regionEnvVar     string
+		regionOpts       string
+		expectedError    error
+		expectedConfig   *userconfig.Config
+	}{
+		{
+			test:             "valid",
+			rolesInMetadata:  "eleven-bastion-role",
+			regionInMetadata: "eu-west-1",
+			expectedConfig: &userconfig.Config{
+				UseInstanceMetadata: true,
+				Region:              "eu-west-1",
+			},
+		},
+
+		{
+			test:             "valid with region sets as env var",
+			rolesInMetadata:  "eleven-bastion-role",
+			regionInMetadata: "eu-west-1",
+			regionEnvVar:     "eu-west-2",
+			expectedConfig: &userconfig.Config{
+				UseInstanceMetadata: true,
+				Region:              "eu-west-2",
+			},
+		},
+
+		{
+			test:             "valid with region option",
+			rolesInMetadata:  "eleven-bastion-role",
+			regionInMetadata: "eu-west-1",
+			regionEnvVar:     "eu-west-2",
+			regionOpts:       "eu-west-3",
+			expectedConfig: &userconfig.Config{
+				UseInstanceMetadata: true,
+				Region:              "eu-west-3",
+			},
+		},
+
+		{
+			test:             "without role attached",
+			rolesInMetadata:  "",
+			regionInMetadata: "eu-west-1",
+			expectedError:    userconfig.ErrMissingConfig,
+		},
+
+		{
+			test:          "unreachable instance metadata",
+			metadataError: unreachableError,
+			expectedError: userconfig.ErrMissingConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			var metadataOutput *imds.GetMetadataOutput
+			if tc.metadataError == nil {
+				metadataOutput = &imds.GetMetadataOutput{
+					Content: io.NopCloser(strings.NewReader(tc.rolesInMetadata)),
+				}
+			}
+
+			metadataClientMock := mocks.NewUserConfigInstanceMetadataClient(mockCtrl)
+			metadataClientMock.
+				EXPECT().
+				GetMetadata(gomock.Any(), &imds.GetMetadataInput{
+					Path: "iam/security-credentials/",
+				}).
+				Return(metadataOutput, tc.metadataError).
+				Times(1)
+
+			metadataClientMock.
+				EXPECT().
+				GetRegion(gomock.Any(), gomock.Any()).
+				Return(&imds.GetRegionOutput{
+					Region: tc.regionInMetadata,
+				}, nil).
+				AnyTimes()
+
+			envVarsGetterMock := mocks.NewUserConfigEnvVarsGetter(mockCtrl)
+			envVarsGetterMock.
+				EXPECT().
+				Get(userconfig.AWSRegionEnvVar).
+				Return(tc.regionEnvVar).
+				AnyTimes()
+
+			resolver := userconfig.NewInstanceMetadataResolver(
+				metadataClientMock,
+				envVarsGetterMock,
+				userconfig.InstanceMetadataResolverOpts{
+					Region: tc.regionOpts,
+				},
+			)
+
+			resolvedConfig, err := resolver.Resolve()
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil && !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
+			}
+
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
+				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
+			}
+
+			if tc.expectedConfig == nil && resolvedConfig != nil {
+				t.Fatalf("expected no config, got '%+v'", *resolvedConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package userconfig
+
+import (
+	"errors"
+	"os"
+)
+
+var (
+	// ErrMissingRoleARNInEnv represents the error
+	// returned when a web identity token file is set
+	// but the role to assume cannot be found.
+	ErrMissingRoleARNInEnv = errors.New("ErrMissingRoleARNInEnv")
+)
+
+const (
+	// AWSWebIdentityTokenFileEnvVar represents the environment variable name
+	// that the resolver will look for when resolving the web identity token file.
+	AWSWebIdentityTokenFileEnvVar = "AWS_WEB_IDENTITY_TOKEN_FILE"
+
+	// AWSRoleARNEnvVar represents the environment variable name
+	// that the resolver will look for when resolving the role to assume.
+	AWSRoleARNEnvVar = "AWS_ROLE_ARN"
+
+	// AWSRoleSessionNameEnvVar represents the environment variable name
+	// that the resolver will look for when resolving the role session name.
+	AWSRoleSessionNameEnvVar = "AWS_ROLE_SESSION_NAME"
+)
+
+// WebIdentityResolverOpts represents the options
+// used to configure the WebIdentityResolver.
+type WebIdentityResolverOpts struct {
+	// Region specifies which region will be used in the resulting config.
+	// Default to the one found in environment variables if not set.
+	Region string
+}
+
+// WebIdentityResolver retrieves the AWS account configuration
+// from the environment variables set by CI providers
+// that use OIDC (like GitHub Actions or EKS).
+type WebIdentityResolver struct {
+	opts    WebIdentityResolverOpts
+	envVars EnvVarsGetter
+}
+
+// NewWebIdentityResolver constructs the WebIdentityResolver struct.
+func NewWebIdentityResolver(
+	envVars EnvVarsGetter,
+	opts WebIdentityResolverOpts,
+) WebIdentityResolver {
+
+	return WebIdentityResolver{
+		opts:    opts,
+		envVars: envVars,
+	}
+}
+
+// Resolve retrieves the AWS account configuration
+// from the AWS_WEB_IDENTITY_TOKEN_FILE, AWS_ROLE_ARN and
+// AWS_ROLE_SESSION_NAME environment variables.
+//
+// The Region option takes precedence over the one
+// found in environment variables.
+//
+// The role is not assumed during resolution. It is
+// assumed by the UserConfigLoader when credentials are needed.
+//
+// ErrMissingConfig is returned if the
+// web identity token file is not set.
+func (w WebIdentityResolver) Resolve() (*Config, error) {
+	tokenFilePath := w.envVars.Get(AWSWebIdentityTokenFileEnvVar)
+
+	if len(tokenFilePath) == 0 {
+		return nil, ErrMissingConfig
+	}
+
+	roleARN := w.envVars.Get(AWSRoleARNEnvVar)
+
+	if len(roleARN) == 0 {
+		return nil, ErrMissingRoleARNInEnv
+	}
+
+	if _, err := os.Stat(tokenFilePath); err != nil {
+		return nil, err
+	}
+
+	resolvedRegion := w.resolveRegion(w.envVars.Get(AWSRegionEnvVar))
+
+	if len(resolvedRegion) == 0 {
+		return nil, ErrMissingRegionInEnv
+	}
+
+	return &Config{
+		WebIdentity: WebIdentity{
+			RoleARN:         roleARN,
+			TokenFilePath:   tokenFilePath,
+			RoleSessionName: w.envVars.Get(AWSRoleSessionNameEnvVar),
+		},
+		Region: resolvedRegion,
+	}, nil
+}
+
+func (w WebIdentityResolver) resolveRegion(regionInEnvVars string) string {
+	if len(w.opts.Region) > 0 {
+		return w.opts.Region
+	}
+
+	return regionInEnvVars
+}
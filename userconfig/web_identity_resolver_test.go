@@ -0,0 +1,122 @@
+package userconfig_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/eleven-sh/aws-cloud-provider/mocks"
+	"github.com/eleven-sh/aws-cloud-provider/userconfig"
+	"github.com/golang/mock/gomock"
+)
+
+func TestWebIdentityResolving(t *testing.T) {
+	testCases := []struct {
+		test                  string
+		tokenFileEnvVar       string
+		roleARNEnvVar         string
+		roleSessionNameEnvVar string
+		regionEnvVar          string
+		regionOpts            string
+		expectedError         error
+		expectedConfig        *userconfig.Config
+	}{
+		{
+			test:                  "valid",
+			tokenFileEnvVar:       "./testdata/web_identity_token",
+			roleARNEnvVar:         "arn:aws:iam::123456789012:role/ci",
+			roleSessionNameEnvVar: "github-actions",
+			regionEnvVar:          "eu-west-1",
+			expectedConfig: &userconfig.Config{
+				WebIdentity: userconfig.WebIdentity{
+					RoleARN:         "arn:aws:iam::123456789012:role/ci",
+					TokenFilePath:   "./testdata/web_identity_token",
+					RoleSessionName: "github-actions",
+				},
+				Region: "eu-west-1",
+			},
+		},
+
+		{
+			test:            "valid with region opts",
+			tokenFileEnvVar: "./testdata/web_identity_token",
+			roleARNEnvVar:   "arn:aws:iam::123456789012:role/ci",
+			regionEnvVar:    "eu-west-1",
+			regionOpts:      "eu-west-3",
+			expectedConfig: &userconfig.Config{
+				WebIdentity: userconfig.WebIdentity{
+					RoleARN:       "arn:aws:iam::123456789012:role/ci",
+					TokenFilePath: "./testdata/web_identity_token",
+				},
+				Region: "eu-west-3",
+			},
+		},
+
+		{
+			test:            "missing region",
+			tokenFileEnvVar: "./testdata/web_identity_token",
+			roleARNEnvVar:   "arn:aws:iam::123456789012:role/ci",
+			expectedError:   userconfig.ErrMissingRegionInEnv,
+		},
+
+		{
+			test:            "missing role ARN",
+			tokenFileEnvVar: "./testdata/web_identity_token",
+			regionEnvVar:    "eu-west-1",
+			expectedError:   userconfig.ErrMissingRoleARNInEnv,
+		},
+
+		{
+			test:            "missing token file",
+			tokenFileEnvVar: "./testdata/unknown_token",
+			roleARNEnvVar:   "arn:aws:iam::123456789012:role/ci",
+			regionEnvVar:    "eu-west-1",
+			expectedError:   os.ErrNotExist,
+		},
+
+		{
+			test:          "missing config",
+			regionEnvVar:  "eu-west-1",
+			expectedError: userconfig.ErrMissingConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.test, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			envVarsGetterMock := mocks.NewUserConfigEnvVarsGetter(mockCtrl)
+			envVarsGetterMock.EXPECT().Get(userconfig.AWSWebIdentityTokenFileEnvVar).Return(tc.tokenFileEnvVar).AnyTimes()
+			envVarsGetterMock.EXPECT().Get(userconfig.AWSRoleARNEnvVar).Return(tc.roleARNEnvVar).AnyTimes()
+			envVarsGetterMock.EXPECT().Get(userconfig.AWSRoleSessionNameEnvVar).Return(tc.roleSessionNameEnvVar).AnyTimes()
+			envVarsGetterMock.EXPECT().Get(userconfig.AWSRegionEnvVar).Return(tc.regionEnvVar).AnyTimes()
+
+			resolver := userconfig.NewWebIdentityResolver(
+				envVarsGetterMock,
+				userconfig.WebIdentityResolverOpts{
+					Region: tc.regionOpts,
+				},
+			)
+
+			resolvedConfig, err := resolver.Resolve()
+
+			if tc.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got '%+v'", err)
+			}
+
+			if tc.expectedError != nil && !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected error to equal '%+v', got '%+v'", tc.expectedError, err)
+			}
+
+			if tc.expectedConfig != nil && !reflect.DeepEqual(resolvedConfig, tc.expectedConfig) {
+				t.Fatalf("expected config to equal '%+v', got '%+v'", *tc.expectedConfig, *resolvedConfig)
+			}
+
+			if tc.expectedConfig == nil && resolvedConfig != nil {
+				t.Fatalf("expected no config, got '%+v'", *resolvedConfig)
+			}
+		})
+	}
+}